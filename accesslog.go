@@ -0,0 +1,54 @@
+package health
+
+import (
+	"log"
+	"net/http"
+	"time"
+)
+
+// Logger is used by the Checker to write logs. Matches *log.Logger.
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+type stdLogger struct{}
+
+func (stdLogger) Printf(format string, v ...interface{}) {
+	log.Printf(format, v...)
+}
+
+func (h *Checker) logger() Logger {
+	if h.Logger == nil {
+		return stdLogger{}
+	}
+
+	return h.Logger
+}
+
+// Logs method, path, status, duration and remote address of each request.
+func accessLogHandler(logger Logger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, code: http.StatusOK}
+
+		next.ServeHTTP(rec, r)
+
+		logger.Printf("%v %v %d %v %v", r.Method, r.URL.RequestURI(), rec.code, time.Since(start), r.RemoteAddr)
+	})
+}
+
+// Records the status code written to the response.
+type statusRecorder struct {
+	http.ResponseWriter
+	code        int
+	wroteHeader bool
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	if !r.wroteHeader {
+		r.code = code
+		r.wroteHeader = true
+	}
+
+	r.ResponseWriter.WriteHeader(code)
+}
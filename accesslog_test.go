@@ -0,0 +1,28 @@
+package health
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChecker_accessLog(t *testing.T) {
+	buf := &bytes.Buffer{}
+	checker := &Checker{AccessLog: true, Logger: log.New(buf, "", 0)}
+	checker.AddReadinessProbe("redis", func() error {
+		return fmt.Errorf("connection refused")
+	})
+
+	server := httptest.NewServer(checker.serverMux())
+	defer server.Close()
+
+	_, err := http.Get(fmt.Sprintf("%v/.well-known/ready?brief", server.URL))
+
+	assert.NoError(t, err)
+	assert.Regexp(t, `^GET /.well-known/ready\?brief 503 \S+ 127.0.0.1:\d+\n$`, buf.String())
+}
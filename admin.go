@@ -0,0 +1,200 @@
+package health
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ProbeInfo describes a registered probe, its settings and its latest result.
+type ProbeInfo struct {
+	Name string `json:"name"`
+	// Either "liveness" or "readiness"
+	Kind      string     `json:"kind"`
+	Critical  bool       `json:"critical"`
+	Timeout   string     `json:"timeout,omitempty"`
+	Disabled  bool       `json:"disabled"`
+	Status    Status     `json:"status,omitempty"`
+	Error     string     `json:"error,omitempty"`
+	CheckedAt *time.Time `json:"checkedAt,omitempty"`
+	// Labels attached to the probe, see Labels
+	Labels map[string]string `json:"labels,omitempty"`
+	// Fault injected into the probe, see InjectFault
+	Fault *Fault `json:"fault,omitempty"`
+}
+
+// Settings of a probe changed by the AdminHandler. Unset fields are not changed.
+type probeUpdate struct {
+	Disabled *bool   `json:"disabled"`
+	Critical *bool   `json:"critical"`
+	Timeout  *string `json:"timeout"`
+}
+
+// Lists the registered liveness and readiness probes with their current settings and latest results, ordered by
+// kind and name. Probes are not run.
+func (h *Checker) Probes() []ProbeInfo {
+	var infos []ProbeInfo
+	for _, kind := range []struct {
+		name   string
+		probes map[string]*registeredProbe
+	}{{"liveness", h.livenessProbes}, {"readiness", h.readinessProbes}} {
+		names := make([]string, 0, len(kind.probes))
+		for name := range kind.probes {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			probe := kind.probes[name]
+			settings := probe.settings()
+			info := ProbeInfo{Name: name, Kind: kind.name, Critical: settings.critical, Disabled: settings.disabled,
+				Labels: copyLabels(probe.labels), Fault: probe.injectedFault()}
+			if settings.timeout > 0 {
+				info.Timeout = settings.timeout.String()
+			}
+
+			if recent := probe.recent(); len(recent) > 0 {
+				r := recent[len(recent)-1]
+				info.Status = r.Status
+				info.CheckedAt = &r.CheckedAt
+				if r.Err != nil {
+					info.Error = r.Err.Error()
+				}
+			}
+
+			infos = append(infos, info)
+		}
+	}
+
+	return infos
+}
+
+// Disables the liveness and readiness probes registered as name. Disabled probes are not run and reported as
+// passing, so incident responders can neutralize a misbehaving probe without a redeployment.
+func (h *Checker) DisableProbe(name string) error {
+	return h.updateProbe(name, func(p *registeredProbe) { p.disabled = true })
+}
+
+// Enables the probes registered as name again, see DisableProbe.
+func (h *Checker) EnableProbe(name string) error {
+	return h.updateProbe(name, func(p *registeredProbe) { p.disabled = false })
+}
+
+// Changes the timeout of the probes registered as name. A timeout of 0 disables the timeout.
+func (h *Checker) SetProbeTimeout(name string, d time.Duration) error {
+	return h.updateProbe(name, func(p *registeredProbe) { p.timeout = d })
+}
+
+// Changes whether a failure of the probes registered as name affects the state of the service.
+func (h *Checker) SetProbeCritical(name string, critical bool) error {
+	return h.updateProbe(name, func(p *registeredProbe) { p.critical = critical })
+}
+
+func (h *Checker) updateProbe(name string, update func(p *registeredProbe)) error {
+	var found bool
+	for _, probes := range []map[string]*registeredProbe{h.livenessProbes, h.readinessProbes} {
+		if probe, ok := probes[name]; ok {
+			probe.settingsMu.Lock()
+			update(probe)
+			probe.settingsMu.Unlock()
+			found = true
+		}
+	}
+
+	if !found {
+		return fmt.Errorf("no probe registered as %q", name)
+	}
+
+	return nil
+}
+
+// Returns a handler to manage the probes at runtime, authenticated by the bearer token. Mount it on an internal
+// port only. `GET /probes` lists the probes, see Probes. `PATCH /probes/<name>` changes the settings of a probe given
+// as JSON, e.g. `{"disabled": true}`, `{"critical": false}` or `{"timeout": "2s"}`. If Chaos is enabled,
+// `PUT /probes/<name>/fault` injects a Fault given as JSON, e.g. `{"error": "connection refused", "latency": "2s",
+// "flap": true}`, and `DELETE /probes/<name>/fault` removes it. Each change is logged.
+//
+// Example:
+//		mux.Handle("/admin/", http.StripPrefix("/admin", checker.AdminHandler(os.Getenv("HEALTH_ADMIN_TOKEN"))))
+func (h *Checker) AdminHandler(token string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		given := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if token == "" || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		switch {
+		case r.URL.Path == "/probes" && r.Method == http.MethodGet:
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(h.Probes())
+		case strings.HasPrefix(r.URL.Path, "/probes/") && strings.HasSuffix(r.URL.Path, "/fault"):
+			h.serveFault(w, r, strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/probes/"), "/fault"))
+		case strings.HasPrefix(r.URL.Path, "/probes/") && r.Method == http.MethodPatch:
+			h.patchProbe(w, r, strings.TrimPrefix(r.URL.Path, "/probes/"))
+		case r.URL.Path == "/probes" || strings.HasPrefix(r.URL.Path, "/probes/"):
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+func (h *Checker) patchProbe(w http.ResponseWriter, r *http.Request, name string) {
+	var update probeUpdate
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		http.Error(w, fmt.Sprintf("invalid settings: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	var timeout time.Duration
+	if update.Timeout != nil {
+		var err error
+		if timeout, err = time.ParseDuration(*update.Timeout); err != nil {
+			http.Error(w, fmt.Sprintf("invalid timeout: %v", err), http.StatusBadRequest)
+			return
+		}
+	}
+
+	err := h.updateProbe(name, func(p *registeredProbe) {
+		if update.Disabled != nil {
+			p.disabled = *update.Disabled
+		}
+		if update.Critical != nil {
+			p.critical = *update.Critical
+		}
+		if update.Timeout != nil {
+			p.timeout = timeout
+		}
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	h.logger().Printf("probe %v changed by admin api from %v: disabled=%v critical=%v timeout=%v",
+		name, r.RemoteAddr, formatOptional(update.Disabled), formatOptional(update.Critical), formatOptional(update.Timeout))
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Formats an optional setting, `-` if it is not set.
+func formatOptional(v interface{}) string {
+	switch v := v.(type) {
+	case *bool:
+		if v != nil {
+			return fmt.Sprint(*v)
+		}
+	case *string:
+		if v != nil {
+			return *v
+		}
+	}
+
+	return "-"
+}
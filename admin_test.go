@@ -0,0 +1,91 @@
+package health
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChecker_DisableProbe(t *testing.T) {
+	checker := &Checker{}
+	checker.AddReadinessProbe("database", func() error { return fmt.Errorf("connection refused") })
+
+	assert.False(t, checker.readiness(context.Background(), nil).Ready)
+
+	assert.NoError(t, checker.DisableProbe("database"))
+	r := checker.readiness(context.Background(), nil)
+	assert.True(t, r.Ready)
+	assert.True(t, r.Probes[0].Disabled)
+
+	assert.NoError(t, checker.EnableProbe("database"))
+	assert.False(t, checker.readiness(context.Background(), nil).Ready)
+
+	assert.NoError(t, checker.SetProbeCritical("database", false))
+	assert.Equal(t, StatusWarn, checker.readiness(context.Background(), nil).Status)
+
+	assert.EqualError(t, checker.DisableProbe("cache"), `no probe registered as "cache"`)
+}
+
+func TestChecker_SetProbeTimeout(t *testing.T) {
+	checker := &Checker{}
+	checker.AddReadinessProbe("slow", func() error {
+		time.Sleep(100 * time.Millisecond)
+		return nil
+	})
+
+	assert.NoError(t, checker.SetProbeTimeout("slow", 10*time.Millisecond))
+	assert.Equal(t, KindTimeout, checker.readiness(context.Background(), nil).Reasons[0].Kind)
+
+	assert.Equal(t, []ProbeInfo{{Name: "slow", Kind: "readiness", Critical: true, Timeout: "10ms", Status: StatusFail,
+		Error: "timed out after 10ms", CheckedAt: checker.Probes()[0].CheckedAt}}, checker.Probes())
+}
+
+func TestChecker_AdminHandler(t *testing.T) {
+	logs := &bytes.Buffer{}
+	checker := &Checker{Logger: log.New(logs, "", 0)}
+	checker.AddLivenessProbe("goroutines", func() error { return nil })
+	checker.AddReadinessProbe("database", func() error { return fmt.Errorf("connection refused") })
+
+	server := httptest.NewServer(checker.AdminHandler("secret"))
+	defer server.Close()
+
+	request := func(method, path, token, body string) *http.Response {
+		req, err := http.NewRequest(method, server.URL+path, strings.NewReader(body))
+		assert.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		resp, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		return resp
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, request(http.MethodGet, "/probes", "wrong", "").StatusCode)
+
+	resp := request(http.MethodGet, "/probes", "secret", "")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	var probes []ProbeInfo
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&probes))
+	assert.Equal(t, []ProbeInfo{
+		{Name: "goroutines", Kind: "liveness", Critical: true},
+		{Name: "database", Kind: "readiness", Critical: true},
+	}, probes)
+
+	resp = request(http.MethodPatch, "/probes/database", "secret", `{"disabled":true,"timeout":"2s"}`)
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	assert.True(t, checker.readiness(context.Background(), nil).Ready)
+	assert.Equal(t, "2s", checker.Probes()[1].Timeout)
+	assert.Contains(t, logs.String(), "probe database changed by admin api")
+
+	assert.Equal(t, http.StatusBadRequest, request(http.MethodPatch, "/probes/database", "secret", `{"timeout":"soon"}`).StatusCode)
+	assert.Equal(t, http.StatusNotFound, request(http.MethodPatch, "/probes/cache", "secret", `{"disabled":true}`).StatusCode)
+	assert.Equal(t, http.StatusMethodNotAllowed, request(http.MethodDelete, "/probes/database", "secret", "").StatusCode)
+}
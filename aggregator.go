@@ -0,0 +1,217 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+const defaultAggregatorTimeout = 5 * time.Second
+
+// Aggregator polls the readiness of downstream services and serves a combined fleet-level health endpoint with
+// the state of each service. The zero value is ready to use.
+//
+// Example:
+//		fleet := &health.Aggregator{}
+//		fleet.AddService("orders", "http://orders:8080/.well-known/ready")
+//		fleet.AddGrpcService("billing", billingConn, "")
+//		http.Handle("/fleet", fleet)
+type Aggregator struct {
+	// Timeout of each poll. Defaults to 5s.
+	Timeout time.Duration
+	// Client used to poll http services. Defaults to `http.DefaultClient`.
+	Client *http.Client
+
+	mu       sync.Mutex
+	services map[string]func(ctx context.Context) ServiceStatus
+}
+
+// AggregateResponse is the response of an Aggregator.
+type AggregateResponse struct {
+	// Whether all services are ready
+	Ready bool `json:"ready"`
+	// Worst status of all services
+	Status Status `json:"status"`
+	// State of each service ordered by name
+	Services []ServiceStatus `json:"services"`
+}
+
+// ServiceStatus is the state of a downstream service polled by an Aggregator.
+type ServiceStatus struct {
+	Name     string   `json:"name"`
+	Ready    bool     `json:"ready"`
+	Status   Status   `json:"status"`
+	Duration string   `json:"duration"`
+	Error    string   `json:"error,omitempty"`
+	Reasons  []Reason `json:"reasons,omitempty"`
+}
+
+// Adds a service serving a readiness endpoint like the one of a Checker at readyURL. Responses of a Checker are
+// decoded, so the fleet endpoint includes the status and reasons of the service. Other services are ready if they
+// respond with 2xx.
+func (a *Aggregator) AddService(name, readyURL string) {
+	a.add(name, func(ctx context.Context) ServiceStatus {
+		return a.pollHTTP(ctx, readyURL)
+	})
+}
+
+// Adds a service implementing the gRPC health checking protocol. The service is ready if it reports SERVING for
+// the given service name. Leave service empty to check the server as a whole.
+func (a *Aggregator) AddGrpcService(name string, cc grpc.ClientConnInterface, service string) {
+	client := grpc_health_v1.NewHealthClient(cc)
+
+	a.add(name, func(ctx context.Context) ServiceStatus {
+		resp, err := client.Check(ctx, &grpc_health_v1.HealthCheckRequest{Service: service})
+		if err != nil {
+			return ServiceStatus{Status: StatusFail, Error: err.Error()}
+		}
+
+		if resp.Status != grpc_health_v1.HealthCheckResponse_SERVING {
+			return ServiceStatus{Status: StatusFail, Error: fmt.Sprintf("service is %v", resp.Status)}
+		}
+
+		return ServiceStatus{Ready: true, Status: StatusPass}
+	})
+}
+
+func (a *Aggregator) add(name string, poll func(ctx context.Context) ServiceStatus) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if _, alreadyRegistered := a.services[name]; alreadyRegistered {
+		panic("an aggregated service should have a unique identifier")
+	}
+
+	if a.services == nil {
+		a.services = map[string]func(ctx context.Context) ServiceStatus{}
+	}
+
+	a.services[name] = poll
+}
+
+// Polls all services concurrently and returns their combined state.
+func (a *Aggregator) Check(ctx context.Context) AggregateResponse {
+	a.mu.Lock()
+	services := make(map[string]func(ctx context.Context) ServiceStatus, len(a.services))
+	for name, poll := range a.services {
+		services[name] = poll
+	}
+	a.mu.Unlock()
+
+	timeout := a.Timeout
+	if timeout <= 0 {
+		timeout = defaultAggregatorTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	results := make([]ServiceStatus, 0, len(services))
+	mu := sync.Mutex{}
+	wg := sync.WaitGroup{}
+	for name, poll := range services {
+		name, poll := name, poll
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			start := time.Now()
+			status := poll(ctx)
+			status.Name = name
+			status.Duration = time.Since(start).String()
+
+			mu.Lock()
+			results = append(results, status)
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Name < results[j].Name
+	})
+
+	status := StatusPass
+	for _, s := range results {
+		status = status.worse(s.Status)
+	}
+
+	return AggregateResponse{
+		Ready:    status != StatusFail,
+		Status:   status,
+		Services: results,
+	}
+}
+
+// Serves the combined state of all services. Responds with 503 if any service is not ready.
+func (a *Aggregator) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	resp := a.Check(r.Context())
+
+	b, err := json.Marshal(resp)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+
+	if !resp.Ready {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+
+	if r.Method == http.MethodHead {
+		return
+	}
+
+	_, _ = w.Write(b)
+}
+
+func (a *Aggregator) pollHTTP(ctx context.Context, url string) ServiceStatus {
+	client := a.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return ServiceStatus{Status: StatusFail, Error: err.Error()}
+	}
+	req.Header.Set("Accept", MediaTypeHealthV2+", */*")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return ServiceStatus{Status: StatusFail, Error: err.Error()}
+	}
+	defer resp.Body.Close()
+
+	body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, maxHTTPAssertBodySize))
+
+	ready := resp.StatusCode >= 200 && resp.StatusCode < 300
+	status := ServiceStatus{Ready: ready, Status: StatusPass}
+	if !ready {
+		status.Status = StatusFail
+		status.Error = fmt.Sprintf("unexpected status code %d", resp.StatusCode)
+	}
+
+	// Services using a Checker report their status and reasons
+	var checker ReadyResponse
+	if json.Unmarshal(body, &checker) == nil {
+		if ready && checker.Status != "" {
+			status.Status = checker.Status
+		}
+		status.Reasons = checker.Reasons
+	}
+
+	return status
+}
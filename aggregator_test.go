@@ -0,0 +1,78 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	grpchealth "google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestAggregator(t *testing.T) {
+	orders := &Checker{}
+	orders.AddReadinessProbe("db", func() error {
+		return fmt.Errorf("connection refused")
+	})
+	ordersServer := httptest.NewServer(orders.serverMux())
+	defer ordersServer.Close()
+
+	legacyServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("OK"))
+	}))
+	defer legacyServer.Close()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	grpcServer := grpc.NewServer()
+	healthServer := grpchealth.NewServer()
+	healthServer.SetServingStatus("billing", grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+	grpc_health_v1.RegisterHealthServer(grpcServer, healthServer)
+	go func() { _ = grpcServer.Serve(lis) }()
+	defer grpcServer.Stop()
+
+	cc, err := grpc.Dial(lis.Addr().String(), grpc.WithInsecure())
+	assert.NoError(t, err)
+	defer cc.Close()
+
+	fleet := &Aggregator{}
+	fleet.AddService("orders", ordersServer.URL+"/.well-known/ready")
+	fleet.AddService("legacy", legacyServer.URL)
+	fleet.AddGrpcService("billing", cc, "billing")
+
+	server := httptest.NewServer(fleet)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	var body AggregateResponse
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.False(t, body.Ready)
+	assert.Equal(t, StatusFail, body.Status)
+	if assert.Len(t, body.Services, 3) {
+		assert.Equal(t, "billing", body.Services[0].Name)
+		assert.False(t, body.Services[0].Ready)
+		assert.Equal(t, "service is NOT_SERVING", body.Services[0].Error)
+
+		assert.Equal(t, "legacy", body.Services[1].Name)
+		assert.True(t, body.Services[1].Ready)
+		assert.Equal(t, StatusPass, body.Services[1].Status)
+
+		assert.Equal(t, "orders", body.Services[2].Name)
+		assert.False(t, body.Services[2].Ready)
+		assert.Equal(t, []Reason{{Service: "db", Error: "connection refused", Kind: KindError}}, body.Services[2].Reasons)
+	}
+
+	healthServer.SetServingStatus("billing", grpc_health_v1.HealthCheckResponse_SERVING)
+	assert.True(t, fleet.Check(context.Background()).Services[0].Ready)
+}
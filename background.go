@@ -0,0 +1,257 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Result is the outcome of an evaluation of the readiness probes.
+type Result struct {
+	// Whether no critical probe failed. Equal to Status != StatusFail, unless the service is starting.
+	Ready bool
+	// Worst status of all probes
+	Status Status
+	// Failing probes and probes with warnings
+	Reasons []Reason
+	// Results of the single probes ordered by name
+	Probes []ProbeResult
+	// Time the evaluation was started at
+	CheckedAt time.Time
+	// Time the evaluation took
+	Duration time.Duration
+
+	// Logger of the checker passed to the listeners, so they log through it as well
+	logger Logger
+}
+
+// ProbeResult is the outcome of a single probe.
+type ProbeResult struct {
+	// Name the probe was registered with
+	Name string
+	// Whether a failure affects the state of the service
+	Critical bool
+	// Error returned by the probe, nil if it passed
+	Err error
+	// Status of the probe. Failing non-critical probes and errors marked by Warning are reported as StatusWarn.
+	Status Status
+	// Time the probe was started at
+	CheckedAt time.Time
+	// Time the probe took
+	Duration time.Duration
+	// Whether the probe changes its state too often, see FlapDetection
+	Flapping bool
+	// Whether the probe was disabled at runtime and reported as passing without running it, see AdminHandler
+	Disabled bool
+	// Whether the probe is within a maintenance window and thus non-critical or skipped, see Maintenance
+	Maintenance bool
+	// Labels attached to the probe, see Labels
+	Labels map[string]string
+	// Number of failures of the probe since the start of the service
+	Failures uint64
+	// Error and start time of the latest failure of the probe, retained after it passes again. Nil if it never
+	// failed.
+	LastErr      error
+	LastFailedAt time.Time
+}
+
+// Returns the logger of the checker that notified the listener with the result, see Checker.Logger.
+func (r Result) log() Logger {
+	if r.logger == nil {
+		return stdLogger{}
+	}
+
+	return r.logger
+}
+
+// Returns true if both results have the same state, i.e. the same probes failing with the same errors.
+func (r Result) sameState(o Result) bool {
+	if r.Status != o.Status || len(r.Probes) != len(o.Probes) {
+		return false
+	}
+
+	for i := range r.Probes {
+		if r.Probes[i].Name != o.Probes[i].Name || fmt.Sprint(r.Probes[i].Err) != fmt.Sprint(o.Probes[i].Err) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// A Listener is notified with the Result of each background evaluation of the readiness probes.
+// Listeners may be called concurrently and should not block, as they delay the evaluation. The listeners of this
+// package log through the Logger of the checker notifying them.
+type Listener func(r Result)
+
+// Adds a listener notified after each background evaluation of the readiness probes, see EvaluateInBackground.
+// Evaluations triggered by requests or Check do not notify the listeners, so polling the endpoints can not page
+// anyone.
+//
+// Example:
+//		checker.AddListener(func(r health.Result) {
+//			if !r.Ready {
+//				log.Printf("service is not ready: %v", r.Reasons)
+//			}
+//		})
+func (h *Checker) AddListener(l Listener) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.listeners = append(h.listeners, l)
+}
+
+// Evaluates the readiness probes every interval in background. While running, the readiness endpoint serves the
+// result of the latest evaluation instead of running the probes on each request. The interval is varied randomly by
+// 10%, so instances of a service started together don't hit their dependencies at the same time.
+// Panics if the background evaluation is already running. Returns a function stopping the evaluation.
+//
+// Example:
+//		checker := &health.Checker{}
+//		checker.AddReadinessProbe("redis", health.RedisPoolProbe(pool))
+//		defer checker.EvaluateInBackground(10 * time.Second)()
+func (h *Checker) EvaluateInBackground(interval time.Duration) func() {
+	h.mu.Lock()
+	if h.background {
+		h.mu.Unlock()
+		panic("background evaluation is already running")
+	}
+	h.background = true
+	h.mu.Unlock()
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		for {
+			// Started before the evaluation, so the interval is measured from start to start as with a ticker
+			next := h.clock().After(jitter(interval, defaultJitter))
+			h.evaluateReadiness(context.Background(), true)
+
+			select {
+			case <-next:
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		close(stop)
+		<-done
+
+		h.mu.Lock()
+		h.background = false
+		h.mu.Unlock()
+	}
+}
+
+// Returns the latest result while evaluating in background, otherwise evaluates the readiness probes.
+// If keep is not nil, only the selected probes are evaluated and the listeners are not notified.
+func (h *Checker) readiness(ctx context.Context, keep probeFilter) Result {
+	h.mu.Lock()
+	last := h.lastResult
+	background := h.background
+	h.mu.Unlock()
+
+	if background && last != nil {
+		return h.withGrace(last.filter(keep))
+	}
+
+	if h.ResultStore != nil && !background {
+		stored, err := h.ResultStore.Load(ctx)
+		if err != nil {
+			h.logger().Printf("failed to load result: %v", err)
+		} else if stored != nil {
+			return h.withGrace(stored.filter(keep))
+		}
+	}
+
+	if keep != nil {
+		return h.evaluate(ctx, selectProbes(h.readinessProbes, keep))
+	}
+
+	return h.evaluateReadiness(ctx, false)
+}
+
+// Returns a channel receiving the result of each evaluation. Results are dropped if the receiver is too slow,
+// only the latest result is kept. Call the returned function to unsubscribe.
+func (h *Checker) subscribe() (<-chan Result, func()) {
+	ch := make(chan Result, 1)
+
+	h.mu.Lock()
+	if h.subscribers == nil {
+		h.subscribers = map[chan Result]struct{}{}
+	}
+	h.subscribers[ch] = struct{}{}
+	h.mu.Unlock()
+
+	return ch, func() {
+		h.mu.Lock()
+		delete(h.subscribers, ch)
+		h.mu.Unlock()
+	}
+}
+
+// Runs the given probes and returns the result.
+func (h *Checker) evaluate(ctx context.Context, probes map[string]*registeredProbe) Result {
+	clock := h.clock()
+	start := clock.Now()
+	results := h.evaluateProbes(ctx, probes)
+	status, reasons := summarize(results)
+
+	return h.withGrace(Result{
+		Ready:     status != StatusFail,
+		Status:    status,
+		Reasons:   reasons,
+		Probes:    results,
+		CheckedAt: start,
+		Duration:  clock.Now().Sub(start),
+	})
+}
+
+// Runs all readiness probes and stores the result. Only notifies the listeners if notify is set, i.e. for
+// background evaluations.
+func (h *Checker) evaluateReadiness(ctx context.Context, notify bool) Result {
+	r := h.evaluate(ctx, h.readinessProbes)
+
+	// A result cut short by the budget of a request reports unfinished probes as failed, which is not the state of
+	// the service
+	if ctx.Err() != nil {
+		return r
+	}
+
+	if h.ResultStore != nil {
+		if err := h.ResultStore.Save(ctx, r); err != nil {
+			h.logger().Printf("failed to store result: %v", err)
+		}
+	}
+
+	h.mu.Lock()
+	h.lastResult = &r
+	h.stats.add(r.CheckedAt, r.Ready)
+	listeners := h.listeners
+	for ch := range h.subscribers {
+		// Replace a result the subscriber has not received yet
+		select {
+		case <-ch:
+		default:
+		}
+
+		ch <- r
+	}
+	h.mu.Unlock()
+
+	if !notify {
+		return r
+	}
+
+	notified := r
+	notified.logger = h.logger()
+	for _, l := range listeners {
+		l(notified)
+	}
+
+	return r
+}
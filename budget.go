@@ -0,0 +1,50 @@
+package health
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Header by which callers announce how long they wait for a response, as duration string or in seconds
+const probeTimeoutHeader = "X-Probe-Timeout"
+
+// Share of the budget reserved for writing the response
+const budgetReserve = 0.1
+
+// Returns the context of the request limited by the time budget of the probes: the smaller of ProbeBudget and the
+// timeout announced by the X-Probe-Timeout header if TrustProbeTimeoutHeader is set, minus a reserve for writing the
+// response. Probes not finished within the budget are reported as failed, so the caller gets a response before it
+// gives up. Results cut short by the budget are neither stored nor recorded, see evaluateReadiness.
+func (h *Checker) withBudget(r *http.Request) (context.Context, context.CancelFunc) {
+	budget := h.ProbeBudget
+	if h.TrustProbeTimeoutHeader {
+		if announced, ok := parseProbeTimeout(r.Header.Get(probeTimeoutHeader)); ok && (budget <= 0 || announced < budget) {
+			budget = announced
+		}
+	}
+
+	if budget <= 0 {
+		return context.WithCancel(r.Context())
+	}
+
+	return context.WithTimeout(r.Context(), budget-time.Duration(float64(budget)*budgetReserve))
+}
+
+// Parses a timeout given as duration string, e.g. `2s`, or in seconds, e.g. `2` or `0.5`.
+func parseProbeTimeout(v string) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+
+	if d, err := time.ParseDuration(v); err == nil && d > 0 {
+		return d, true
+	}
+
+	if seconds, err := strconv.ParseFloat(v, 64); err == nil && seconds > 0 {
+		return time.Duration(seconds * float64(time.Second)), true
+	}
+
+	return 0, false
+}
@@ -0,0 +1,102 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChecker_ProbeBudget(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	checker := &Checker{ProbeBudget: 100 * time.Millisecond}
+	checker.AddReadinessProbe("database", func() error { return nil })
+	checker.AddReadinessProbe("slow", func() error {
+		<-release
+		return nil
+	})
+
+	server := httptest.NewServer(checker.serverMux())
+	defer server.Close()
+
+	start := time.Now()
+	resp, err := http.DefaultClient.Do(detailedRequest(server.URL + "/.well-known/ready"))
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Less(t, int64(time.Since(start)), int64(time.Second))
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+
+	var body ReadyResponse
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, "slow", body.Reasons[0].Service)
+	assert.Equal(t, KindTimeout, body.Reasons[0].Kind)
+}
+
+func TestChecker_ProbeBudget_header(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	checker := &Checker{TrustProbeTimeoutHeader: true}
+	checker.AddLivenessProbe("slow", func() error {
+		<-release
+		return nil
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/.well-known/alive", nil)
+	req.Header.Set("X-Probe-Timeout", "0.1")
+	rec := httptest.NewRecorder()
+	checker.serverMux().ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+}
+
+func TestChecker_ProbeBudget_untrustedHeader(t *testing.T) {
+	checker := &Checker{}
+	checker.AddReadinessProbe("database", func() error {
+		time.Sleep(20 * time.Millisecond)
+		return nil
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/.well-known/ready", nil)
+	req.Header.Set("X-Probe-Timeout", "0.001")
+	rec := httptest.NewRecorder()
+	checker.serverMux().ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestChecker_ProbeBudget_notStored(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	store := &MemoryResultStore{}
+	checker := &Checker{ProbeBudget: 50 * time.Millisecond, ResultStore: store}
+	checker.AddReadinessProbe("slow", func() error {
+		<-release
+		return nil
+	})
+
+	rec := httptest.NewRecorder()
+	checker.serverMux().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/.well-known/ready", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	stored, _ := store.Load(context.Background())
+	assert.Nil(t, stored)
+	assert.Equal(t, Availability{}, checker.Stats().Service.Hour)
+}
+
+func TestParseProbeTimeout(t *testing.T) {
+	tests := map[string]time.Duration{"2s": 2 * time.Second, "1": time.Second, "0.5": 500 * time.Millisecond, "": 0, "-1": 0, "soon": 0}
+	for v, expected := range tests {
+		d, ok := parseProbeTimeout(v)
+		assert.Equal(t, expected, d, v)
+		assert.Equal(t, expected > 0, ok, v)
+	}
+}
@@ -0,0 +1,162 @@
+package health
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const defaultFaultError = "injected fault"
+
+// ErrChaosDisabled is returned when injecting a fault while Checker.Chaos is disabled.
+var ErrChaosDisabled = errors.New("chaos mode is disabled")
+
+// Fault injected into a probe by InjectFault, to rehearse how orchestrators and alerting respond to a degraded
+// service.
+type Fault struct {
+	// Fails the probe with this error instead of running it
+	Error string
+	// Delays each run of the probe, e.g. to exceed its timeout
+	Latency time.Duration
+	// Alternates between failing and running the probe, so it flaps. Fails with "injected fault" if Error is empty.
+	Flap bool
+}
+
+// JSON form of a Fault with the latency as duration string, e.g. `{"error": "connection refused", "latency": "2s"}`
+type faultJSON struct {
+	Error   string `json:"error,omitempty"`
+	Latency string `json:"latency,omitempty"`
+	Flap    bool   `json:"flap,omitempty"`
+}
+
+func (f Fault) MarshalJSON() ([]byte, error) {
+	v := faultJSON{Error: f.Error, Flap: f.Flap}
+	if f.Latency > 0 {
+		v.Latency = f.Latency.String()
+	}
+
+	return json.Marshal(v)
+}
+
+func (f *Fault) UnmarshalJSON(b []byte) error {
+	var v faultJSON
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+
+	f.Error, f.Flap, f.Latency = v.Error, v.Flap, 0
+	if v.Latency != "" {
+		latency, err := time.ParseDuration(v.Latency)
+		if err != nil {
+			return fmt.Errorf("invalid latency: %w", err)
+		}
+		f.Latency = latency
+	}
+
+	return nil
+}
+
+// Injects a fault into the liveness and readiness probes registered as name until ClearFault is called, e.g. in
+// staging to rehearse how orchestrators and alerting respond to readiness degradation. The fault applies inside the
+// timeout and middlewares of the probe. Fails with ErrChaosDisabled unless Chaos is enabled.
+//
+// Example:
+//		checker.Chaos = os.Getenv("STAGE") == "staging"
+//		err := checker.InjectFault("database", health.Fault{Latency: 3 * time.Second, Flap: true})
+func (h *Checker) InjectFault(name string, f Fault) error {
+	if !h.Chaos {
+		return ErrChaosDisabled
+	}
+
+	if f.Flap && f.Error == "" {
+		f.Error = defaultFaultError
+	}
+
+	return h.updateProbe(name, func(p *registeredProbe) {
+		p.fault = &f
+		p.faultRuns = 0
+	})
+}
+
+// Removes the fault injected into the probes registered as name, see InjectFault.
+func (h *Checker) ClearFault(name string) error {
+	return h.updateProbe(name, func(p *registeredProbe) { p.fault = nil })
+}
+
+// Returns a copy of the injected fault or nil if there is none.
+func (p *registeredProbe) injectedFault() *Fault {
+	p.settingsMu.Lock()
+	defer p.settingsMu.Unlock()
+
+	if p.fault == nil {
+		return nil
+	}
+
+	f := *p.fault
+	return &f
+}
+
+// Returns the probe failing or delayed by the injected fault, or the probe itself if there is none.
+func (p *registeredProbe) withFault(clock Clock) Probe {
+	p.settingsMu.Lock()
+	defer p.settingsMu.Unlock()
+
+	f := p.fault
+	if f == nil {
+		return p.probe
+	}
+
+	run := p.faultRuns
+	p.faultRuns++
+
+	return func() error {
+		if f.Latency > 0 {
+			<-clock.After(f.Latency)
+		}
+
+		if f.Error == "" || f.Flap && run%2 == 1 {
+			return p.probe()
+		}
+
+		return errors.New(f.Error)
+	}
+}
+
+// Handles `PUT` and `DELETE` of `/probes/<name>/fault` of the AdminHandler.
+func (h *Checker) serveFault(w http.ResponseWriter, r *http.Request, name string) {
+	if !h.Chaos {
+		http.Error(w, ErrChaosDisabled.Error(), http.StatusForbidden)
+		return
+	}
+
+	var err error
+	switch r.Method {
+	case http.MethodPut:
+		var f Fault
+		if err := json.NewDecoder(r.Body).Decode(&f); err != nil {
+			http.Error(w, fmt.Sprintf("invalid fault: %v", err), http.StatusBadRequest)
+			return
+		}
+
+		if err = h.InjectFault(name, f); err == nil {
+			h.logger().Printf("fault injected into probe %v by admin api from %v: error=%q latency=%v flap=%v",
+				name, r.RemoteAddr, f.Error, f.Latency, f.Flap)
+		}
+	case http.MethodDelete:
+		if err = h.ClearFault(name); err == nil {
+			h.logger().Printf("fault of probe %v cleared by admin api from %v", name, r.RemoteAddr)
+		}
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,100 @@
+package health
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChecker_InjectFault(t *testing.T) {
+	checker := &Checker{}
+	checker.AddReadinessProbe("database", func() error { return nil }, Timeout(50*time.Millisecond))
+
+	assert.True(t, errors.Is(checker.InjectFault("database", Fault{Error: "connection refused"}), ErrChaosDisabled))
+
+	checker.Chaos = true
+	assert.NoError(t, checker.InjectFault("database", Fault{Error: "connection refused"}))
+	r := checker.readiness(context.Background(), nil)
+	assert.False(t, r.Ready)
+	assert.EqualError(t, r.Probes[0].Err, "connection refused")
+
+	assert.NoError(t, checker.InjectFault("database", Fault{Latency: 100 * time.Millisecond}))
+	r = checker.readiness(context.Background(), nil)
+	assert.Equal(t, KindTimeout, r.Reasons[0].Kind)
+
+	// Later runs would share the timed out run until it finished
+	assert.Eventually(t, func() bool {
+		runner := &checker.readinessProbes["database"].runner
+		runner.mu.Lock()
+		defer runner.mu.Unlock()
+		return runner.pending == nil
+	}, time.Second, time.Millisecond)
+
+	assert.NoError(t, checker.InjectFault("database", Fault{Flap: true}))
+	var ready []bool
+	for i := 0; i < 4; i++ {
+		ready = append(ready, checker.readiness(context.Background(), nil).Ready)
+	}
+	assert.Equal(t, []bool{false, true, false, true}, ready)
+	assert.Equal(t, &Fault{Error: "injected fault", Flap: true}, checker.Probes()[0].Fault)
+
+	assert.NoError(t, checker.ClearFault("database"))
+	assert.True(t, checker.readiness(context.Background(), nil).Ready)
+	assert.Nil(t, checker.Probes()[0].Fault)
+
+	assert.EqualError(t, checker.InjectFault("cache", Fault{}), `no probe registered as "cache"`)
+}
+
+func TestFault_JSON(t *testing.T) {
+	b, err := json.Marshal(Fault{Error: "connection refused", Latency: 2 * time.Second, Flap: true})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"error": "connection refused", "latency": "2s", "flap": true}`, string(b))
+
+	var f Fault
+	assert.NoError(t, json.Unmarshal(b, &f))
+	assert.Equal(t, Fault{Error: "connection refused", Latency: 2 * time.Second, Flap: true}, f)
+
+	assert.EqualError(t, json.Unmarshal([]byte(`{"latency": "soon"}`), &f), `invalid latency: time: invalid duration "soon"`)
+}
+
+func TestChecker_AdminHandler_fault(t *testing.T) {
+	logs := &bytes.Buffer{}
+	checker := &Checker{Logger: log.New(logs, "", 0)}
+	checker.AddReadinessProbe("database", func() error { return nil })
+
+	server := httptest.NewServer(checker.AdminHandler("secret"))
+	defer server.Close()
+
+	request := func(method, path, body string) int {
+		req, err := http.NewRequest(method, server.URL+path, strings.NewReader(body))
+		assert.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer secret")
+
+		resp, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		return resp.StatusCode
+	}
+
+	assert.Equal(t, http.StatusForbidden, request(http.MethodPut, "/probes/database/fault", `{"error":"connection refused"}`))
+
+	checker.Chaos = true
+	assert.Equal(t, http.StatusNoContent, request(http.MethodPut, "/probes/database/fault", `{"error":"connection refused"}`))
+	assert.False(t, checker.readiness(context.Background(), nil).Ready)
+	assert.Contains(t, logs.String(), `fault injected into probe database by admin api`)
+
+	assert.Equal(t, http.StatusNoContent, request(http.MethodDelete, "/probes/database/fault", ""))
+	assert.True(t, checker.readiness(context.Background(), nil).Ready)
+
+	assert.Equal(t, http.StatusBadRequest, request(http.MethodPut, "/probes/database/fault", `{"latency":"soon"}`))
+	assert.Equal(t, http.StatusNotFound, request(http.MethodPut, "/probes/cache/fault", `{}`))
+	assert.Equal(t, http.StatusMethodNotAllowed, request(http.MethodPatch, "/probes/database/fault", `{}`))
+}
@@ -1,60 +1,505 @@
 package health
 
 import (
+	"bytes"
 	"context"
-	"encoding/json"
+	"crypto/sha256"
+	"errors"
 	"fmt"
-	"log"
+	"net"
 	"net/http"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
 )
 
 // A Probe is a health check for a service you depend on.
 // Should return an error if the tested service is unhealthy.
 type Probe func() error
 
-type readyResponse struct {
-	Ready   bool     `json:"ready"`
-	Reasons []string `json:"reasons,omitempty"`
+// AliveResponse is the response of the liveness endpoint.
+type AliveResponse struct {
+	Alive   bool     `json:"alive"`
+	Status  Status   `json:"status,omitempty"`
+	Reasons []Reason `json:"reasons,omitempty"`
+}
+
+// ReadyResponse is the response of the readiness endpoint.
+type ReadyResponse struct {
+	Ready     bool              `json:"ready"`
+	Status    Status            `json:"status,omitempty"`
+	Reasons   []Reason          `json:"reasons,omitempty"`
+	CheckedAt *time.Time        `json:"checkedAt,omitempty"`
+	Duration  string            `json:"duration,omitempty"`
+	Probes    []ProbeStatus     `json:"probes,omitempty"`
+	Peers     *PeerStatus       `json:"peers,omitempty"`
+	Metadata  map[string]string `json:"metadata,omitempty"`
+}
+
+type statusResponse struct {
+	Ready    bool              `json:"ready"`
+	Status   Status            `json:"status"`
+	Reasons  []Reason          `json:"reasons,omitempty"`
+	Probes   []ProbeStatus     `json:"probes"`
+	Metadata map[string]string `json:"metadata,omitempty"`
 }
 
+// ProbeStatus is the state of a single probe, served by the per-probe endpoints and listed in ReadyResponse.
+type ProbeStatus struct {
+	Name     string     `json:"name"`
+	Critical bool       `json:"critical"`
+	Healthy  bool       `json:"healthy"`
+	Status   Status     `json:"status"`
+	Duration string     `json:"duration"`
+	Error    string     `json:"error,omitempty"`
+	Kind     ReasonKind `json:"kind,omitempty"`
+	Flapping bool       `json:"flapping,omitempty"`
+	Disabled bool       `json:"disabled,omitempty"`
+	// Whether the probe is within a maintenance window, see Maintenance
+	Maintenance bool `json:"maintenance,omitempty"`
+	// Labels attached to the probe, see Labels
+	Labels map[string]string `json:"labels,omitempty"`
+	// Number of failures since the start of the service and the latest failure, also while the probe passes
+	Failures      uint64     `json:"failures,omitempty"`
+	LastError     string     `json:"lastError,omitempty"`
+	LastFailureAt *time.Time `json:"lastFailureAt,omitempty"`
+}
+
+// Returns the statuses of probes that did not pass.
+func withoutPassing(statuses []ProbeStatus) []ProbeStatus {
+	var filtered []ProbeStatus
+	for _, status := range statuses {
+		if status.Status != StatusPass {
+			filtered = append(filtered, status)
+		}
+	}
+
+	return filtered
+}
+
+func newProbeStatuses(results []ProbeResult) []ProbeStatus {
+	statuses := make([]ProbeStatus, 0, len(results))
+	for _, result := range results {
+		status := ProbeStatus{
+			Name:     result.Name,
+			Critical: result.Critical,
+			Healthy:  result.Err == nil,
+			Status:   result.Status,
+			Duration: result.Duration.String(),
+			Flapping: result.Flapping,
+			Disabled: result.Disabled,
+			Labels:   result.Labels,
+			Failures: result.Failures,
+
+			Maintenance: result.Maintenance,
+		}
+		if result.LastErr != nil {
+			lastFailureAt := result.LastFailedAt
+			status.LastError = result.LastErr.Error()
+			status.LastFailureAt = &lastFailureAt
+		}
+		if result.Err != nil {
+			status.Error = result.Err.Error()
+			status.Kind = reasonKindOf(result.Err)
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	return statuses
+}
+
+const (
+	defaultAlivePath   = "/.well-known/alive"
+	defaultReadyPath   = "/.well-known/ready"
+	defaultHistoryPath = "/.well-known/history"
+
+	defaultHistorySize = 10
+)
+
 // A Checker can be used to provide a liveliness and readiness endpoint for your application.
-// Use `checker.AddReadinessProbe` to add a test for readiness.
+// Use `checker.AddReadinessProbe` to add a test for readiness and `checker.AddLivenessProbe` to add a test for liveness.
 type Checker struct {
-	readinessProbes map[string]Probe
-	server          *http.Server
+	// Path of the liveness endpoint. Defaults to `/.well-known/alive`.
+	AlivePath string
+	// Path of the readiness endpoint. Defaults to `/.well-known/ready`.
+	ReadyPath string
+	// Path of the history endpoint. Defaults to `/.well-known/history`.
+	HistoryPath string
+	// Path of the endpoint listing the probes as dependencies without running them, see Dependencies.
+	// Defaults to `/.well-known/dependencies`.
+	DependenciesPath string
+	// Path of the endpoint reporting the availability of the service and its probes, see Stats.
+	// Defaults to `/.well-known/stats`.
+	StatsPath string
+	// Number of results kept per probe for the history. Defaults to 10, a negative value disables the history.
+	HistorySize int
+	// Static information about the instance included in each readiness response, e.g. pod name and zone.
+	// See InstanceMetadata.
+	Metadata map[string]string
+	// Compresses responses with gzip if the client accepts it. Disabled by default.
+	Compress bool
+	// Allows cross-origin requests to the health endpoints. Disabled by default.
+	CORS *CORSConfig
+	// Logs each request to the health endpoints. Disabled by default.
+	AccessLog bool
+	// Logger used for access logs and errors, also by the listeners of this package. Defaults to the standard logger.
+	Logger Logger
+	// Time after the first evaluation in which failing probes report StatusStarting, so slowly connecting
+	// dependencies don't trip restart loops during boot. The service is reported alive, but not ready while
+	// starting. Disabled by default.
+	GracePeriod time.Duration
+	// Reports the service as ready while starting, see GracePeriod.
+	ReadyWhileStarting bool
+	// Stops waiting for the remaining probes as soon as a critical probe fails. The remaining probes are reported
+	// as canceled, but they are not interrupted: they keep running in background until they return, so this only
+	// shortens the response time and does not reduce the load on the dependencies. Disabled by default.
+	FailFast bool
+	// Sibling instances queried to report how many of them are ready. Disabled by default.
+	Peers *PeerConfig
+	// Serves HTTP/2 over cleartext (h2c) besides HTTP/1.1, e.g. for service meshes upgrading all traffic to
+	// HTTP/2. Applies to ServeHTTP only. Disabled by default.
+	H2C bool
+	// Serves the handlers of net/http/pprof at `/debug/pprof/`, e.g. to grab goroutine and heap profiles of a wedged
+	// instance through the health port. Only enable it if the port is not publicly reachable. Disabled by default.
+	Pprof bool
+	// Serves an OpenAPI 3 document describing the health endpoints at `/.well-known/openapi.json`, see OpenAPISpec,
+	// and a JSON Schema of the responses at `/.well-known/schema.json`, see ResponseSchema. Disabled by default.
+	OpenAPI bool
+	// Tells the time for evaluations, timeouts and schedules. Defaults to the real time, replace it in tests.
+	Clock Clock
+	// Renders the responses of the health endpoints. Defaults to JSONEncoder.
+	Encoder ResponseEncoder
+	// Wrap every probe when it runs, e.g. for logging, metrics or retries. The first middleware is the outermost.
+	// The timeout of a probe includes its middlewares.
+	ProbeMiddlewares []ProbeMiddleware
+	// Secrets removed from the errors of probes before they are served, logged, exported as metrics or passed to
+	// listeners, e.g. API keys of a vendor. Credentials in URLs and passwords in connection strings are always removed.
+	RedactPatterns []*regexp.Regexp
+	// Time within the liveness and readiness endpoints respond, e.g. the timeoutSeconds of the Kubernetes probe.
+	// Probes not finished in time are reported as failed. By default the endpoints wait for all probes.
+	ProbeBudget time.Duration
+	// Lets callers announce a shorter budget by the `X-Probe-Timeout` header. Only enable it if the endpoints are
+	// reachable by trusted callers only, e.g. the kubelet, as anyone sending a tiny timeout fails the probes.
+	// Disabled by default.
+	TrustProbeTimeoutHeader bool
+	// Time the result of a probe is reused for other requests of the probe after it finished, so the dependency is
+	// not touched again by each endpoint. Requests while the probe runs always share its run. Disabled by default.
+	ProbeDedupWindow time.Duration
+	// Shares the latest result of the readiness probes between processes, e.g. RedisResultStore. Processes not
+	// evaluating in background serve the stored result instead of running the probes, as long as there is one.
+	// By default the result is kept in memory of the process.
+	ResultStore ResultStore
+	// Allows injecting faults into probes by InjectFault and the AdminHandler, e.g. in staging to rehearse how
+	// orchestrators and alerting respond to a degraded service. Never enable it in production. Disabled by default.
+	Chaos bool
+
+	livenessProbes  map[string]*registeredProbe
+	readinessProbes map[string]*registeredProbe
+	servers         []*http.Server
+	serverAddrs     []net.Addr
+
+	mu          sync.Mutex
+	listeners   []Listener
+	subscribers map[chan Result]struct{}
+	lastResult  *Result
+	background  bool
+	startedAt   time.Time
+	// Availability of the service, see Stats
+	stats availabilityCounter
+	// Latest status of the peers, see cachedPeerStatus
+	peersMu       sync.Mutex
+	peers         *PeerStatus
+	peersAt       time.Time
+	peersQuerying bool
+	// Encoded responses by path, see writeCachedResponse
+	responsesMu sync.Mutex
+	responses   map[string]*encodedResponse
+}
+
+// A probe registered at a Checker.
+type registeredProbe struct {
+	probe    Probe
+	timeout  time.Duration
+	critical bool
+	labels   map[string]string
+
+	// Ring buffer of the latest results
+	historyMu   sync.Mutex
+	history     []ProbeResult
+	historyNext int
+
+	// Number of failures and the latest failure, exposed by the MetricsHandler
+	failuresMu  sync.Mutex
+	failures    uint64
+	lastFailure ProbeResult
+
+	// Availability within rolling windows, see Checker.Stats
+	statsMu sync.Mutex
+	stats   availabilityCounter
+
+	// Schedule of probes running at their own interval while evaluating in background
+	interval     time.Duration
+	initialDelay time.Duration
+	jitter       *float64
+	scheduleMu   sync.Mutex
+	scheduled    ProbeResult
+	nextRun      time.Time
+
+	// State changes of probes with flap detection
+	flapTransitions int
+	flapWindow      time.Duration
+	flapMu          sync.Mutex
+	flapChanges     []time.Time
+	flapHealthy     bool
+	flapSeen        bool
+
+	// Dependency checked by the probe, see DependencyOf
+	dependencyType   string
+	dependencyTarget string
+
+	// Planned downtimes, see Maintenance
+	maintenance []maintenanceWindow
+
+	// Latest run of the probe shared by concurrent callers and the number of shared runs, see runShared
+	flightMu sync.Mutex
+	flight   *probeFlight
+	shared   uint64
+
+	// Run of the probe in flight, which may hang after its timeout
+	runner boundedRunner
+
+	// Guards the settings changed at runtime by the admin API: timeout, critical, disabled and the injected fault
+	settingsMu sync.Mutex
+	disabled   bool
+	fault      *Fault
+	faultRuns  uint64
+}
+
+// Settings of a registered probe, which can be changed at runtime
+type probeSettings struct {
+	timeout  time.Duration
+	critical bool
+	disabled bool
+	// Whether the probe is within a maintenance window and skipped during it
+	maintenance bool
+	skipped     bool
+}
+
+func (p *registeredProbe) settings() probeSettings {
+	p.settingsMu.Lock()
+	defer p.settingsMu.Unlock()
+
+	return probeSettings{timeout: p.timeout, critical: p.critical, disabled: p.disabled}
+}
+
+// A ProbeOption configures how a registered probe is evaluated.
+type ProbeOption func(p *registeredProbe)
+
+// Fails the probe if it does not return within d. By default probes are not timed out. A probe still running after
+// its timeout is not started again until it returns, the following evaluations wait for that run instead.
+func Timeout(d time.Duration) ProbeOption {
+	return func(p *registeredProbe) {
+		p.timeout = d
+	}
+}
+
+// Marks the probe as non-critical. A failing non-critical probe is reported as reason,
+// but does not change the state of the service.
+func NonCritical() ProbeOption {
+	return func(p *registeredProbe) {
+		p.critical = false
+	}
+}
+
+func newRegisteredProbe(probe Probe, opts []ProbeOption) *registeredProbe {
+	p := &registeredProbe{
+		probe:    probe,
+		critical: true,
+	}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	if err := checkMetricLabels(p.labels); err != nil {
+		panic(fmt.Sprintf("a health probe should have distinct labels: %v", err))
+	}
+
+	return p
+}
+
+// Runs the probe wrapped by the middlewares honoring its timeout.
+func (p *registeredProbe) run(clock Clock, middlewares []ProbeMiddleware) error {
+	probe := chainProbe(p.withFault(clock), middlewares)
+
+	if timeout := p.settings().timeout; timeout > 0 {
+		return p.runner.runUntil(context.Background(), clock.After(timeout), timeout, probe)
+	}
+
+	return probe()
+}
+
+// Adds the result to the history, keeping at most size results.
+func (p *registeredProbe) record(r ProbeResult, size int) {
+	if size <= 0 {
+		return
+	}
+
+	p.historyMu.Lock()
+	defer p.historyMu.Unlock()
+
+	if len(p.history) < size {
+		p.history = append(p.history, r)
+		return
+	}
+
+	p.history[p.historyNext] = r
+	p.historyNext = (p.historyNext + 1) % len(p.history)
+}
+
+// Counts the result if the probe failed.
+func (p *registeredProbe) countFailure(r ProbeResult) {
+	if r.Err == nil {
+		return
+	}
+
+	p.failuresMu.Lock()
+	defer p.failuresMu.Unlock()
+
+	p.failures++
+	p.lastFailure = r
+}
+
+// Returns the number of failures and the latest failure.
+func (p *registeredProbe) failureCount() (uint64, ProbeResult) {
+	p.failuresMu.Lock()
+	defer p.failuresMu.Unlock()
+
+	return p.failures, p.lastFailure
+}
+
+// Adds the number of failures and the latest failure of the probe to the result.
+func (p *registeredProbe) withFailures(r ProbeResult) ProbeResult {
+	count, last := p.failureCount()
+	r.Failures = count
+	r.LastErr = last.Err
+	r.LastFailedAt = last.CheckedAt
+
+	return r
+}
+
+// Returns the recorded results, oldest first.
+func (p *registeredProbe) recent() []ProbeResult {
+	p.historyMu.Lock()
+	defer p.historyMu.Unlock()
+
+	return append(append([]ProbeResult{}, p.history[p.historyNext:]...), p.history[:p.historyNext]...)
+}
+
+// Add a probe which should be run each time the service is checked for liveness.
+// Only add probes detecting states the service can not recover from by itself, e.g. deadlocks or leaks,
+// as a failing liveness probe leads to a restart of the service.
+// Example:
+//		checker.AddLivenessProbe("goroutines", health.GoroutineProbe(10000))
+func (h *Checker) AddLivenessProbe(service string, probe Probe, opts ...ProbeOption) {
+	_, alreadyRegistered := h.livenessProbes[service]
+	if alreadyRegistered {
+		panic("a health probe should have a unique identifier")
+	}
+
+	if h.livenessProbes == nil {
+		h.livenessProbes = map[string]*registeredProbe{}
+	}
+
+	h.livenessProbes[service] = newRegisteredProbe(probe, opts)
 }
 
 // Add a probe which should be run each time the service is checked for readiness.
 // Example:
 //		conn, _ := grpc.Dial(...)
 //		checker.AddReadinessProbe("eventstore", health.GrpcProbe(conn))
-func (h *Checker) AddReadinessProbe(service string, probe Probe) {
+//		checker.AddReadinessProbe("cache", health.RedisPoolProbe(pool), health.Timeout(time.Second), health.NonCritical())
+func (h *Checker) AddReadinessProbe(service string, probe Probe, opts ...ProbeOption) {
 	_, alreadyRegistered := h.readinessProbes[service]
 	if alreadyRegistered {
 		panic("a health probe should have a unique identifier")
 	}
 
 	if h.readinessProbes == nil {
-		h.readinessProbes = map[string]Probe{}
+		h.readinessProbes = map[string]*registeredProbe{}
 	}
 
-	h.readinessProbes[service] = probe
+	h.readinessProbes[service] = newRegisteredProbe(probe, opts)
 }
 
-// Serves health status endpoints via http
-func (h *Checker) ServeHTTP(addr string) error {
-	if h.server != nil {
-		return fmt.Errorf("server is alrady running at %v", h.server.Addr)
+// Runs all liveness probes and returns whether the service is alive and the reasons of failing probes.
+func (h *Checker) IsAlive() (bool, []string) {
+	r := h.evaluate(context.Background(), h.livenessProbes)
+	return r.alive(), reasonStrings(r.Reasons)
+}
+
+// Runs all readiness probes and returns whether the service is ready and the reasons of failing probes.
+func (h *Checker) IsReady() (bool, []string) {
+	r := h.Check(context.Background())
+	return r.Ready, reasonStrings(r.Reasons)
+}
+
+// Runs all readiness probes and returns the detailed result. Probes not finished when ctx is done are reported
+// as timed out. The listeners are not notified, see AddListener.
+//
+// Example:
+//		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+//		defer cancel()
+//
+//		for _, reason := range checker.Check(ctx).Reasons {
+//			log.Printf("%v failed (%v): %v", reason.Service, reason.Kind, reason.Error)
+//		}
+func (h *Checker) Check(ctx context.Context) Result {
+	return h.evaluateReadiness(ctx, false)
+}
+
+// Returns a probe checking the readiness of h, so a checker owned by a library or module can be added as a single
+// probe to the checker of the application. The probe fails if h is not ready and reports a warning if h is degraded.
+// The error lists the reasons of h. While h evaluates in background, its latest result is used.
+//
+// Example:
+//		checker.AddReadinessProbe("billing", billing.HealthChecker().AsProbe())
+func (h *Checker) AsProbe() Probe {
+	return func() error {
+		r := h.readiness(context.Background(), nil)
+		if r.Status == StatusPass {
+			return nil
+		}
+
+		err := classify(ErrUnhealthy, errors.New(strings.Join(reasonStrings(r.Reasons), "; ")))
+		if r.Ready {
+			return Warning(err)
+		}
+
+		return err
 	}
+}
 
-	h.server = &http.Server{Addr: addr, Handler: h.serverMux()}
-	if err := h.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-		return fmt.Errorf("could not listen on %s: %v", addr, err)
+// Serves health status endpoints via http. Additional addresses are served by the same checker, e.g. a localhost-only
+// admin port and the pod IP for the kubelet. Blocks until all servers are shut down or one of them fails, which stops
+// the others. Fails right away if an address can not be bound or the checker is already serving. Pass port 0 to
+// listen on a free port, see Addr. Once the servers stopped, the checker can serve again.
+func (h *Checker) ServeHTTP(addr string, addrs ...string) error {
+	servers, listeners, err := h.listen(append([]string{addr}, addrs...))
+	if err != nil {
+		return err
 	}
+	defer h.release(servers)
 
-	return nil
+	return serve(servers, listeners)
 }
 
 // Serves health endpoint in background. Calls os.Exit(1) in error.
@@ -64,56 +509,468 @@ func (h *Checker) ServeHTTP(addr string) error {
 //		health := &Checker{}
 //		defer health.ServeHTTPBackground(":8080")()
 // 	}
-func (h *Checker) ServeHTTPBackground(addr string) func() {
-	go func() {
-		err := h.ServeHTTP(addr)
+//
+// Deprecated: Use StartHTTP, which returns the errors instead of exiting.
+func (h *Checker) ServeHTTPBackground(addr string, addrs ...string) func() {
+	stop, err := h.StartHTTP(addr, addrs...)
+	if err != nil {
+		h.logger().Printf("failed to start health server: %v", err)
+		os.Exit(1)
+	}
+
+	return func() {
+		err := stop()
 		if err != nil {
-			log.Fatalf("failed to start health server: %v", err)
+			h.logger().Printf("failed to shutdown health server: %v", err)
+			os.Exit(1)
+		}
+	}
+}
+
+// Serves health status endpoints via http in background, see ServeHTTP. Fails if an address can not be bound or the
+// checker is already serving. Servers failing later on are logged and stop the others. Returns a function gracefully
+// shutting the servers down.
+//
+// Example:
+//		stop, err := checker.StartHTTP(":8080")
+//		if err != nil {
+//			log.Fatal(err)
+//		}
+//		defer stop()
+func (h *Checker) StartHTTP(addr string, addrs ...string) (func() error, error) {
+	servers, listeners, err := h.listen(append([]string{addr}, addrs...))
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		defer h.release(servers)
+
+		if err := serve(servers, listeners); err != nil {
+			h.logger().Printf("health server failed: %v", err)
 		}
 	}()
 
-	return func() {
-		err := h.Shutdown()
+	return h.Shutdown, nil
+}
+
+// Listens on all addresses and creates a server for each of them. Fails if the servers are already running.
+func (h *Checker) listen(addrs []string) ([]*http.Server, []net.Listener, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if len(h.servers) > 0 {
+		return nil, nil, fmt.Errorf("server is already running at %v", h.servers[0].Addr)
+	}
+
+	handler := h.serverHandler()
+	servers := make([]*http.Server, 0, len(addrs))
+	listeners := make([]net.Listener, 0, len(addrs))
+	for _, addr := range addrs {
+		l, err := net.Listen("tcp", addr)
 		if err != nil {
-			log.Fatalf("failed to shutdown health server: %v", err)
+			for _, l := range listeners {
+				_ = l.Close()
+			}
+
+			return nil, nil, fmt.Errorf("could not listen on %s: %w", addr, err)
 		}
+
+		listeners = append(listeners, l)
+		servers = append(servers, &http.Server{Addr: l.Addr().String(), Handler: handler})
 	}
+
+	h.servers = servers
+	h.serverAddrs = make([]net.Addr, 0, len(listeners))
+	for _, l := range listeners {
+		h.serverAddrs = append(h.serverAddrs, l.Addr())
+	}
+
+	return servers, listeners, nil
+}
+
+// Forgets the servers once they stopped, so the checker can serve again. Servers replaced in the meantime are kept.
+func (h *Checker) release(servers []*http.Server) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if len(h.servers) > 0 && h.servers[0] == servers[0] {
+		h.servers = nil
+		h.serverAddrs = nil
+	}
+}
+
+// Returns the address the health endpoints are served at, e.g. to find the port chosen for `:0` in tests. With
+// multiple addresses, the first one is returned. Nil if the checker is not serving.
+//
+// Example:
+//		stop, _ := checker.StartHTTP("127.0.0.1:0")
+//		defer stop()
+//		resp, err := http.Get("http://" + checker.Addr().String() + "/.well-known/ready")
+func (h *Checker) Addr() net.Addr {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if len(h.serverAddrs) == 0 {
+		return nil
+	}
+
+	return h.serverAddrs[0]
 }
 
-// Gracefully stops health checker
+// Serves each listener by its server and waits until all servers stopped. Returns on the first error after closing
+// the other servers.
+func serve(servers []*http.Server, listeners []net.Listener) error {
+	errs := make(chan error, len(servers))
+	for i := range servers {
+		server, l := servers[i], listeners[i]
+		go func() {
+			if err := server.Serve(l); err != nil && err != http.ErrServerClosed {
+				errs <- fmt.Errorf("could not serve on %s: %w", server.Addr, err)
+				return
+			}
+
+			errs <- nil
+		}()
+	}
+
+	for range servers {
+		if err := <-errs; err != nil {
+			for _, server := range servers {
+				_ = server.Close()
+			}
+
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Gracefully stops health checker. It can serve again afterwards.
 func (h *Checker) Shutdown() error {
+	h.mu.Lock()
+	servers := h.servers
+	h.servers = nil
+	h.serverAddrs = nil
+	h.mu.Unlock()
+
 	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
 	defer cancel()
 
-	return h.server.Shutdown(ctx)
+	var firstErr error
+	for _, server := range servers {
+		if err := server.Shutdown(ctx); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
 }
 
-// Appends `/.well-known/alive` and `/.well-known/ready` endpoints to given server mux
+// Appends `/.well-known/alive`, `/.well-known/ready` and `/.well-known/history` endpoints to given server mux.
+// See AlivePath, ReadyPath and HistoryPath to change the paths.
+// JSON readiness responses only carry the readiness and the failing probes unless MediaTypeHealthV2 is accepted.
+// The detailed readiness response lists the probes that did not pass, add `?verbose=1` to list all probes.
+// Add `?brief=1` to omit the status details, reasons, timings, probe results and peers from the response.
+// HEAD requests and requests with `?quiet=1` are answered with the status code only.
+// Use `?exclude=name` or `?include=name`, which can be repeated, to evaluate a subset of the probes.
+// Each readiness probe is served at `/.well-known/ready/<name>` as well, responding with 503 if it did not pass.
+// If Pprof is set, the handlers of net/http/pprof are served at `/debug/pprof/`.
+// If OpenAPI is set, the OpenAPI document of the endpoints is served at `/.well-known/openapi.json` and the JSON Schema
+// of the responses at `/.well-known/schema.json`.
 func (h *Checker) AppendHealthEndpoints(m *http.ServeMux) {
-	m.HandleFunc("/.well-known/alive", func(w http.ResponseWriter, _ *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		_, _ = w.Write([]byte(`{"alive":true}`))
+	h.handle(m, h.alivePath(), func(w http.ResponseWriter, r *http.Request) {
+		keep, err := parseProbeFilter(r, h.livenessProbes)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		// Without liveness probes the service is always alive, the response is encoded once
+		if len(h.livenessProbes) == 0 {
+			h.writeCachedResponse(w, r, true, staticAliveState, func() interface{} {
+				return newAliveResponse(r, Result{Ready: true, Status: StatusPass})
+			})
+			return
+		}
+
+		ctx, cancel := h.withBudget(r)
+		defer cancel()
+
+		resp := newAliveResponse(r, h.evaluate(ctx, selectProbes(h.livenessProbes, keep)))
+		h.writeResponse(w, r, resp.Alive, resp)
+	})
+
+	h.handle(m, h.readyPath(), func(w http.ResponseWriter, r *http.Request) {
+		keep, err := parseProbeFilter(r, h.readinessProbes)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		// The latest result of the background evaluation is encoded once per evaluation and status of the peers
+		h.mu.Lock()
+		last, background := h.lastResult, h.background
+		h.mu.Unlock()
+		peers := h.cachedPeerStatus()
+
+		if background && last != nil && keep == nil {
+			result := h.withGrace(*last)
+			state := &responseState{checkedAt: result.CheckedAt, status: result.Status, ready: result.Ready}
+			if peers != nil {
+				state.peers = *peers
+			}
+			h.writeCachedResponse(w, r, result.Ready, state, func() interface{} {
+				return h.newReadyResponse(r, result, peers)
+			})
+			return
+		}
+
+		ctx, cancel := h.withBudget(r)
+		defer cancel()
+
+		result := h.readiness(ctx, keep)
+		h.writeResponse(w, r, result.Ready, h.newReadyResponse(r, result, peers))
+	})
+
+	h.handle(m, h.readyPath()+"/", func(w http.ResponseWriter, r *http.Request) {
+		name := strings.TrimPrefix(r.URL.Path, h.readyPath()+"/")
+		if _, ok := h.readinessProbes[name]; !ok {
+			http.NotFound(w, r)
+			return
+		}
+
+		ctx, cancel := h.withBudget(r)
+		defer cancel()
+
+		keep := func(service string) bool {
+			return service == name
+		}
+		result := h.readiness(ctx, keep)
+		if len(result.Probes) == 0 {
+			// A stored result of another process, e.g. during a rolling deploy, may lack the probe
+			result = h.evaluate(ctx, selectProbes(h.readinessProbes, keep))
+		}
+
+		status := newProbeStatuses(result.Probes)[0]
+		h.writeResponse(w, r, status.Healthy, &status)
 	})
 
-	m.HandleFunc("/.well-known/ready", func(w http.ResponseWriter, _ *http.Request) {
-		ok, reasons := runProbes(h.readinessProbes)
+	h.handle(m, h.historyPath(), func(w http.ResponseWriter, r *http.Request) {
+		h.writeResponse(w, r, true, newHistoryResponse(h.History()))
+	})
+
+	h.handle(m, h.dependenciesPath(), h.dependenciesHandler)
+	h.handle(m, h.statsPath(), h.statsHandler)
+
+	if h.Pprof {
+		m.Handle(pprofPath, pprofHandler())
+	}
+
+	if h.OpenAPI {
+		h.handle(m, openAPIPath, h.openAPIHandler)
+		h.handle(m, schemaPath, h.schemaHandler)
+	}
+}
+
+func newAliveResponse(r *http.Request, result Result) *AliveResponse {
+	resp := &AliveResponse{Alive: result.alive()}
+	if !queryFlag(r, "brief") {
+		resp.Status = result.Status
+		resp.Reasons = result.Reasons
+	}
+
+	return resp
+}
+
+func (h *Checker) newReadyResponse(r *http.Request, result Result, peers *PeerStatus) *ReadyResponse {
+	resp := &ReadyResponse{
+		Ready:    result.Ready,
+		Metadata: h.Metadata,
+	}
+
+	if !queryFlag(r, "brief") {
+		resp.Status = result.Status
+		resp.Reasons = result.Reasons
+		resp.CheckedAt = &result.CheckedAt
+		resp.Duration = result.Duration.String()
+		resp.Probes = newProbeStatuses(result.Probes)
+		if version, _ := requestedVersion(r); !queryFlag(r, "verbose") && version < 2 {
+			resp.Probes = withoutPassing(resp.Probes)
+		}
+		resp.Peers = peers
+	}
+
+	return resp
+}
+
+// Writes resp using the encoder of the checker. Responds with 503 if not ok. The body is omitted for HEAD requests and with `?quiet=1`.
+// JSON responses are labeled with the version requested by the Accept header, see MediaTypeHealthV2.
+// Responses are not to be cached without revalidation. Healthy responses carry an ETag and are answered with
+// 304 if the body did not change, e.g. while serving the same result of a background evaluation.
+func (h *Checker) writeResponse(w http.ResponseWriter, r *http.Request, ok bool, resp interface{}) {
+	h.writeCachedResponse(w, r, ok, nil, func() interface{} { return resp })
+}
+
+// Writes the response built by build like writeResponse. If state is given, the encoded response is reused for
+// requests to the same path with the same state, query and Accept header instead of building and encoding it again.
+func (h *Checker) writeCachedResponse(w http.ResponseWriter, r *http.Request, ok bool, state *responseState, build func() interface{}) {
+	encoder := h.encoder()
+	contentType := encoder.ContentType()
+
+	if _, isJSON := encoder.(JSONEncoder); isJSON {
+		w.Header().Add("Vary", "Accept")
+
+		version, ok := requestedVersion(r)
+		if !ok {
+			http.Error(w, "unsupported response version", http.StatusNotAcceptable)
+			return
+		}
+
+		if version > 0 {
+			contentType = versionedContentType(version)
+		}
+
+		if version < 2 {
+			detailed := build
+			build = func() interface{} {
+				v := detailed()
+				if ready, isReady := v.(*ReadyResponse); isReady {
+					return ready.v1()
+				}
+				return v
+			}
+		}
+	}
+
+	var key responseKey
+	if state != nil {
+		key = responseKey{state: *state, ok: ok, query: r.URL.RawQuery, accept: r.Header.Get("Accept")}
+	}
+
+	cached, found := h.cachedResponse(r.URL.Path, key, state != nil)
+	if !found {
+		var buf bytes.Buffer
+		if err := encoder.Encode(&buf, build()); err != nil {
+			h.logger().Printf("failed to write health-check response: %v", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		cached = &encodedResponse{key: key, body: buf.Bytes()}
+		if ok {
+			// Weak, as the body may be compressed
+			cached.etag = fmt.Sprintf(`W/"%x"`, sha256.Sum256(cached.body))
+		}
 
-		resp := &readyResponse{
-			Ready:   ok,
-			Reasons: reasons,
+		if state != nil {
+			h.cacheResponse(r.URL.Path, cached)
 		}
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Cache-Control", "no-cache")
 
-		w.Header().Set("Content-Type", "application/json")
+	if !ok {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	} else {
+		w.Header().Set("ETag", cached.etag)
 
-		if !resp.Ready {
-			w.WriteHeader(http.StatusServiceUnavailable)
+		if r.Header.Get("If-None-Match") == cached.etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
 		}
+	}
+
+	if r.Method == http.MethodHead || queryFlag(r, "quiet") {
+		return
+	}
+
+	_, _ = w.Write(cached.body)
+}
+
+// Returns true if the query parameter is present and not set to a false value, e.g. `?brief` or `?brief=1`.
+func queryFlag(r *http.Request, name string) bool {
+	values, ok := r.URL.Query()[name]
+	if !ok {
+		return false
+	}
+
+	if len(values) == 0 || values[0] == "" {
+		return true
+	}
+
+	enabled, err := strconv.ParseBool(values[0])
+	return err == nil && enabled
+}
+
+func (h *Checker) alivePath() string {
+	if h.AlivePath == "" {
+		return defaultAlivePath
+	}
+
+	return h.AlivePath
+}
+
+func (h *Checker) readyPath() string {
+	if h.ReadyPath == "" {
+		return defaultReadyPath
+	}
+
+	return h.ReadyPath
+}
+
+func (h *Checker) historyPath() string {
+	if h.HistoryPath == "" {
+		return defaultHistoryPath
+	}
+
+	return h.HistoryPath
+}
+
+func (h *Checker) historySize() int {
+	if h.HistorySize == 0 {
+		return defaultHistorySize
+	}
+
+	return h.HistorySize
+}
+
+// Registers the handler of a health endpoint at m applying the configured middlewares.
+func (h *Checker) handle(m *http.ServeMux, pattern string, handler http.HandlerFunc) {
+	var next http.Handler = handler
+	if h.Compress {
+		next = gzipHandler(next)
+	}
+
+	if h.CORS != nil {
+		next = corsHandler(h.CORS, next)
+	}
+
+	if h.AccessLog {
+		next = accessLogHandler(h.logger(), next)
+	}
+
+	m.Handle(pattern, next)
+}
 
-		if b, err := json.Marshal(resp); err == nil {
-			_, _ = w.Write(b)
-		} else {
-			log.Printf("failed to write health-check response: %v\n", err)
+// Returns a handler serving the health endpoints and passing all other requests to next, so health can be served
+// on the same port as the API without an own http.Server or ServeMux.
+//
+// Example:
+//		api := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { ... })
+//		_ = http.ListenAndServe(":8080", checker.Middleware(api))
+func (h *Checker) Middleware(next http.Handler) http.Handler {
+	m := h.serverMux()
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, pattern := m.Handler(r); pattern != "" {
+			m.ServeHTTP(w, r)
+			return
 		}
+
+		next.ServeHTTP(w, r)
 	})
 }
 
@@ -125,29 +982,142 @@ func (h *Checker) serverMux() *http.ServeMux {
 	return m
 }
 
-// Runs through all probes in parallel and returns ok and a list of reasons
-func runProbes(probes map[string]Probe) (bool, []string) {
-	wg := sync.WaitGroup{}
-	m := sync.Mutex{}
-	var reasons []string
+// Returns the handler of the health server.
+func (h *Checker) serverHandler() http.Handler {
+	if h.H2C {
+		return h2c.NewHandler(h.serverMux(), &http2.Server{})
+	}
+
+	return h.serverMux()
+}
+
+// Runs through all probes in parallel, records their results in the history and returns them ordered by name.
+// Probes not finished when ctx is done or a critical probe failed in fail-fast mode are reported as failed.
+func (h *Checker) evaluateProbes(ctx context.Context, probes map[string]*registeredProbe) []ProbeResult {
+	results := make([]ProbeResult, 0, len(probes))
+	for service := range probes {
+		results = append(results, ProbeResult{Name: service, Labels: copyLabels(probes[service].labels)})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Name < results[j].Name
+	})
+
+	type finished struct {
+		index  int
+		result ProbeResult
+	}
 
-	for service, probe := range probes {
-		wg.Add(1)
+	h.mu.Lock()
+	background := h.background
+	h.mu.Unlock()
 
-		probe := probe
-		service := service
+	done := make(chan finished, len(results))
+	clock := h.clock()
+	start := clock.Now()
+	for i, result := range results {
+		i, result := i, result
+		probe := probes[result.Name]
 		go func() {
-			if err := probe(); err != nil {
-				m.Lock()
-				reasons = append(reasons, fmt.Sprintf("%v: %v", service, err))
-				m.Unlock()
+			settings := probe.settingsAt(clock.Now())
+			result.Maintenance = settings.maintenance
+			if settings.disabled || settings.skipped {
+				result.CheckedAt = clock.Now()
+				result.Critical = settings.critical
+				result.Status = StatusPass
+				result.Disabled = settings.disabled
+				done <- finished{index: i, result: probe.withFailures(result)}
+				return
+			}
+
+			if background {
+				if scheduled, ok := probe.scheduledResult(clock.Now()); ok {
+					scheduled.Name = result.Name
+					scheduled.Labels = result.Labels
+					// The result may have been computed before a maintenance window started
+					scheduled.Maintenance = result.Maintenance
+					scheduled.Critical = settings.critical
+					scheduled.Status = probeStatusOf(scheduled.Err, settings.critical)
+					done <- finished{index: i, result: probe.withFailures(scheduled)}
+					return
+				}
 			}
 
-			wg.Done()
+			result.CheckedAt = clock.Now()
+			result.Critical = settings.critical
+			err, shared := probe.runShared(clock, h.ProbeMiddlewares, h.ProbeDedupWindow)
+			result.Err = h.redact(err)
+			result.Status = probeStatusOf(result.Err, settings.critical)
+			result.Duration = clock.Now().Sub(result.CheckedAt)
+			result = probe.dampen(result)
+			// Shared runs were recorded by the caller running the probe
+			if !shared {
+				probe.record(result, h.historySize())
+				probe.countFailure(result)
+				probe.countAvailability(result)
+			}
+			probe.schedule(result)
+
+			done <- finished{index: i, result: probe.withFailures(result)}
 		}()
 	}
 
-	wg.Wait()
+	pending := map[int]bool{}
+	for i := range results {
+		pending[i] = true
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Name of the critical probe that failed in fail-fast mode
+	var failed string
+
+	for len(pending) > 0 {
+		select {
+		case f := <-done:
+			results[f.index] = f.result
+			delete(pending, f.index)
+
+			if h.FailFast && failed == "" && f.result.Status == StatusFail {
+				failed = f.result.Name
+				// Only stops waiting, the probes can not be interrupted
+				cancel()
+			}
+		case <-ctx.Done():
+			err := fmt.Errorf("probe did not finish: %w", ctx.Err())
+			if failed != "" {
+				err = fmt.Errorf("probe canceled as %v failed: %w", failed, ctx.Err())
+			}
+
+			for i := range pending {
+				critical := probes[results[i].Name].settingsAt(start).critical
+				results[i].CheckedAt = start
+				results[i].Critical = critical
+				results[i].Err = err
+				results[i].Status = probeStatusOf(results[i].Err, critical)
+				results[i].Duration = clock.Now().Sub(start)
+			}
+
+			return results
+		}
+	}
+
+	return results
+}
+
+// Returns the worst status and the reasons of the given results.
+func summarize(results []ProbeResult) (Status, []Reason) {
+	status := StatusPass
+	var reasons []Reason
+
+	for _, result := range results {
+		if result.Err != nil {
+			reasons = append(reasons, newReason(result))
+		}
+
+		status = status.worse(result.Status)
+	}
 
-	return len(reasons) == 0, reasons
+	return status, reasons
 }
@@ -1,13 +1,20 @@
 package health
 
 import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/http2"
 )
 
 func TestChecker_alive(t *testing.T) {
@@ -58,6 +65,517 @@ func TestChecker_AddHealthyProbe_unhealthy(t *testing.T) {
 
 	assert.NoError(t, err)
 	assert.EqualValues(t, http.StatusServiceUnavailable, resp.StatusCode)
+	var body ReadyResponseV1
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.False(t, body.Ready)
+	assert.Equal(t, []string{"my-service: unhealthy"}, body.Reasons)
+}
+
+func TestChecker_AddLivenessProbe_unhealthy(t *testing.T) {
+	checker := &Checker{}
+	checker.AddLivenessProbe("deadlock", func() error {
+		return fmt.Errorf("worker is stuck")
+	})
+
+	server := httptest.NewServer(checker.serverMux())
+	defer server.Close()
+
+	resp, err := http.Get(fmt.Sprintf("%v/.well-known/alive", server.URL))
+
+	assert.NoError(t, err)
+	assert.EqualValues(t, http.StatusServiceUnavailable, resp.StatusCode)
+	body, _ := ioutil.ReadAll(resp.Body)
+	assert.Contains(t, string(body), `"alive":false`)
+	assert.Contains(t, string(body), `{"service":"deadlock","error":"worker is stuck","kind":"error"}`)
+}
+
+func TestChecker_ready_head(t *testing.T) {
+	checker := &Checker{}
+	checker.AddReadinessProbe("my-service", func() error {
+		return fmt.Errorf("unhealthy")
+	})
+
+	server := httptest.NewServer(checker.serverMux())
+	defer server.Close()
+
+	resp, err := http.Head(fmt.Sprintf("%v/.well-known/ready", server.URL))
+
+	assert.NoError(t, err)
+	assert.EqualValues(t, http.StatusServiceUnavailable, resp.StatusCode)
+	body, _ := ioutil.ReadAll(resp.Body)
+	assert.Empty(t, body)
+}
+
+func TestChecker_ready_quiet(t *testing.T) {
+	checker := &Checker{}
+	checker.AddReadinessProbe("my-service", func() error {
+		return fmt.Errorf("unhealthy")
+	})
+
+	server := httptest.NewServer(checker.serverMux())
+	defer server.Close()
+
+	resp, err := http.Get(fmt.Sprintf("%v/.well-known/ready?quiet", server.URL))
+
+	assert.NoError(t, err)
+	assert.EqualValues(t, http.StatusServiceUnavailable, resp.StatusCode)
+	body, _ := ioutil.ReadAll(resp.Body)
+	assert.Empty(t, body)
+}
+
+func TestChecker_ready_verbose(t *testing.T) {
+	checker := &Checker{}
+	checker.AddReadinessProbe("healthy-service", func() error { return nil })
+	checker.AddReadinessProbe("broken-service", func() error {
+		return fmt.Errorf("unhealthy")
+	})
+
+	result := checker.evaluateReadiness(context.Background(), false)
+
+	for query, expected := range map[string][]string{
+		"":         {"broken-service"},
+		"?verbose": {"broken-service", "healthy-service"},
+	} {
+		ready := checker.newReadyResponse(httptest.NewRequest(http.MethodGet, "/.well-known/ready"+query, nil), result, nil)
+
+		var names []string
+		for _, p := range ready.Probes {
+			names = append(names, p.Name)
+		}
+
+		assert.Equal(t, expected, names, query)
+	}
+}
+
+func TestChecker_ready_brief(t *testing.T) {
+	checker := &Checker{}
+	checker.AddReadinessProbe("my-service", func() error {
+		return fmt.Errorf("unhealthy")
+	})
+
+	server := httptest.NewServer(checker.serverMux())
+	defer server.Close()
+
+	resp, err := http.Get(fmt.Sprintf("%v/.well-known/ready?brief=1", server.URL))
+
+	assert.NoError(t, err)
+	assert.EqualValues(t, http.StatusServiceUnavailable, resp.StatusCode)
+	body, _ := ioutil.ReadAll(resp.Body)
+	assert.JSONEq(t, `{"ready":false}`, string(body))
+}
+
+func TestChecker_AddReadinessProbe_timeout(t *testing.T) {
+	checker := &Checker{}
+	checker.AddReadinessProbe("slow-service", func() error {
+		time.Sleep(time.Second)
+		return nil
+	}, Timeout(10*time.Millisecond))
+
+	r := checker.evaluate(context.Background(), checker.readinessProbes)
+
+	assert.False(t, r.Ready)
+	assert.Contains(t, r.Reasons[0].String(), "slow-service: timed out")
+}
+
+func TestChecker_AddReadinessProbe_nonCritical(t *testing.T) {
+	checker := &Checker{}
+	checker.AddReadinessProbe("optional-service", func() error {
+		return fmt.Errorf("unhealthy")
+	}, NonCritical())
+
+	r := checker.evaluate(context.Background(), checker.readinessProbes)
+
+	assert.True(t, r.Ready)
+	assert.Equal(t, []string{"optional-service: unhealthy"}, reasonStrings(r.Reasons))
+}
+
+func TestChecker_customPaths(t *testing.T) {
+	checker := &Checker{AlivePath: "/healthz", ReadyPath: "/readyz"}
+
+	server := httptest.NewServer(checker.serverMux())
+	defer server.Close()
+
+	resp, err := http.Get(fmt.Sprintf("%v/healthz", server.URL))
+	assert.NoError(t, err)
+	assert.EqualValues(t, http.StatusOK, resp.StatusCode)
+
+	resp, err = http.Get(fmt.Sprintf("%v/readyz", server.URL))
+	assert.NoError(t, err)
+	assert.EqualValues(t, http.StatusOK, resp.StatusCode)
+
+	resp, err = http.Get(fmt.Sprintf("%v/.well-known/ready", server.URL))
+	assert.NoError(t, err)
+	assert.EqualValues(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestChecker_EvaluateInBackground(t *testing.T) {
+	calls := 0
+	evaluated := make(chan Result, 10)
+
+	checker := &Checker{}
+	checker.AddReadinessProbe("my-service", func() error {
+		calls++
+		return fmt.Errorf("unhealthy")
+	})
+	checker.AddListener(func(r Result) {
+		evaluated <- r
+	})
+
+	stop := checker.EvaluateInBackground(time.Hour)
+	defer stop()
+	<-evaluated
+
+	server := httptest.NewServer(checker.serverMux())
+	defer server.Close()
+
+	resp, err := http.Get(fmt.Sprintf("%v/.well-known/ready", server.URL))
+
+	assert.NoError(t, err)
+	assert.EqualValues(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, 1, calls, "the probe must not run on request")
+	assert.Panics(t, func() {
+		checker.EvaluateInBackground(time.Hour)
+	})
+}
+
+func TestChecker_metadata(t *testing.T) {
+	checker := &Checker{Metadata: map[string]string{"zone": "eu-central-1a"}}
+	server := httptest.NewServer(checker.serverMux())
+	defer server.Close()
+
+	resp, err := http.DefaultClient.Do(detailedRequest(fmt.Sprintf("%v/.well-known/ready", server.URL)))
+
+	assert.NoError(t, err)
+	body, _ := ioutil.ReadAll(resp.Body)
+	assert.Contains(t, string(body), `"metadata":{"zone":"eu-central-1a"}`)
+}
+
+func TestChecker_ready_durations(t *testing.T) {
+	checker := &Checker{}
+	checker.AddReadinessProbe("slow-service", func() error {
+		time.Sleep(10 * time.Millisecond)
+		return nil
+	})
+
+	server := httptest.NewServer(checker.serverMux())
+	defer server.Close()
+
+	resp, err := http.DefaultClient.Do(detailedRequest(fmt.Sprintf("%v/.well-known/ready", server.URL)))
+	assert.NoError(t, err)
+
+	var ready ReadyResponse
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&ready))
+	assert.WithinDuration(t, time.Now(), *ready.CheckedAt, time.Second)
+	assert.Len(t, ready.Probes, 1)
+
+	duration, _ := time.ParseDuration(ready.Probes[0].Duration)
+	assert.GreaterOrEqual(t, int64(duration), int64(10*time.Millisecond))
+}
+
+func TestChecker_Check(t *testing.T) {
+	checker := &Checker{}
+	checker.AddReadinessProbe("cache", func() error {
+		return fmt.Errorf("dial tcp: connection refused: cache:6379")
+	})
+	checker.AddReadinessProbe("slow", func() error {
+		time.Sleep(time.Second)
+		return nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	result := checker.Check(ctx)
+
+	assert.False(t, result.Ready)
+	assert.Equal(t, []Reason{
+		{Service: "cache", Error: "dial tcp: connection refused: cache:6379", Kind: KindError},
+		{Service: "slow", Error: "probe did not finish: context deadline exceeded", Kind: KindTimeout},
+	}, result.Reasons)
+}
+
+func TestChecker_Check_timeoutKind(t *testing.T) {
+	checker := &Checker{}
+	checker.AddReadinessProbe("slow", func() error {
+		time.Sleep(time.Second)
+		return nil
+	}, Timeout(10*time.Millisecond))
+
+	result := checker.Check(context.Background())
+
+	assert.Equal(t, KindTimeout, result.Reasons[0].Kind)
+}
+
+func TestChecker_FailFast(t *testing.T) {
+	checker := &Checker{FailFast: true}
+	checker.AddReadinessProbe("cache", func() error {
+		return fmt.Errorf("connection refused")
+	})
+	checker.AddReadinessProbe("slow", func() error {
+		time.Sleep(time.Second)
+		return nil
+	})
+
+	start := time.Now()
+	result := checker.Check(context.Background())
+
+	assert.Less(t, int64(time.Since(start)), int64(500*time.Millisecond))
+	assert.False(t, result.Ready)
+	assert.Equal(t, []Reason{
+		{Service: "cache", Error: "connection refused", Kind: KindError},
+		{Service: "slow", Error: "probe canceled as cache failed: context canceled", Kind: KindError},
+	}, result.Reasons)
+}
+
+func TestChecker_ServeHTTP_multipleAddresses(t *testing.T) {
+	checker := &Checker{}
+	stop, err := checker.StartHTTP("127.0.0.1:0", "127.0.0.1:0")
+	assert.NoError(t, err)
+
+	checker.mu.Lock()
+	servers := checker.servers
+	checker.mu.Unlock()
+
+	assert.Len(t, servers, 2)
+	for _, server := range servers {
+		resp, err := http.Get(fmt.Sprintf("http://%v/.well-known/alive", server.Addr))
+		assert.NoError(t, err)
+		assert.EqualValues(t, http.StatusOK, resp.StatusCode)
+		_ = resp.Body.Close()
+	}
+
+	assert.Error(t, checker.ServeHTTP("127.0.0.1:0"), "already running")
+	_, err = checker.StartHTTP("127.0.0.1:0")
+	assert.Error(t, err, "already running")
+
+	assert.NoError(t, stop())
+	for _, server := range servers {
+		_, err := http.Get(fmt.Sprintf("http://%v/.well-known/alive", server.Addr))
+		assert.Error(t, err)
+	}
+}
+
+func TestChecker_ServeHTTP_restart(t *testing.T) {
+	checker := &Checker{}
+	assert.Nil(t, checker.Addr())
+
+	for i := 0; i < 2; i++ {
+		stop, err := checker.StartHTTP("127.0.0.1:0")
+		assert.NoError(t, err)
+
+		addr := checker.Addr()
+		assert.NotNil(t, addr)
+		resp, err := http.Get(fmt.Sprintf("http://%v/.well-known/alive", addr))
+		assert.NoError(t, err)
+		assert.EqualValues(t, http.StatusOK, resp.StatusCode)
+		_ = resp.Body.Close()
+
+		assert.NoError(t, stop())
+		assert.Nil(t, checker.Addr())
+	}
+}
+
+func TestChecker_ServeHTTP_bindFailure(t *testing.T) {
+	running := &Checker{}
+	defer running.ServeHTTPBackground("127.0.0.1:0")()
+
+	checker := &Checker{}
+	err := checker.ServeHTTP(running.Addr().String())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "could not listen on")
+	assert.Nil(t, checker.Addr())
+
+	_, err = checker.StartHTTP("127.0.0.1:0", running.Addr().String())
+	assert.Error(t, err)
+	assert.Nil(t, checker.Addr())
+
+	// The checker serves once the address is free
+	stop, err := checker.StartHTTP("127.0.0.1:0")
+	assert.NoError(t, err)
+	assert.NotNil(t, checker.Addr())
+	assert.NoError(t, stop())
+}
+
+func TestServe_firstError(t *testing.T) {
+	healthy, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	broken, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	_ = broken.Close()
+
+	servers := []*http.Server{{Addr: healthy.Addr().String()}, {Addr: broken.Addr().String()}}
+	err = serve(servers, []net.Listener{healthy, broken})
+	assert.Error(t, err, "returns without waiting for the healthy server")
+	assert.Contains(t, err.Error(), "could not serve on "+broken.Addr().String())
+}
+
+func TestChecker_Middleware(t *testing.T) {
+	checker := &Checker{}
+	checker.AddReadinessProbe("redis", func() error {
+		return fmt.Errorf("connection refused")
+	})
+
+	api := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("api"))
+	})
+	server := httptest.NewServer(checker.Middleware(api))
+	defer server.Close()
+
+	for path, expected := range map[string]int{
+		"/.well-known/ready":       http.StatusServiceUnavailable,
+		"/.well-known/ready/redis": http.StatusServiceUnavailable,
+		"/.well-known/alive":       http.StatusOK,
+		"/orders":                  http.StatusOK,
+	} {
+		resp, err := http.Get(server.URL + path)
+		assert.NoError(t, err)
+		_ = resp.Body.Close()
+		assert.EqualValues(t, expected, resp.StatusCode, path)
+	}
+
+	resp, err := http.Get(server.URL + "/orders")
+	assert.NoError(t, err)
+	defer resp.Body.Close()
 	body, _ := ioutil.ReadAll(resp.Body)
-	assert.Contains(t, string(body), "my-service: unhealthy")
+	assert.Equal(t, "api", string(body))
+}
+
+func TestChecker_H2C(t *testing.T) {
+	checker := &Checker{H2C: true}
+	server := httptest.NewServer(checker.serverHandler())
+	defer server.Close()
+
+	client := &http.Client{Transport: &http2.Transport{
+		AllowHTTP: true,
+		DialTLS: func(network, addr string, _ *tls.Config) (net.Conn, error) {
+			return net.Dial(network, addr)
+		},
+	}}
+
+	resp, err := client.Get(fmt.Sprintf("%v/.well-known/ready", server.URL))
+
+	assert.NoError(t, err)
+	assert.EqualValues(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, 2, resp.ProtoMajor)
+}
+
+func TestChecker_AsProbe(t *testing.T) {
+	module := &Checker{}
+	module.AddReadinessProbe("queue", func() error {
+		return fmt.Errorf("connection refused")
+	})
+	module.AddReadinessProbe("cache", func() error {
+		return fmt.Errorf("timeout")
+	}, NonCritical())
+
+	checker := &Checker{}
+	checker.AddReadinessProbe("billing", module.AsProbe())
+
+	result := checker.Check(context.Background())
+
+	assert.False(t, result.Ready)
+	assert.Equal(t, []Reason{
+		{Service: "billing", Error: "cache: timeout; queue: connection refused", Kind: KindUnhealthy},
+	}, result.Reasons)
+}
+
+func TestChecker_AsProbe_degraded(t *testing.T) {
+	module := &Checker{}
+	module.AddReadinessProbe("cache", func() error {
+		return fmt.Errorf("timeout")
+	}, NonCritical())
+
+	err := module.AsProbe()()
+
+	assert.Equal(t, StatusWarn, probeStatusOf(err, true))
+	assert.True(t, errors.Is(err, ErrUnhealthy))
+}
+
+func TestChecker_ready_probe(t *testing.T) {
+	checker := &Checker{}
+	checker.AddReadinessProbe("database", func() error { return nil })
+	checker.AddReadinessProbe("redis", func() error {
+		return fmt.Errorf("connection refused")
+	})
+
+	server := httptest.NewServer(checker.serverMux())
+	defer server.Close()
+
+	for path, expected := range map[string]int{
+		"database": http.StatusOK,
+		"redis":    http.StatusServiceUnavailable,
+		"unknown":  http.StatusNotFound,
+	} {
+		resp, err := http.Get(fmt.Sprintf("%v/.well-known/ready/%v", server.URL, path))
+
+		assert.NoError(t, err)
+		assert.EqualValues(t, expected, resp.StatusCode, path)
+	}
+
+	resp, _ := http.Get(fmt.Sprintf("%v/.well-known/ready/redis", server.URL))
+	var status ProbeStatus
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&status))
+	assert.Equal(t, "connection refused", status.Error)
+}
+
+func TestChecker_ready_etag(t *testing.T) {
+	checker := &Checker{}
+	checker.AddReadinessProbe("database", func() error { return nil })
+
+	evaluated := make(chan Result, 1)
+	checker.AddListener(func(r Result) { evaluated <- r })
+	defer checker.EvaluateInBackground(time.Hour)()
+	<-evaluated
+
+	server := httptest.NewServer(checker.serverMux())
+	defer server.Close()
+
+	resp, err := http.Get(fmt.Sprintf("%v/.well-known/ready", server.URL))
+	assert.NoError(t, err)
+	assert.Equal(t, "no-cache", resp.Header.Get("Cache-Control"))
+	etag := resp.Header.Get("ETag")
+	assert.NotEmpty(t, etag)
+
+	req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("%v/.well-known/ready", server.URL), nil)
+	req.Header.Set("If-None-Match", etag)
+	resp, err = http.DefaultClient.Do(req)
+
+	assert.NoError(t, err)
+	assert.EqualValues(t, http.StatusNotModified, resp.StatusCode)
+}
+
+func TestChecker_ready_lastFailure(t *testing.T) {
+	var err error
+	checker := &Checker{}
+	checker.AddReadinessProbe("broker", func() error { return err })
+
+	err = errors.New("connection reset")
+	checker.readiness(context.Background(), nil)
+	err = nil
+
+	r := checker.readiness(context.Background(), nil)
+	assert.Equal(t, StatusPass, r.Probes[0].Status)
+	assert.Equal(t, uint64(1), r.Probes[0].Failures)
+	assert.EqualError(t, r.Probes[0].LastErr, "connection reset")
+
+	server := httptest.NewServer(checker.serverMux())
+	defer server.Close()
+
+	resp, getErr := http.DefaultClient.Do(detailedRequest(server.URL + "/.well-known/ready"))
+	assert.NoError(t, getErr)
+	defer resp.Body.Close()
+
+	var body ReadyResponse
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, uint64(1), body.Probes[0].Failures)
+	assert.Equal(t, "connection reset", body.Probes[0].LastError)
+	assert.NotNil(t, body.Probes[0].LastFailureAt)
+}
+
+// Returns a request for the detailed response of the endpoint at url, see MediaTypeHealthV2.
+func detailedRequest(url string) *http.Request {
+	req, _ := http.NewRequest(http.MethodGet, url, nil)
+	req.Header.Set("Accept", MediaTypeHealthV2)
+
+	return req
 }
@@ -0,0 +1,54 @@
+package health
+
+import "time"
+
+// A Clock tells the time to the checker. Replace it to test time-dependent behavior like grace periods, flap
+// detection, probe intervals, timeouts and the background evaluation without real sleeps. See healthtest.Clock.
+type Clock interface {
+	// Returns the current time
+	Now() time.Time
+	// Returns a channel receiving the current time after d
+	After(d time.Duration) <-chan time.Time
+	// Returns a ticker sending the current time every d
+	NewTicker(d time.Duration) Ticker
+}
+
+// A Ticker sends the current time at intervals, like time.Ticker.
+type Ticker interface {
+	// Channel the ticks are sent to
+	C() <-chan time.Time
+	// Stops the ticker
+	Stop()
+}
+
+// Clock using the time package
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+func (realClock) NewTicker(d time.Duration) Ticker {
+	return realTicker{time.NewTicker(d)}
+}
+
+type realTicker struct {
+	*time.Ticker
+}
+
+func (t realTicker) C() <-chan time.Time {
+	return t.Ticker.C
+}
+
+// Returns the clock of the checker or the real clock.
+func (h *Checker) clock() Clock {
+	if h.Clock == nil {
+		return realClock{}
+	}
+
+	return h.Clock
+}
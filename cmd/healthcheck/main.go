@@ -0,0 +1,155 @@
+// Command healthcheck runs probes of the healthchecker package from the command line.
+// Probes are given as URIs (see health.ProbeFromURI) or by a config file (see health.LoadConfig).
+//
+// Usage:
+//		healthcheck -probe redis=redis://redis:6379 -probe api=http://api:8080/.well-known/alive
+//		healthcheck -config health.yaml -interval 10s
+//		healthcheck -config health.yaml -listen :8080
+//		healthcheck -config health.yaml -interval 10s -state-file /run/health/state.json
+//		healthcheck -probe app=statefile:///run/health/state.json?max_age=30s
+//
+// Without -interval and -listen the probes are run once and the exit code is 1 if any critical probe fails.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	health "github.com/regiocom/healthchecker"
+)
+
+// Collects repeated string flags
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(v string) error {
+	*l = append(*l, v)
+	return nil
+}
+
+func main() {
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+
+	ok, err := run(os.Args[1:], os.Stdout, stop)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if !ok {
+		os.Exit(1)
+	}
+}
+
+// Runs the probes given by args and prints the results to out. Without -interval and -listen the probes are run once
+// and false is returned if the service is not alive or not ready, otherwise it runs until stop receives.
+func run(args []string, out io.Writer, stop <-chan os.Signal) (bool, error) {
+	flags := flag.NewFlagSet("healthcheck", flag.ExitOnError)
+	var readiness, liveness stringList
+	flags.Var(&readiness, "probe", "readiness probe as `name=uri`, can be repeated")
+	flags.Var(&liveness, "liveness", "liveness probe as `name=uri`, can be repeated")
+	configPath := flags.String("config", "", "YAML or JSON config file")
+	timeout := flags.Duration("timeout", 5*time.Second, "timeout of probes given by flags")
+	interval := flags.Duration("interval", 0, "run the probes repeatedly at the given interval")
+	listen := flags.String("listen", "", "serve the health endpoints at the given address, e.g. :8080")
+	stateFile := flags.String("state-file", "", "write the state of the service to the given file after each run")
+	_ = flags.Parse(args)
+
+	checker, err := newChecker(*configPath, readiness, liveness, *timeout)
+	if err != nil {
+		return false, err
+	}
+
+	if *stateFile != "" {
+		checker.AddListener(health.StateFileListener(*stateFile))
+	}
+
+	if *listen != "" {
+		stopServer, err := checker.StartHTTP(*listen)
+		if err != nil {
+			return false, err
+		}
+		defer stopServer()
+	}
+
+	if *interval <= 0 && *listen == "" {
+		r := checker.Check(context.Background())
+
+		// Listeners are only notified by background evaluations
+		if *stateFile != "" {
+			health.StateFileListener(*stateFile)(r)
+		}
+
+		return report(out, checker, r), nil
+	}
+
+	if *interval > 0 {
+		checker.AddListener(func(r health.Result) {
+			report(out, checker, r)
+		})
+		defer checker.EvaluateInBackground(*interval)()
+	}
+
+	<-stop
+	return true, nil
+}
+
+func newChecker(configPath string, readiness, liveness []string, timeout time.Duration) (*health.Checker, error) {
+	cfg := &health.Config{}
+	if configPath != "" {
+		var err error
+		cfg, err = health.LoadConfig(configPath)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for kind, specs := range map[string][]string{"readiness": readiness, "liveness": liveness} {
+		for _, spec := range specs {
+			parts := strings.SplitN(spec, "=", 2)
+			if len(parts) != 2 {
+				return nil, fmt.Errorf("invalid probe %q, expected name=uri", spec)
+			}
+
+			cfg.Probes = append(cfg.Probes, health.ProbeConfig{
+				Name:    parts[0],
+				Kind:    kind,
+				URI:     parts[1],
+				Timeout: timeout.String(),
+			})
+		}
+	}
+
+	if len(cfg.Probes) == 0 {
+		return nil, fmt.Errorf("no probes given, use -probe, -liveness or -config")
+	}
+
+	return health.NewCheckerFromConfig(cfg)
+}
+
+// Runs the liveness probes, prints them with the result of the readiness probes and returns true if the service is
+// alive and ready.
+func report(out io.Writer, checker *health.Checker, r health.Result) bool {
+	alive, reasons := checker.IsAlive()
+	for _, reason := range r.Reasons {
+		reasons = append(reasons, reason.String())
+	}
+
+	fmt.Fprintf(out, "%v alive=%v ready=%v\n", time.Now().Format(time.RFC3339), alive, r.Ready)
+	for _, reason := range reasons {
+		fmt.Fprintf(out, "  - %v\n", reason)
+	}
+
+	return alive && r.Ready
+}
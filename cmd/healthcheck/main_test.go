@@ -0,0 +1,55 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	health "github.com/regiocom/healthchecker"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRun_stateFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "healthcheck")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "state.json")
+
+	stop := make(chan os.Signal, 1)
+	done := make(chan bool)
+	go func() {
+		ok, err := run([]string{"-probe", "g=goroutines://?max=10000", "-interval", "10ms", "-state-file", path},
+			ioutil.Discard, stop)
+		assert.NoError(t, err)
+		done <- ok
+	}()
+
+	assert.Eventually(t, func() bool {
+		state, err := health.ReadStateFile(path, 0)
+		return err == nil && state.Ready
+	}, time.Second, 10*time.Millisecond)
+
+	stop <- os.Interrupt
+	assert.True(t, <-done)
+}
+
+func TestRun_once(t *testing.T) {
+	dir, err := ioutil.TempDir("", "healthcheck")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "state.json")
+
+	var out bytes.Buffer
+	ok, err := run([]string{"-probe", "g=goroutines://?max=1", "-state-file", path}, &out, nil)
+
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	assert.Contains(t, out.String(), "ready=false")
+
+	state, err := health.ReadStateFile(path, 0)
+	assert.NoError(t, err)
+	assert.False(t, state.Ready)
+}
@@ -0,0 +1,175 @@
+package health
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"time"
+)
+
+const (
+	defaultCollectorTimeout = 10 * time.Second
+	defaultCollectorRetries = 3
+	defaultCollectorBackoff = time.Second
+)
+
+type collectorConfig struct {
+	client   *http.Client
+	service  string
+	instance string
+	header   http.Header
+	retries  int
+	backoff  time.Duration
+}
+
+// A CollectorOption configures a CollectorListener.
+type CollectorOption func(c *collectorConfig)
+
+// Sets the http client used to post snapshots. Defaults to a client with a timeout of 10s.
+func CollectorClient(client *http.Client) CollectorOption {
+	return func(c *collectorConfig) {
+		c.client = client
+	}
+}
+
+// Sets the service name reported in the snapshots.
+func CollectorService(name string) CollectorOption {
+	return func(c *collectorConfig) {
+		c.service = name
+	}
+}
+
+// Sets the instance reported in the snapshots. Defaults to the hostname.
+func CollectorInstance(instance string) CollectorOption {
+	return func(c *collectorConfig) {
+		c.instance = instance
+	}
+}
+
+// Adds a header to each request, e.g. for authentication.
+func CollectorHeader(key, value string) CollectorOption {
+	return func(c *collectorConfig) {
+		c.header.Add(key, value)
+	}
+}
+
+// Sets how often a failed post is retried and the backoff before the first retry, which is doubled on each further retry.
+// Defaults to 3 retries with a backoff of 1s.
+func CollectorRetries(retries int, backoff time.Duration) CollectorOption {
+	return func(c *collectorConfig) {
+		c.retries = retries
+		c.backoff = backoff
+	}
+}
+
+type collectorSnapshot struct {
+	Service   string        `json:"service,omitempty"`
+	Instance  string        `json:"instance"`
+	Timestamp time.Time     `json:"timestamp"`
+	Ready     bool          `json:"ready"`
+	Status    Status        `json:"status"`
+	Probes    []ProbeStatus `json:"probes"`
+}
+
+// Posts a JSON snapshot of each evaluation of the readiness probes to a central collector, for fleets where
+// scraping each instance is not feasible. Failed posts are retried on network errors and 5xx or 429 responses.
+// Snapshots are sent asynchronously and dropped while a previous snapshot is still being sent.
+//
+// Example:
+//		checker.AddListener(health.CollectorListener("https://health.example.com/v1/snapshots",
+//			health.CollectorService("billing"),
+//			health.CollectorHeader("Authorization", "Bearer "+token),
+//		))
+//		defer checker.EvaluateInBackground(30 * time.Second)()
+func CollectorListener(url string, opts ...CollectorOption) Listener {
+	cfg := &collectorConfig{
+		client:  &http.Client{Timeout: defaultCollectorTimeout},
+		header:  http.Header{},
+		retries: defaultCollectorRetries,
+		backoff: defaultCollectorBackoff,
+	}
+	cfg.instance, _ = os.Hostname()
+
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	sending := make(chan struct{}, 1)
+
+	return func(r Result) {
+		snapshot := collectorSnapshot{
+			Service:   cfg.service,
+			Instance:  cfg.instance,
+			Timestamp: time.Now(),
+			Ready:     r.Ready,
+			Status:    r.Status,
+			Probes:    newProbeStatuses(r.Probes),
+		}
+
+		select {
+		case sending <- struct{}{}:
+		default:
+			r.log().Printf("dropped health snapshot, as the previous one is still being sent")
+			return
+		}
+
+		go func() {
+			defer func() { <-sending }()
+
+			if err := cfg.post(url, &snapshot); err != nil {
+				r.log().Printf("failed to send health snapshot: %v", err)
+			}
+		}()
+	}
+}
+
+// Posts the snapshot and retries on temporary failures.
+func (c *collectorConfig) post(url string, snapshot *collectorSnapshot) error {
+	body, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+
+	backoff := c.backoff
+	for attempt := 0; ; attempt++ {
+		retry, err := c.postOnce(url, body)
+		if err == nil || !retry || attempt >= c.retries {
+			return err
+		}
+
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// Posts the body once and returns whether a failure is worth a retry.
+func (c *collectorConfig) postOnce(url string, body []byte) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+
+	for key, values := range c.header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+
+	defer resp.Body.Close()
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+
+	if resp.StatusCode >= 300 {
+		retry := resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+		return retry, fmt.Errorf("unexpected status code %d from %v", resp.StatusCode, url)
+	}
+
+	return false, nil
+}
@@ -0,0 +1,70 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCollectorListener(t *testing.T) {
+	attempts := 0
+	snapshots := make(chan collectorSnapshot, 1)
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+
+		var snapshot collectorSnapshot
+		_ = json.NewDecoder(r.Body).Decode(&snapshot)
+		snapshots <- snapshot
+	}))
+	defer s.Close()
+
+	checker := &Checker{}
+	checker.AddReadinessProbe("redis", func() error {
+		return fmt.Errorf("connection refused")
+	})
+	checker.AddListener(CollectorListener(s.URL,
+		CollectorService("billing"),
+		CollectorInstance("billing-0"),
+		CollectorHeader("Authorization", "Bearer secret"),
+		CollectorRetries(1, time.Millisecond),
+	))
+
+	checker.evaluateReadiness(context.Background(), true)
+
+	select {
+	case snapshot := <-snapshots:
+		assert.Equal(t, "billing", snapshot.Service)
+		assert.Equal(t, "billing-0", snapshot.Instance)
+		assert.False(t, snapshot.Ready)
+		assert.Len(t, snapshot.Probes, 1)
+		assert.Equal(t, "connection refused", snapshot.Probes[0].Error)
+	case <-time.After(time.Second):
+		t.Fatal("no snapshot received")
+	}
+}
+
+func TestCollectorConfig_post_err_noRetryOnClientError(t *testing.T) {
+	attempts := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer s.Close()
+
+	cfg := &collectorConfig{client: http.DefaultClient, header: http.Header{}, retries: 3}
+
+	assert.Error(t, cfg.post(s.URL, &collectorSnapshot{}))
+	assert.Equal(t, 1, attempts)
+}
@@ -0,0 +1,446 @@
+package health
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	probeKindReadiness = "readiness"
+	probeKindLiveness  = "liveness"
+)
+
+// Config describes the endpoints and probes of a Checker. Use LoadConfig to read it from a YAML or JSON file.
+//
+// Example:
+//		alivePath: /healthz
+//		readyPath: /readyz
+//		metadata:
+//		  zone: eu-central-1a
+//		probes:
+//		  - name: cache
+//		    uri: redis://redis:6379/0
+//		    timeout: 1s
+//		    critical: false
+//		  - name: spool
+//		    type: disk
+//		    target: /var/spool/app
+//		    params:
+//		      min_free_percent: 5
+//		  - name: goroutines
+//		    kind: liveness
+//		    uri: goroutines://?max=10000
+type Config struct {
+	// Path of the liveness endpoint. Defaults to `/.well-known/alive`.
+	AlivePath string `json:"alivePath" yaml:"alivePath"`
+	// Path of the readiness endpoint. Defaults to `/.well-known/ready`.
+	ReadyPath string `json:"readyPath" yaml:"readyPath"`
+	// Path of the history endpoint. Defaults to `/.well-known/history`.
+	HistoryPath string `json:"historyPath" yaml:"historyPath"`
+	// Path of the dependencies endpoint. Defaults to `/.well-known/dependencies`.
+	DependenciesPath string `json:"dependenciesPath" yaml:"dependenciesPath"`
+	// Path of the stats endpoint. Defaults to `/.well-known/stats`.
+	StatsPath string `json:"statsPath" yaml:"statsPath"`
+	// Number of results kept per probe for the history. Defaults to 10.
+	HistorySize int `json:"historySize" yaml:"historySize"`
+	// Static information about the instance included in each readiness response
+	Metadata map[string]string `json:"metadata" yaml:"metadata"`
+	// Compresses responses with gzip if the client accepts it
+	Compress bool `json:"compress" yaml:"compress"`
+	// Serves HTTP/2 over cleartext besides HTTP/1.1
+	H2C bool `json:"h2c" yaml:"h2c"`
+	// Serves the handlers of net/http/pprof at `/debug/pprof/`
+	Pprof bool `json:"pprof" yaml:"pprof"`
+	// Allows injecting faults into probes by the admin API, see Checker.Chaos. Never enable it in production.
+	Chaos bool `json:"chaos" yaml:"chaos"`
+	// Serves an OpenAPI document describing the health endpoints at `/.well-known/openapi.json`
+	OpenAPI bool `json:"openAPI" yaml:"openAPI"`
+	// Responds with `OK` or `UNAVAILABLE` as plain text instead of JSON, see TextEncoder
+	PlainText bool `json:"plainText" yaml:"plainText"`
+	// Allows cross-origin requests to the health endpoints
+	CORS *CORSConfig `json:"cors" yaml:"cors"`
+	// Logs each request to the health endpoints
+	AccessLog bool `json:"accessLog" yaml:"accessLog"`
+	// Time after the start in which failing probes report the status starting as duration string, e.g. `1m`
+	GracePeriod string `json:"gracePeriod" yaml:"gracePeriod"`
+	// Reports the service as ready while starting
+	ReadyWhileStarting bool `json:"readyWhileStarting" yaml:"readyWhileStarting"`
+	// Time within the health endpoints respond as duration string, e.g. `1s`, see Checker.ProbeBudget
+	ProbeBudget string `json:"probeBudget" yaml:"probeBudget"`
+	// Lets callers announce a shorter budget by the `X-Probe-Timeout` header, see Checker.TrustProbeTimeoutHeader
+	TrustProbeTimeoutHeader bool `json:"trustProbeTimeoutHeader" yaml:"trustProbeTimeoutHeader"`
+	// Time the result of a probe is reused for other requests as duration string, e.g. `1s`, see
+	// Checker.ProbeDedupWindow
+	ProbeDedupWindow string `json:"probeDedupWindow" yaml:"probeDedupWindow"`
+	// Stops waiting for the remaining probes as soon as a critical probe fails, see Checker.FailFast
+	FailFast bool `json:"failFast" yaml:"failFast"`
+	// Sibling instances queried to report how many of them are ready
+	Peers *PeerConfig `json:"peers" yaml:"peers"`
+	// Regular expressions matching secrets removed from the errors of probes, see Checker.RedactPatterns
+	Redact []string `json:"redact" yaml:"redact"`
+	// Probes to register
+	Probes []ProbeConfig `json:"probes" yaml:"probes"`
+}
+
+// ProbeConfig describes a single probe. The probe is created by ProbeFromURI, thus all registered schemes are supported.
+type ProbeConfig struct {
+	// Unique name of the probe
+	Name string `json:"name" yaml:"name"`
+	// Either `readiness` (default) or `liveness`
+	Kind string `json:"kind" yaml:"kind"`
+	// URI of the probe, e.g. `redis://redis:6379`. Alternatively use Type and Target.
+	URI string `json:"uri" yaml:"uri"`
+	// Scheme of the probe, e.g. `redis`. Used with Target if no URI is given.
+	Type string `json:"type" yaml:"type"`
+	// Target of the probe, e.g. `redis:6379`. Used with Type if no URI is given.
+	Target string `json:"target" yaml:"target"`
+	// Timeout of the probe as duration string, e.g. `2s`. By default the probe is not timed out.
+	Timeout string `json:"timeout" yaml:"timeout"`
+	// Whether a failing probe affects the state of the service. Defaults to true.
+	Critical *bool `json:"critical" yaml:"critical"`
+	// Interval of the probe while evaluating in background as duration string, e.g. `5m`. See Interval.
+	Interval string `json:"interval" yaml:"interval"`
+	// Delay of the first run of a probe with an interval as duration string, e.g. `30s`. See InitialDelay.
+	InitialDelay string `json:"initialDelay" yaml:"initialDelay"`
+	// Thresholds and other parameters of the probe, added as query parameters to the URI.
+	Params map[string]interface{} `json:"params" yaml:"params"`
+	// Labels attached to the probe, e.g. `team: billing`. See Labels.
+	Labels map[string]string `json:"labels" yaml:"labels"`
+	// Planned downtimes of the dependency, during which the probe is non-critical or skipped. See Maintenance.
+	Maintenance []MaintenanceConfig `json:"maintenance" yaml:"maintenance"`
+}
+
+// MaintenanceConfig describes a maintenance window of a probe in a Config. Either Start and End or At and Duration
+// are required.
+type MaintenanceConfig struct {
+	// Start and end of a single window in RFC 3339, e.g. `2024-03-01T22:00:00Z`
+	Start string `json:"start" yaml:"start"`
+	End   string `json:"end" yaml:"end"`
+	// Time of day a recurring window starts at, e.g. `02:00`
+	At string `json:"at" yaml:"at"`
+	// Length of a recurring window as duration string, e.g. `1h`
+	Duration string `json:"duration" yaml:"duration"`
+	// Weekdays of a recurring window, e.g. `sunday`. Defaults to every day.
+	Days []string `json:"days" yaml:"days"`
+	// Time zone of a recurring window, e.g. `Europe/Berlin`. Defaults to the local time zone.
+	TimeZone string `json:"timeZone" yaml:"timeZone"`
+	// Skips the probe during the window instead of treating it as non-critical
+	Skip bool `json:"skip" yaml:"skip"`
+}
+
+// Reads a Config from a YAML or JSON file. The format is chosen by the file extension and defaults to YAML.
+func LoadConfig(path string) (*Config, error) {
+	// #nosec G304
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read config: %w", err)
+	}
+
+	cfg := &Config{}
+	if strings.ToLower(filepath.Ext(path)) == ".json" {
+		err = json.Unmarshal(b, cfg)
+	} else {
+		err = yaml.Unmarshal(b, cfg)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("could not parse config %v: %w", path, err)
+	}
+
+	return cfg, nil
+}
+
+// Creates a Checker with the endpoints and probes described by cfg. If a probe is invalid, the connections opened by
+// the probes built before it are closed.
+//
+// Example:
+//		cfg, err := health.LoadConfig("/etc/app/health.yaml")
+//		if err != nil {
+//			log.Fatal(err)
+//		}
+//
+//		checker, err := health.NewCheckerFromConfig(cfg)
+//		if err != nil {
+//			log.Fatal(err)
+//		}
+//
+//		stop, err := checker.StartHTTP(":8080")
+//		if err != nil {
+//			log.Fatal(err)
+//		}
+//		defer stop()
+func NewCheckerFromConfig(cfg *Config) (*Checker, error) {
+	h := &Checker{
+		AlivePath:   cfg.AlivePath,
+		ReadyPath:   cfg.ReadyPath,
+		HistoryPath: cfg.HistoryPath,
+		HistorySize: cfg.HistorySize,
+		Metadata:    cfg.Metadata,
+		Compress:    cfg.Compress,
+		CORS:        cfg.CORS,
+		AccessLog:   cfg.AccessLog,
+		H2C:         cfg.H2C,
+		Pprof:       cfg.Pprof,
+		Chaos:       cfg.Chaos,
+		OpenAPI:     cfg.OpenAPI,
+		FailFast:    cfg.FailFast,
+		Peers:       cfg.Peers,
+		StatsPath:   cfg.StatsPath,
+
+		DependenciesPath:        cfg.DependenciesPath,
+		ReadyWhileStarting:      cfg.ReadyWhileStarting,
+		TrustProbeTimeoutHeader: cfg.TrustProbeTimeoutHeader,
+	}
+
+	if cfg.PlainText {
+		h.Encoder = TextEncoder{}
+	}
+
+	if cfg.GracePeriod != "" {
+		gracePeriod, err := time.ParseDuration(cfg.GracePeriod)
+		if err != nil {
+			return nil, fmt.Errorf("invalid grace period: %w", err)
+		}
+
+		h.GracePeriod = gracePeriod
+	}
+
+	if cfg.ProbeBudget != "" {
+		budget, err := time.ParseDuration(cfg.ProbeBudget)
+		if err != nil {
+			return nil, fmt.Errorf("invalid probe budget: %w", err)
+		}
+
+		h.ProbeBudget = budget
+	}
+
+	if cfg.ProbeDedupWindow != "" {
+		window, err := time.ParseDuration(cfg.ProbeDedupWindow)
+		if err != nil {
+			return nil, fmt.Errorf("invalid probe dedup window: %w", err)
+		}
+
+		h.ProbeDedupWindow = window
+	}
+
+	for _, pattern := range cfg.Redact {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redact pattern: %w", err)
+		}
+
+		h.RedactPatterns = append(h.RedactPatterns, re)
+	}
+
+	var closers []io.Closer
+	for _, pc := range cfg.Probes {
+		closer, err := pc.register(h)
+		if err != nil {
+			// Release the connections of the probes built so far, as the checker is discarded
+			for _, c := range closers {
+				_ = c.Close()
+			}
+
+			return nil, fmt.Errorf("invalid probe %q: %w", pc.Name, err)
+		}
+
+		if closer != nil {
+			closers = append(closers, closer)
+		}
+	}
+
+	return h, nil
+}
+
+func (pc *ProbeConfig) register(h *Checker) (io.Closer, error) {
+	if pc.Name == "" {
+		return nil, fmt.Errorf("name is missing")
+	}
+
+	// Checked before building the probe, which may open connections
+	probes := h.readinessProbes
+	add := h.AddReadinessProbe
+	switch pc.Kind {
+	case "", probeKindReadiness:
+	case probeKindLiveness:
+		probes = h.livenessProbes
+		add = h.AddLivenessProbe
+	default:
+		return nil, fmt.Errorf("unknown kind %q", pc.Kind)
+	}
+
+	if _, exists := probes[pc.Name]; exists {
+		return nil, fmt.Errorf("name is not unique")
+	}
+
+	if err := checkMetricLabels(pc.Labels); err != nil {
+		return nil, err
+	}
+
+	probe, closer, opts, err := pc.build()
+	if err != nil {
+		return nil, err
+	}
+
+	add(pc.Name, probe, opts...)
+
+	return closer, nil
+}
+
+// Creates the probe and its options. Also returns a closer releasing the connections opened for the probe, nil if
+// there are none.
+func (pc *ProbeConfig) build() (Probe, io.Closer, []ProbeOption, error) {
+	uri := pc.URI
+	if uri == "" {
+		if pc.Type == "" {
+			return nil, nil, nil, fmt.Errorf("either uri or type is required")
+		}
+
+		uri = pc.Type + "://" + pc.Target
+	}
+
+	if len(pc.Params) > 0 {
+		u, err := url.Parse(uri)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("invalid uri: %w", err)
+		}
+
+		q := u.Query()
+		for key, value := range pc.Params {
+			// Numbers decoded from JSON are floats, which fmt formats with an exponent if large, e.g. byte sizes
+			if f, isFloat := value.(float64); isFloat {
+				q.Set(key, strconv.FormatFloat(f, 'f', -1, 64))
+				continue
+			}
+
+			q.Set(key, fmt.Sprint(value))
+		}
+
+		u.RawQuery = q.Encode()
+		uri = u.String()
+	}
+
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("invalid uri: %w", err)
+	}
+
+	opts := []ProbeOption{DependencyOf(u.Scheme, uri)}
+	if pc.Timeout != "" {
+		timeout, err := time.ParseDuration(pc.Timeout)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("invalid timeout: %w", err)
+		}
+
+		opts = append(opts, Timeout(timeout))
+	}
+
+	if pc.Critical != nil && !*pc.Critical {
+		opts = append(opts, NonCritical())
+	}
+
+	if pc.Interval != "" {
+		interval, err := time.ParseDuration(pc.Interval)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("invalid interval: %w", err)
+		}
+
+		opts = append(opts, Interval(interval))
+	}
+
+	if pc.InitialDelay != "" {
+		delay, err := time.ParseDuration(pc.InitialDelay)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("invalid initial delay: %w", err)
+		}
+
+		opts = append(opts, InitialDelay(delay))
+	}
+
+	if len(pc.Labels) > 0 {
+		opts = append(opts, Labels(pc.Labels))
+	}
+
+	for _, mc := range pc.Maintenance {
+		opt, err := mc.build()
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("invalid maintenance window: %w", err)
+		}
+
+		opts = append(opts, opt)
+	}
+
+	// Created last, as it may open connections
+	probe, closer, err := probeFromURI(uri)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return probe, closer, opts, nil
+}
+
+// Creates the probe option of the window.
+func (mc *MaintenanceConfig) build() (ProbeOption, error) {
+	if mc.Start != "" || mc.End != "" {
+		start, err := time.Parse(time.RFC3339, mc.Start)
+		if err != nil {
+			return nil, fmt.Errorf("invalid start: %w", err)
+		}
+
+		end, err := time.Parse(time.RFC3339, mc.End)
+		if err != nil {
+			return nil, fmt.Errorf("invalid end: %w", err)
+		}
+
+		return Maintenance(Between(start, end), mc.Skip), nil
+	}
+
+	d, err := time.ParseDuration(mc.Duration)
+	if err != nil {
+		return nil, fmt.Errorf("invalid duration: %w", err)
+	}
+
+	loc := time.Local
+	if mc.TimeZone != "" {
+		if loc, err = time.LoadLocation(mc.TimeZone); err != nil {
+			return nil, fmt.Errorf("invalid time zone: %w", err)
+		}
+	}
+
+	var days []time.Weekday
+	for _, name := range mc.Days {
+		day, ok := weekdays[strings.ToLower(name)]
+		if !ok {
+			return nil, fmt.Errorf("invalid day %q", name)
+		}
+
+		days = append(days, day)
+	}
+
+	window, err := Recurring(mc.At, d, loc, days...)
+	if err != nil {
+		return nil, err
+	}
+
+	return Maintenance(window, mc.Skip), nil
+}
+
+var weekdays = map[string]time.Weekday{
+	"sunday":    time.Sunday,
+	"monday":    time.Monday,
+	"tuesday":   time.Tuesday,
+	"wednesday": time.Wednesday,
+	"thursday":  time.Thursday,
+	"friday":    time.Friday,
+	"saturday":  time.Saturday,
+}
@@ -0,0 +1,156 @@
+package health
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeTestConfig(t *testing.T, pattern, content string) string {
+	f, err := ioutil.TempFile("", pattern)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	_, _ = f.WriteString(content)
+
+	return f.Name()
+}
+
+func TestLoadConfig_yaml(t *testing.T) {
+	path := writeTestConfig(t, "health-*.yaml", `
+alivePath: /healthz
+readyPath: /readyz
+probes:
+  - name: cache
+    uri: redis://redis:6379/0
+    timeout: 1s
+    critical: false
+  - name: spool
+    type: disk
+    target: /var/spool/app
+    params:
+      min_free_percent: 5
+`)
+	defer os.Remove(path)
+
+	cfg, err := LoadConfig(path)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "/healthz", cfg.AlivePath)
+	assert.Equal(t, "/readyz", cfg.ReadyPath)
+	assert.Len(t, cfg.Probes, 2)
+	assert.Equal(t, "1s", cfg.Probes[0].Timeout)
+	assert.False(t, *cfg.Probes[0].Critical)
+	assert.EqualValues(t, 5, cfg.Probes[1].Params["min_free_percent"])
+}
+
+func TestLoadConfig_json(t *testing.T) {
+	path := writeTestConfig(t, "health-*.json", `{"readyPath": "/readyz", "probes": [{"name": "api", "uri": "http://api/.well-known/alive"}]}`)
+	defer os.Remove(path)
+
+	cfg, err := LoadConfig(path)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "/readyz", cfg.ReadyPath)
+	assert.Equal(t, "http://api/.well-known/alive", cfg.Probes[0].URI)
+}
+
+func TestNewCheckerFromConfig_err_closesBuiltProbes(t *testing.T) {
+	scheme := fmt.Sprintf("closing-test-%d", atomic.AddUint64(&customSchemes, 1))
+	closed := 0
+	registerClosingScheme(scheme, func(u *url.URL) (Probe, io.Closer, error) {
+		return func() error { return nil }, closerFunc(func() error { closed++; return nil }), nil
+	})
+
+	_, err := NewCheckerFromConfig(&Config{Probes: []ProbeConfig{
+		{Name: "a", URI: scheme + "://a"},
+		{Name: "b", URI: "unknown://b"},
+	}})
+
+	assert.Error(t, err)
+	assert.Equal(t, 1, closed)
+}
+
+func TestNewCheckerFromConfig_largeParams(t *testing.T) {
+	var cfg Config
+	assert.NoError(t, json.Unmarshal([]byte(`{"probes": [{"name": "memory", "type": "memory", "params": {"max_rss": 4000000000}}]}`), &cfg))
+
+	_, err := NewCheckerFromConfig(&cfg)
+
+	assert.NoError(t, err, "not formatted as 4e+09")
+}
+
+func TestLoadConfig_err_invalid(t *testing.T) {
+	path := writeTestConfig(t, "health-*.json", `{"probes": {}}`)
+	defer os.Remove(path)
+
+	_, err := LoadConfig(path)
+
+	assert.Error(t, err)
+}
+
+func TestNewCheckerFromConfig(t *testing.T) {
+	l, _ := net.Listen("tcp", "127.0.0.1:0")
+	defer l.Close()
+
+	critical := false
+	cfg := &Config{
+		ReadyPath: "/readyz",
+		Probes: []ProbeConfig{
+			{Name: "backend", Type: "tcp", Target: l.Addr().String(), Timeout: "1s"},
+			{Name: "unreachable", URI: "tcp://127.0.0.1:1", Critical: &critical},
+			{Name: "goroutines", Kind: "liveness", URI: "goroutines://", Params: map[string]interface{}{"max": 1}},
+		},
+	}
+
+	checker, err := NewCheckerFromConfig(cfg)
+	assert.NoError(t, err)
+
+	server := httptest.NewServer(checker.serverMux())
+	defer server.Close()
+
+	resp, err := http.Get(fmt.Sprintf("%v/readyz", server.URL))
+	assert.NoError(t, err)
+	assert.EqualValues(t, http.StatusOK, resp.StatusCode)
+	body, _ := ioutil.ReadAll(resp.Body)
+	assert.Contains(t, string(body), "unreachable")
+
+	resp, err = http.Get(fmt.Sprintf("%v/.well-known/alive", server.URL))
+	assert.NoError(t, err)
+	assert.EqualValues(t, http.StatusServiceUnavailable, resp.StatusCode)
+}
+
+func TestNewCheckerFromConfig_err(t *testing.T) {
+	configs := map[string]*Config{
+		"missing name":     {Probes: []ProbeConfig{{URI: "tcp://localhost:80"}}},
+		"missing uri":      {Probes: []ProbeConfig{{Name: "a"}}},
+		"unknown kind":     {Probes: []ProbeConfig{{Name: "a", URI: "tcp://localhost:80", Kind: "startup"}}},
+		"invalid timeout":  {Probes: []ProbeConfig{{Name: "a", URI: "tcp://localhost:80", Timeout: "soon"}}},
+		"invalid interval": {Probes: []ProbeConfig{{Name: "a", URI: "tcp://localhost:80", Interval: "daily"}}},
+		"invalid delay":    {Probes: []ProbeConfig{{Name: "a", URI: "tcp://localhost:80", Interval: "1m", InitialDelay: "later"}}},
+		"invalid grace":    {GracePeriod: "a while"},
+		"duplicate name":   {Probes: []ProbeConfig{{Name: "a", URI: "tcp://localhost:80"}, {Name: "a", URI: "tcp://localhost:81"}}},
+		"colliding labels": {Probes: []ProbeConfig{{Name: "a", URI: "tcp://localhost:80", Labels: map[string]string{"a-b": "1", "a_b": "2"}}}},
+	}
+
+	for name, cfg := range configs {
+		_, err := NewCheckerFromConfig(cfg)
+		assert.Error(t, err, name)
+	}
+
+	// The name is checked before the probe is built
+	_, err := NewCheckerFromConfig(&Config{Probes: []ProbeConfig{{Name: "a", URI: "tcp://localhost:80"}, {Name: "a", URI: "unknown://a"}}})
+	assert.EqualError(t, err, `invalid probe "a": name is not unique`)
+}
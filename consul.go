@@ -0,0 +1,210 @@
+package health
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultConsulAgent   = "http://127.0.0.1:8500"
+	defaultConsulTTL     = 30 * time.Second
+	defaultConsulTimeout = 10 * time.Second
+)
+
+// ConsulService describes the service registered at the Consul agent.
+type ConsulService struct {
+	// Unique id of the instance. Defaults to Name.
+	ID string
+	// Name of the service
+	Name string
+	// Address and port the service is reachable at
+	Address string
+	Port    int
+	Tags    []string
+	// Time the check stays passing without an update. Must be longer than the evaluation interval. Defaults to 30s.
+	TTL time.Duration
+	// Consul removes the service if the check is critical for longer. Disabled by default.
+	DeregisterCriticalServiceAfter time.Duration
+}
+
+type consulConfig struct {
+	agent  string
+	token  string
+	client *http.Client
+}
+
+// A ConsulOption configures the Consul integration.
+type ConsulOption func(c *consulConfig)
+
+// Sets the base URL of the Consul agent. Defaults to `http://127.0.0.1:8500`.
+func ConsulAgent(agent string) ConsulOption {
+	return func(c *consulConfig) {
+		c.agent = strings.TrimSuffix(agent, "/")
+	}
+}
+
+// Sets the ACL token sent to the Consul agent.
+func ConsulToken(token string) ConsulOption {
+	return func(c *consulConfig) {
+		c.token = token
+	}
+}
+
+// Sets the http client used to call the Consul agent. Defaults to a client with a timeout of 10s.
+func ConsulClient(client *http.Client) ConsulOption {
+	return func(c *consulConfig) {
+		c.client = client
+	}
+}
+
+// Registers the service with a TTL check at the local Consul agent. The returned listener marks the check as passing,
+// warning or critical after each evaluation of the readiness probes, so Consul based service discovery follows the
+// readiness of the service. The check is critical whenever the readiness endpoint responds with 503. If the process dies the TTL expires and the check turns critical.
+// Also returns a function to deregister the service on shutdown.
+//
+// Example:
+//		listener, deregister, err := health.ConsulTTLCheck(health.ConsulService{Name: "billing", Port: 8080})
+//		if err != nil {
+//			log.Fatal(err)
+//		}
+//		defer deregister()
+//
+//		checker.AddListener(listener)
+//		defer checker.EvaluateInBackground(10 * time.Second)()
+func ConsulTTLCheck(service ConsulService, opts ...ConsulOption) (Listener, func() error, error) {
+	cfg := &consulConfig{
+		agent:  defaultConsulAgent,
+		client: &http.Client{Timeout: defaultConsulTimeout},
+	}
+
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	if service.Name == "" {
+		return nil, nil, fmt.Errorf("service name is required")
+	}
+
+	if service.ID == "" {
+		service.ID = service.Name
+	}
+
+	if service.TTL <= 0 {
+		service.TTL = defaultConsulTTL
+	}
+
+	checkID := "service:" + service.ID
+	registration := map[string]interface{}{
+		"ID":      service.ID,
+		"Name":    service.Name,
+		"Address": service.Address,
+		"Port":    service.Port,
+		"Tags":    service.Tags,
+		"Check": map[string]interface{}{
+			"CheckID": checkID,
+			"Name":    service.Name + " readiness",
+			"TTL":     service.TTL.String(),
+		},
+	}
+
+	if service.DeregisterCriticalServiceAfter > 0 {
+		registration["Check"].(map[string]interface{})["DeregisterCriticalServiceAfter"] = service.DeregisterCriticalServiceAfter.String()
+	}
+
+	if err := cfg.put("/v1/agent/service/register", registration); err != nil {
+		return nil, nil, fmt.Errorf("could not register service at consul: %w", err)
+	}
+
+	// Updates are sent in order, only the latest one is kept while the agent is slow
+	queue := &orderedQueue{}
+	mu := sync.Mutex{}
+	var latest map[string]string
+	pending := false
+
+	listener := func(r Result) {
+		update := map[string]string{
+			"Status": "passing",
+			"Output": "ready",
+		}
+
+		switch {
+		case !r.Ready:
+			update["Status"] = "critical"
+			update["Output"] = strings.Join(reasonStrings(r.Reasons), "\n")
+		case r.Status == StatusWarn, r.Status == StatusStarting:
+			// Failures within the grace period only turn the check critical unless ReadyWhileStarting is set
+			update["Status"] = "warning"
+			update["Output"] = strings.Join(reasonStrings(r.Reasons), "\n")
+		}
+
+		mu.Lock()
+		latest = update
+		queued := pending
+		pending = true
+		mu.Unlock()
+
+		if queued {
+			return
+		}
+
+		logger := r.log()
+		queue.add(func() {
+			mu.Lock()
+			update := latest
+			pending = false
+			mu.Unlock()
+
+			if err := cfg.put("/v1/agent/check/update/"+url.PathEscape(checkID), update); err != nil {
+				logger.Printf("failed to update consul check: %v", err)
+			}
+		})
+	}
+
+	deregister := func() error {
+		return cfg.put("/v1/agent/service/deregister/"+url.PathEscape(service.ID), nil)
+	}
+
+	return listener, deregister, nil
+}
+
+// Sends a PUT request with body encoded as json to the Consul agent.
+func (c *consulConfig) put(path string, body interface{}) error {
+	var b []byte
+	if body != nil {
+		var err error
+		if b, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+
+	req, err := http.NewRequest(http.MethodPut, c.agent+path, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+
+	if c.token != "" {
+		req.Header.Set("X-Consul-Token", c.token)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, bytes.TrimSpace(msg))
+	}
+
+	return nil
+}
@@ -0,0 +1,122 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type consulRequest struct {
+	path string
+	body map[string]interface{}
+}
+
+func newConsulTestAgent(status int) (*httptest.Server, chan consulRequest) {
+	requests := make(chan consulRequest, 10)
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := map[string]interface{}{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		requests <- consulRequest{path: r.URL.Path, body: body}
+		w.WriteHeader(status)
+	}))
+
+	return s, requests
+}
+
+func TestConsulTTLCheck(t *testing.T) {
+	s, requests := newConsulTestAgent(http.StatusOK)
+	defer s.Close()
+
+	listener, deregister, err := ConsulTTLCheck(ConsulService{Name: "billing", Port: 8080, TTL: time.Minute}, ConsulAgent(s.URL))
+	assert.NoError(t, err)
+
+	registration := <-requests
+	assert.Equal(t, "/v1/agent/service/register", registration.path)
+	assert.Equal(t, "billing", registration.body["ID"])
+	assert.Equal(t, "1m0s", registration.body["Check"].(map[string]interface{})["TTL"])
+
+	checker := &Checker{}
+	checker.AddReadinessProbe("redis", func() error {
+		return fmt.Errorf("connection refused")
+	})
+	checker.AddListener(listener)
+	checker.evaluateReadiness(context.Background(), true)
+
+	update := <-requests
+	assert.Equal(t, "/v1/agent/check/update/service:billing", update.path)
+	assert.Equal(t, "critical", update.body["Status"])
+	assert.Equal(t, "redis: connection refused", update.body["Output"])
+
+	assert.NoError(t, deregister())
+	assert.Equal(t, "/v1/agent/service/deregister/billing", (<-requests).path)
+}
+
+func TestConsulTTLCheck_starting(t *testing.T) {
+	s, requests := newConsulTestAgent(http.StatusOK)
+	defer s.Close()
+
+	listener, _, err := ConsulTTLCheck(ConsulService{Name: "billing", Port: 8080}, ConsulAgent(s.URL))
+	assert.NoError(t, err)
+	<-requests
+
+	for readyWhileStarting, status := range map[bool]string{true: "warning", false: "critical"} {
+		checker := &Checker{GracePeriod: time.Hour, ReadyWhileStarting: readyWhileStarting}
+		checker.AddReadinessProbe("redis", func() error {
+			return fmt.Errorf("connection refused")
+		})
+		checker.AddListener(listener)
+
+		r := checker.evaluateReadiness(context.Background(), true)
+		assert.Equal(t, StatusStarting, r.Status)
+		assert.Equal(t, status, (<-requests).body["Status"], "ReadyWhileStarting: %v", readyWhileStarting)
+	}
+}
+
+func TestConsulTTLCheck_err_registrationFailed(t *testing.T) {
+	s, _ := newConsulTestAgent(http.StatusForbidden)
+	defer s.Close()
+
+	_, _, err := ConsulTTLCheck(ConsulService{Name: "billing"}, ConsulAgent(s.URL))
+
+	assert.Error(t, err)
+}
+
+func TestConsulTTLCheck_ordered(t *testing.T) {
+	release := make(chan struct{})
+	statuses := make(chan interface{}, 10)
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/agent/service/register" {
+			return
+		}
+
+		body := map[string]interface{}{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		statuses <- body["Status"]
+		<-release
+	}))
+	defer s.Close()
+
+	listener, _, err := ConsulTTLCheck(ConsulService{Name: "billing"}, ConsulAgent(s.URL))
+	assert.NoError(t, err)
+
+	listener(Result{Status: StatusFail})
+	assert.Equal(t, "critical", <-statuses)
+
+	// Sent after the first update finished, only the latest one is kept
+	listener(Result{Ready: true, Status: StatusWarn})
+	listener(Result{Ready: true, Status: StatusPass})
+	close(release)
+
+	assert.Equal(t, "passing", <-statuses)
+	select {
+	case status := <-statuses:
+		t.Fatalf("unexpected update: %v", status)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
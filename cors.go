@@ -0,0 +1,64 @@
+package health
+
+import (
+	"net/http"
+	"strings"
+)
+
+// CORSConfig allows browsers to query the health endpoints from other origins, e.g. from a status dashboard.
+type CORSConfig struct {
+	// Origins allowed to query the endpoints, e.g. `https://status.example.com`. Use `*` to allow all origins.
+	AllowedOrigins []string `json:"allowedOrigins" yaml:"allowedOrigins"`
+	// Methods allowed for cross-origin requests. Defaults to GET and HEAD.
+	AllowedMethods []string `json:"allowedMethods" yaml:"allowedMethods"`
+}
+
+// Adds CORS headers for allowed origins and answers preflight requests.
+func corsHandler(cfg *CORSConfig, next http.Handler) http.Handler {
+	methods := cfg.AllowedMethods
+	if len(methods) == 0 {
+		methods = []string{http.MethodGet, http.MethodHead}
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Add("Vary", "Origin")
+		allowed := cfg.allowedOrigin(origin)
+		if allowed != "" {
+			w.Header().Set("Access-Control-Allow-Origin", allowed)
+			w.Header().Set("Access-Control-Expose-Headers", "ETag")
+		}
+
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			if allowed != "" {
+				w.Header().Set("Access-Control-Allow-Methods", strings.Join(methods, ", "))
+				w.Header().Set("Access-Control-Allow-Headers", "If-None-Match")
+			}
+
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Returns the value of the Access-Control-Allow-Origin header for origin, or an empty string if it is not allowed.
+func (c *CORSConfig) allowedOrigin(origin string) string {
+	for _, allowed := range c.AllowedOrigins {
+		if allowed == "*" {
+			return "*"
+		}
+
+		if strings.EqualFold(allowed, origin) {
+			return origin
+		}
+	}
+
+	return ""
+}
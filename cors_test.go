@@ -0,0 +1,46 @@
+package health
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChecker_cors(t *testing.T) {
+	checker := &Checker{CORS: &CORSConfig{AllowedOrigins: []string{"https://status.example.com"}}}
+	server := httptest.NewServer(checker.serverMux())
+	defer server.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("%v/.well-known/ready", server.URL), nil)
+	req.Header.Set("Origin", "https://status.example.com")
+	resp, err := http.DefaultClient.Do(req)
+
+	assert.NoError(t, err)
+	assert.EqualValues(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "https://status.example.com", resp.Header.Get("Access-Control-Allow-Origin"))
+
+	req.Header.Set("Origin", "https://evil.example.com")
+	resp, err = http.DefaultClient.Do(req)
+
+	assert.NoError(t, err)
+	assert.Empty(t, resp.Header.Get("Access-Control-Allow-Origin"))
+}
+
+func TestChecker_cors_preflight(t *testing.T) {
+	checker := &Checker{CORS: &CORSConfig{AllowedOrigins: []string{"*"}}}
+	server := httptest.NewServer(checker.serverMux())
+	defer server.Close()
+
+	req, _ := http.NewRequest(http.MethodOptions, fmt.Sprintf("%v/.well-known/ready", server.URL), nil)
+	req.Header.Set("Origin", "https://status.example.com")
+	req.Header.Set("Access-Control-Request-Method", http.MethodGet)
+	resp, err := http.DefaultClient.Do(req)
+
+	assert.NoError(t, err)
+	assert.EqualValues(t, http.StatusNoContent, resp.StatusCode)
+	assert.Equal(t, "*", resp.Header.Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "GET, HEAD", resp.Header.Get("Access-Control-Allow-Methods"))
+}
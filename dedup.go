@@ -0,0 +1,54 @@
+package health
+
+import "time"
+
+// A run of a probe shared by all callers requesting the probe while it runs
+type probeFlight struct {
+	done       chan struct{}
+	err        error
+	finishedAt time.Time
+}
+
+// Runs the probe unless it is already running or finished within window, in which case the result of that run is
+// returned and shared is true. So the liveness and readiness endpoints, the per-probe endpoints, listeners and the
+// background evaluation requesting a probe at the same time touch the dependency once.
+func (p *registeredProbe) runShared(clock Clock, middlewares []ProbeMiddleware, window time.Duration) (err error, shared bool) {
+	p.flightMu.Lock()
+	if f := p.flight; f != nil {
+		select {
+		case <-f.done:
+			// Reuse the finished run within the window only
+			if window <= 0 || clock.Now().Sub(f.finishedAt) >= window {
+				break
+			}
+
+			p.shared++
+			p.flightMu.Unlock()
+			return f.err, true
+		default:
+			p.shared++
+			p.flightMu.Unlock()
+
+			<-f.done
+			return f.err, true
+		}
+	}
+
+	f := &probeFlight{done: make(chan struct{})}
+	p.flight = f
+	p.flightMu.Unlock()
+
+	f.err = p.run(clock, middlewares)
+	f.finishedAt = clock.Now()
+	close(f.done)
+
+	return f.err, false
+}
+
+// Returns the number of runs of the probe that were shared with another caller, see runShared.
+func (p *registeredProbe) sharedRuns() uint64 {
+	p.flightMu.Lock()
+	defer p.flightMu.Unlock()
+
+	return p.shared
+}
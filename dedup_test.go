@@ -0,0 +1,68 @@
+package health
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChecker_dedup(t *testing.T) {
+	var runs int32
+	release := make(chan struct{})
+	checker := &Checker{}
+	checker.AddReadinessProbe("database", func() error {
+		atomic.AddInt32(&runs, 1)
+		<-release
+		return nil
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.True(t, checker.readiness(context.Background(), nil).Ready)
+		}()
+	}
+
+	// Release the probe once all requests wait for it
+	probe := checker.readinessProbes["database"]
+	assert.Eventually(t, func() bool { return probe.sharedRuns() == 2 }, time.Second, time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.EqualValues(t, 1, atomic.LoadInt32(&runs))
+	assert.Len(t, checker.History().Readiness["database"], 1)
+
+	// Finished runs are not reused without a window
+	checker.readiness(context.Background(), nil)
+	assert.EqualValues(t, 2, atomic.LoadInt32(&runs))
+
+	rec := httptest.NewRecorder()
+	checker.MetricsHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Contains(t, rec.Body.String(), "health_probe_shared_runs_total{probe=\"database\"} 2\n")
+}
+
+func TestChecker_dedupWindow(t *testing.T) {
+	var runs int32
+	checker := &Checker{ProbeDedupWindow: time.Hour}
+	checker.AddReadinessProbe("database", func() error {
+		atomic.AddInt32(&runs, 1)
+		return nil
+	})
+
+	checker.readiness(context.Background(), nil)
+	checker.readiness(context.Background(), nil)
+	assert.EqualValues(t, 1, atomic.LoadInt32(&runs))
+
+	checker.ProbeDedupWindow = time.Nanosecond
+	time.Sleep(time.Millisecond)
+	checker.readiness(context.Background(), nil)
+	assert.EqualValues(t, 2, atomic.LoadInt32(&runs))
+}
@@ -0,0 +1,99 @@
+package health
+
+import (
+	"net/http"
+	"net/url"
+	"sort"
+)
+
+const defaultDependenciesPath = "/.well-known/dependencies"
+
+// Dependency describes a registered probe without running it, served by the dependencies endpoint.
+type Dependency struct {
+	Name string `json:"name"`
+	// Either "liveness" or "readiness"
+	Kind string `json:"kind"`
+	// Type of the dependency, e.g. `redis`, see DependencyOf
+	Type string `json:"type,omitempty"`
+	// Target of the probe with credentials and query parameters removed
+	Target   string            `json:"target,omitempty"`
+	Critical bool              `json:"critical"`
+	Timeout  string            `json:"timeout,omitempty"`
+	Labels   map[string]string `json:"labels,omitempty"`
+}
+
+// DependenciesResponse lists the dependencies of the service.
+type DependenciesResponse struct {
+	Dependencies []Dependency `json:"dependencies"`
+}
+
+// Describes the dependency checked by the probe, e.g. `DependencyOf("postgres", "postgres://db:5432/orders")`, so
+// platform tooling can inventory the dependencies of a service from the dependencies endpoint. Credentials and
+// query parameters of URL targets are never served. Probes created from a Config are described by their URI.
+func DependencyOf(typ, target string) ProbeOption {
+	return func(p *registeredProbe) {
+		p.dependencyType = typ
+		p.dependencyTarget = redactTarget(target)
+	}
+}
+
+// Removes the credentials, query parameters and fragment of URL targets, which may contain secrets.
+func redactTarget(target string) string {
+	u, err := url.Parse(target)
+	if err != nil || u.Scheme == "" || u.Opaque != "" {
+		return target
+	}
+
+	u.User = nil
+	u.RawQuery = ""
+	u.Fragment = ""
+
+	return u.String()
+}
+
+// Lists the registered probes as dependencies ordered by kind and name. Probes are not run.
+func (h *Checker) Dependencies() []Dependency {
+	dependencies := []Dependency{}
+	for _, kind := range []struct {
+		name   string
+		probes map[string]*registeredProbe
+	}{{"liveness", h.livenessProbes}, {"readiness", h.readinessProbes}} {
+		names := make([]string, 0, len(kind.probes))
+		for name := range kind.probes {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			probe := kind.probes[name]
+			settings := probe.settings()
+			dependency := Dependency{
+				Name:     name,
+				Kind:     kind.name,
+				Type:     probe.dependencyType,
+				Target:   probe.dependencyTarget,
+				Critical: settings.critical,
+				Labels:   copyLabels(probe.labels),
+			}
+			if settings.timeout > 0 {
+				dependency.Timeout = settings.timeout.String()
+			}
+
+			dependencies = append(dependencies, dependency)
+		}
+	}
+
+	return dependencies
+}
+
+func (h *Checker) dependenciesPath() string {
+	if h.DependenciesPath == "" {
+		return defaultDependenciesPath
+	}
+
+	return h.DependenciesPath
+}
+
+func (h *Checker) dependenciesHandler(w http.ResponseWriter, r *http.Request) {
+	h.writeResponse(w, r, true, &DependenciesResponse{Dependencies: h.Dependencies()})
+}
@@ -0,0 +1,51 @@
+package health
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChecker_Dependencies(t *testing.T) {
+	runs := 0
+	checker := &Checker{}
+	checker.AddReadinessProbe("orders-db", func() error {
+		runs++
+		return nil
+	}, DependencyOf("postgres", "postgres://app:secret@db:5432/orders?sslmode=disable"), Timeout(2*time.Second))
+	checker.AddReadinessProbe("cache", func() error { return nil }, NonCritical(), Labels(map[string]string{"team": "core"}))
+	checker.AddLivenessProbe("broker", func() error { return nil }, DependencyOf("tcp", "broker:5672"))
+
+	rec := httptest.NewRecorder()
+	checker.serverMux().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/.well-known/dependencies", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.NotContains(t, rec.Body.String(), "secret")
+
+	var resp DependenciesResponse
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, []Dependency{
+		{Name: "broker", Kind: "liveness", Type: "tcp", Target: "broker:5672", Critical: true},
+		{Name: "cache", Kind: "readiness", Labels: map[string]string{"team": "core"}},
+		{Name: "orders-db", Kind: "readiness", Type: "postgres", Target: "postgres://db:5432/orders", Critical: true, Timeout: "2s"},
+	}, resp.Dependencies)
+	assert.Equal(t, 0, runs)
+}
+
+func TestNewCheckerFromConfig_dependencies(t *testing.T) {
+	checker, err := NewCheckerFromConfig(&Config{
+		DependenciesPath: "/deps",
+		Probes:           []ProbeConfig{{Name: "redis", URI: "redis://:secret@redis:6379/0", Timeout: "1s"}},
+	})
+	assert.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	checker.serverMux().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/deps", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, []Dependency{
+		{Name: "redis", Kind: "readiness", Type: "redis", Target: "redis://redis:6379/0", Critical: true, Timeout: "1s"},
+	}, checker.Dependencies())
+}
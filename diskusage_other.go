@@ -0,0 +1,13 @@
+//go:build !linux && !darwin && !freebsd && !windows
+// +build !linux,!darwin,!freebsd,!windows
+
+package health
+
+import (
+	"fmt"
+	"runtime"
+)
+
+func diskUsage(_ string) (free, total uint64, err error) {
+	return 0, 0, fmt.Errorf("disk usage is not supported on %v", runtime.GOOS)
+}
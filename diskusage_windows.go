@@ -0,0 +1,21 @@
+//go:build windows
+// +build windows
+
+package health
+
+import "golang.org/x/sys/windows"
+
+// Returns the bytes available to the caller and the total size of the volume containing path.
+func diskUsage(path string) (free, total uint64, err error) {
+	p, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var totalFree uint64
+	if err := windows.GetDiskFreeSpaceEx(p, &free, &total, &totalFree); err != nil {
+		return 0, 0, err
+	}
+
+	return free, total, nil
+}
@@ -0,0 +1,274 @@
+package health
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/smtp"
+	"os"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+)
+
+const (
+	defaultEmailInterval = 15 * time.Minute
+	defaultEmailTimeout  = 10 * time.Second
+
+	defaultEmailSubject = `[{{if .Ready}}RECOVERED{{else}}UNREADY{{end}}] {{.Host}} is {{if not .Ready}}not {{end}}ready`
+	defaultEmailBody    = `{{.Host}} is {{if not .Ready}}not {{end}}ready since {{.Since.Format "2006-01-02 15:04:05 MST"}}.
+{{range .Result.Reasons}}
+- {{.Service}}: {{.Error}}{{end}}
+`
+)
+
+type emailConfig struct {
+	username  string
+	password  string
+	tlsConfig *tls.Config
+	implicit  bool
+	subject   string
+	body      string
+	interval  time.Duration
+	after     time.Duration
+	timeout   time.Duration
+}
+
+// An EmailOption configures an EmailListener.
+type EmailOption func(c *emailConfig)
+
+// Authenticates with the given credentials using PLAIN auth. Requires TLS unless the server is on localhost.
+func EmailAuth(username, password string) EmailOption {
+	return func(c *emailConfig) {
+		c.username = username
+		c.password = password
+	}
+}
+
+// Connects with TLS from the start (SMTPS, usually port 465) instead of upgrading the connection by STARTTLS.
+// If config is nil, the host of the server is verified.
+func EmailImplicitTLS(config *tls.Config) EmailOption {
+	return func(c *emailConfig) {
+		c.implicit = true
+		c.tlsConfig = config
+	}
+}
+
+// Sets the TLS config used by STARTTLS, e.g. to trust a private CA. By default the connection is upgraded with the
+// host of the server verified if the server supports STARTTLS.
+func EmailTLSConfig(config *tls.Config) EmailOption {
+	return func(c *emailConfig) {
+		c.tlsConfig = config
+	}
+}
+
+// Sets the templates of the subject and the body, see text/template. The templates are executed with EmailData.
+func EmailTemplates(subject, body string) EmailOption {
+	return func(c *emailConfig) {
+		c.subject = subject
+		c.body = body
+	}
+}
+
+// Sends at most one email within interval, later transitions are sent after the interval if they still apply.
+// Defaults to 15 minutes.
+func EmailMinInterval(interval time.Duration) EmailOption {
+	return func(c *emailConfig) {
+		c.interval = interval
+	}
+}
+
+// Only sends an email if the service is not ready for at least d, so short blips don't page anyone. The recovery is
+// only sent if the failure was.
+func EmailAfter(d time.Duration) EmailOption {
+	return func(c *emailConfig) {
+		c.after = d
+	}
+}
+
+// EmailData is passed to the templates of an EmailListener.
+type EmailData struct {
+	// Host name of the instance
+	Host string
+	// Whether the service became ready again or unready
+	Ready bool
+	// Time the service changed its state
+	Since time.Time
+	// Result of the evaluation causing the email
+	Result Result
+}
+
+// Returns a listener sending an email to the given recipients when the service becomes unready and when it
+// recovers, e.g. to the distribution list of the service owners in environments without chatops. The email is sent
+// asynchronously via the SMTP server at addr (host:port) using STARTTLS if supported. Failures are logged.
+//
+// Example:
+//		listener, err := health.EmailListener("smtp.example.com:587", "health@example.com", []string{"team@example.com"},
+//			health.EmailAuth("health@example.com", os.Getenv("SMTP_PASSWORD")), health.EmailAfter(5*time.Minute))
+//		if err != nil {
+//			log.Fatal(err)
+//		}
+//
+//		checker.AddListener(listener)
+func EmailListener(addr, from string, to []string, opts ...EmailOption) (Listener, error) {
+	cfg := &emailConfig{
+		subject:  defaultEmailSubject,
+		body:     defaultEmailBody,
+		interval: defaultEmailInterval,
+		timeout:  defaultEmailTimeout,
+	}
+
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	subject, err := template.New("subject").Parse(cfg.subject)
+	if err != nil {
+		return nil, fmt.Errorf("invalid subject template: %w", err)
+	}
+
+	body, err := template.New("body").Parse(cfg.body)
+	if err != nil {
+		return nil, fmt.Errorf("invalid body template: %w", err)
+	}
+
+	hostname, _ := os.Hostname()
+
+	mu := sync.Mutex{}
+	ready := true
+	var since time.Time
+	// Whether the failure was sent, so the recovery is sent as well
+	notified := false
+	var lastSent time.Time
+
+	return func(r Result) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if r.Ready != ready {
+			ready = r.Ready
+			since = r.CheckedAt
+		}
+
+		// A failure is sent once it lasted long enough, a recovery only if the failure was sent. Both are retried
+		// with the next result while rate limited.
+		send := notified
+		if !ready {
+			send = !notified && r.CheckedAt.Sub(since) >= cfg.after
+		}
+
+		if !send || !lastSent.IsZero() && r.CheckedAt.Sub(lastSent) < cfg.interval {
+			return
+		}
+
+		data := EmailData{Host: hostname, Ready: ready, Since: since, Result: r}
+		msg, err := cfg.message(subject, body, from, to, data)
+		if err != nil {
+			r.log().Printf("failed to render email: %v", err)
+			return
+		}
+
+		lastSent = r.CheckedAt
+		notified = !ready
+
+		go func() {
+			if err := cfg.send(addr, from, to, msg); err != nil {
+				r.log().Printf("failed to send email via %v: %v", addr, err)
+			}
+		}()
+	}, nil
+}
+
+// Renders the email including its headers.
+func (c *emailConfig) message(subject, body *template.Template, from string, to []string, data EmailData) ([]byte, error) {
+	var s bytes.Buffer
+	if err := subject.Execute(&s, data); err != nil {
+		return nil, err
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %v\r\n", from)
+	fmt.Fprintf(&msg, "To: %v\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&msg, "Subject: %v\r\n", strings.ReplaceAll(strings.ReplaceAll(s.String(), "\r", ""), "\n", " "))
+	fmt.Fprintf(&msg, "Date: %v\r\n", data.Result.CheckedAt.Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\nContent-Type: text/plain; charset=utf-8\r\n\r\n")
+
+	var b bytes.Buffer
+	if err := body.Execute(&b, data); err != nil {
+		return nil, err
+	}
+	msg.WriteString(strings.ReplaceAll(b.String(), "\n", "\r\n"))
+
+	return msg.Bytes(), nil
+}
+
+// Sends the message via the SMTP server.
+func (c *emailConfig) send(addr, from string, to []string, msg []byte) error {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return err
+	}
+
+	tlsConfig := c.tlsConfig
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{ServerName: host, MinVersion: tls.VersionTLS12}
+	}
+
+	dialer := &net.Dialer{Timeout: c.timeout}
+	var conn net.Conn
+	if c.implicit {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", addr)
+	}
+	if err != nil {
+		return err
+	}
+	_ = conn.SetDeadline(time.Now().Add(c.timeout))
+
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		_ = conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok && !c.implicit {
+		if err := client.StartTLS(tlsConfig); err != nil {
+			return err
+		}
+	}
+
+	if c.username != "" {
+		if err := client.Auth(smtp.PlainAuth("", c.username, c.password, host)); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(from); err != nil {
+		return err
+	}
+
+	for _, rcpt := range to {
+		if err := client.Rcpt(rcpt); err != nil {
+			return err
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
@@ -0,0 +1,132 @@
+package health
+
+import (
+	"net"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Starts a minimal SMTP server passing the received messages to the returned channel.
+func newEmailTestServer(t *testing.T) (string, <-chan string) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = l.Close() })
+
+	messages := make(chan string, 10)
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+
+			go func() {
+				defer conn.Close()
+
+				tp := textproto.NewConn(conn)
+				_ = tp.PrintfLine("220 localhost ESMTP")
+				for {
+					line, err := tp.ReadLine()
+					if err != nil {
+						return
+					}
+
+					switch {
+					case strings.HasPrefix(line, "DATA"):
+						_ = tp.PrintfLine("354 go ahead")
+						lines, _ := tp.ReadDotLines()
+						messages <- strings.Join(lines, "\n")
+						_ = tp.PrintfLine("250 OK")
+					case strings.HasPrefix(line, "QUIT"):
+						_ = tp.PrintfLine("221 bye")
+						return
+					default:
+						_ = tp.PrintfLine("250 OK")
+					}
+				}
+			}()
+		}
+	}()
+
+	return l.Addr().String(), messages
+}
+
+func receiveEmail(t *testing.T, messages <-chan string) string {
+	select {
+	case msg := <-messages:
+		return msg
+	case <-time.After(2 * time.Second):
+		t.Fatal("no email received")
+		return ""
+	}
+}
+
+func TestEmailListener(t *testing.T) {
+	addr, messages := newEmailTestServer(t)
+
+	listener, err := EmailListener(addr, "health@example.com", []string{"team@example.com"},
+		EmailAfter(time.Minute), EmailMinInterval(0))
+	assert.NoError(t, err)
+
+	start := time.Now()
+	unready := Result{Ready: false, Status: StatusFail, Reasons: []Reason{{Service: "database", Error: "connection refused"}}}
+
+	// Failures shorter than EmailAfter are not sent
+	unready.CheckedAt = start
+	listener(unready)
+	listener(Result{Ready: true, CheckedAt: start.Add(30 * time.Second)})
+
+	unready.CheckedAt = start.Add(time.Minute)
+	listener(unready)
+	unready.CheckedAt = start.Add(2 * time.Minute)
+	listener(unready)
+
+	msg := receiveEmail(t, messages)
+	assert.Contains(t, msg, "To: team@example.com")
+	assert.Contains(t, msg, "Subject: [UNREADY]")
+	assert.Contains(t, msg, "- database: connection refused")
+
+	unready.CheckedAt = start.Add(3 * time.Minute)
+	listener(unready)
+	listener(Result{Ready: true, CheckedAt: start.Add(4 * time.Minute)})
+
+	msg = receiveEmail(t, messages)
+	assert.Contains(t, msg, "Subject: [RECOVERED]")
+
+	select {
+	case msg := <-messages:
+		t.Fatalf("unexpected email: %v", msg)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestEmailListener_rateLimit(t *testing.T) {
+	addr, messages := newEmailTestServer(t)
+
+	listener, err := EmailListener(addr, "health@example.com", []string{"team@example.com"},
+		EmailMinInterval(time.Hour), EmailTemplates("{{.Ready}}", "{{len .Result.Reasons}} reasons"))
+	assert.NoError(t, err)
+
+	start := time.Now()
+	listener(Result{Ready: false, CheckedAt: start})
+	assert.Contains(t, receiveEmail(t, messages), "Subject: false")
+
+	// The recovery is sent once the interval passed
+	listener(Result{Ready: true, CheckedAt: start.Add(time.Minute)})
+	listener(Result{Ready: true, CheckedAt: start.Add(time.Hour)})
+
+	msg := receiveEmail(t, messages)
+	assert.Contains(t, msg, "Subject: true")
+	assert.Contains(t, msg, "0 reasons")
+}
+
+func TestEmailListener_err_template(t *testing.T) {
+	_, err := EmailListener("localhost:25", "a@example.com", nil, EmailTemplates("{{", ""))
+	assert.Error(t, err)
+}
@@ -0,0 +1,194 @@
+package health
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+// A ResponseEncoder renders the responses of the health endpoints, e.g. as XML or in a legacy format required by a
+// load balancer. The encoded value is an *AliveResponse, *ReadyResponse, *ProbeStatus or *HistoryResponse.
+//
+// Example:
+//		type textEncoder struct{}
+//
+//		func (textEncoder) ContentType() string { return "text/plain" }
+//
+//		func (textEncoder) Encode(w io.Writer, v interface{}) error {
+//			if r, ok := v.(*health.ReadyResponse); ok && r.Ready {
+//				_, err := io.WriteString(w, "UP")
+//				return err
+//			}
+//			_, err := io.WriteString(w, "DOWN")
+//			return err
+//		}
+//
+//		checker := &health.Checker{Encoder: textEncoder{}}
+type ResponseEncoder interface {
+	// Content type of the encoded responses, e.g. `application/xml`
+	ContentType() string
+	// Writes the encoded response to w
+	Encode(w io.Writer, v interface{}) error
+}
+
+// JSONEncoder encodes responses as JSON. It is used if no encoder is set.
+type JSONEncoder struct{}
+
+func (JSONEncoder) ContentType() string {
+	return "application/json"
+}
+
+func (JSONEncoder) Encode(w io.Writer, v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(b)
+	return err
+}
+
+// Returns the encoder set at the checker or the JSONEncoder.
+func (h *Checker) encoder() ResponseEncoder {
+	if h.Encoder == nil {
+		return JSONEncoder{}
+	}
+
+	return h.Encoder
+}
+
+// TextEncoder responds with `OK` or `UNAVAILABLE` as plain text, e.g. for load balancers and uptime monitors doing
+// literal string matching. The history is written as one line per result.
+//
+// Example:
+//		checker := &health.Checker{Encoder: health.TextEncoder{}}
+type TextEncoder struct{}
+
+func (TextEncoder) ContentType() string {
+	return "text/plain; charset=utf-8"
+}
+
+func (TextEncoder) Encode(w io.Writer, v interface{}) error {
+	switch resp := v.(type) {
+	case *AliveResponse:
+		return writeTextState(w, resp.Alive)
+	case *ReadyResponse:
+		return writeTextState(w, resp.Ready)
+	case *ProbeStatus:
+		return writeTextState(w, resp.Healthy)
+	case *WatchResponse:
+		return writeTextState(w, resp.Ready)
+	case *HistoryResponse:
+		return writeTextHistory(w, resp)
+	case *DependenciesResponse:
+		return writeTextDependencies(w, resp)
+	case *StatsResponse:
+		return writeTextStats(w, resp)
+	default:
+		return fmt.Errorf("unsupported response %T", v)
+	}
+}
+
+func writeTextState(w io.Writer, ok bool) error {
+	state := "UNAVAILABLE"
+	if ok {
+		state = "OK"
+	}
+
+	_, err := io.WriteString(w, state)
+	return err
+}
+
+// Writes a line per result ordered by kind, probe and time.
+func writeTextHistory(w io.Writer, resp *HistoryResponse) error {
+	for _, kind := range []struct {
+		name    string
+		entries map[string][]HistoryEntry
+	}{{"liveness", resp.Liveness}, {"readiness", resp.Readiness}} {
+		names := make([]string, 0, len(kind.entries))
+		for name := range kind.entries {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			for _, e := range kind.entries[name] {
+				state := "OK"
+				if !e.Healthy {
+					state = "UNAVAILABLE " + e.Error
+				}
+
+				if _, err := fmt.Fprintf(w, "%v %v %v %v %v\n", e.CheckedAt.Format(time.RFC3339), kind.name, name, e.Duration, state); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func writeTextDependencies(w io.Writer, resp *DependenciesResponse) error {
+	for _, d := range resp.Dependencies {
+		criticality := "critical"
+		if !d.Critical {
+			criticality = "non-critical"
+		}
+
+		if _, err := fmt.Fprintf(w, "%v %v %v %v %v\n", d.Kind, d.Name, criticality, orDash(d.Type), orDash(d.Target)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Writes a line per probe with its availability in percent within the last hour, day and week, starting with the
+// service.
+func writeTextStats(w io.Writer, resp *StatsResponse) error {
+	write := func(kind, name string, e StatsEntry) error {
+		_, err := fmt.Fprintf(w, "%v %v %v %v %v\n", kind, name, formatPercent(e.Hour), formatPercent(e.Day), formatPercent(e.Week))
+		return err
+	}
+
+	if err := write("service", "-", resp.Service); err != nil {
+		return err
+	}
+
+	for _, kind := range []struct {
+		name    string
+		entries map[string]StatsEntry
+	}{{"liveness", resp.Liveness}, {"readiness", resp.Readiness}} {
+		names := make([]string, 0, len(kind.entries))
+		for name := range kind.entries {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			if err := write(kind.name, name, kind.entries[name]); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func formatPercent(p *float64) string {
+	if p == nil {
+		return "-"
+	}
+
+	return fmt.Sprintf("%.2f%%", *p)
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+
+	return s
+}
@@ -0,0 +1,80 @@
+package health
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type textEncoder struct{}
+
+func (textEncoder) ContentType() string {
+	return "text/plain"
+}
+
+func (textEncoder) Encode(w io.Writer, v interface{}) error {
+	if r, ok := v.(*ReadyResponse); ok && r.Ready {
+		_, err := io.WriteString(w, "UP")
+		return err
+	}
+
+	_, err := io.WriteString(w, "DOWN")
+	return err
+}
+
+func TestChecker_Encoder(t *testing.T) {
+	checker := &Checker{Encoder: textEncoder{}}
+	checker.AddReadinessProbe("redis", func() error {
+		return fmt.Errorf("connection refused")
+	})
+
+	server := httptest.NewServer(checker.serverMux())
+	defer server.Close()
+
+	resp, err := http.Get(fmt.Sprintf("%v/.well-known/ready", server.URL))
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, _ := ioutil.ReadAll(resp.Body)
+
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, "text/plain", resp.Header.Get("Content-Type"))
+	assert.Equal(t, "DOWN", string(body))
+}
+
+func TestTextEncoder(t *testing.T) {
+	checker := &Checker{Encoder: TextEncoder{}}
+	checker.AddReadinessProbe("redis", func() error {
+		return fmt.Errorf("connection refused")
+	})
+
+	server := httptest.NewServer(checker.serverMux())
+	defer server.Close()
+
+	for path, expected := range map[string]string{
+		"/.well-known/alive":       "OK",
+		"/.well-known/ready":       "UNAVAILABLE",
+		"/.well-known/ready/redis": "UNAVAILABLE",
+	} {
+		resp, err := http.Get(server.URL + path)
+		assert.NoError(t, err)
+
+		body, _ := ioutil.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+
+		assert.Equal(t, "text/plain; charset=utf-8", resp.Header.Get("Content-Type"), path)
+		assert.Equal(t, expected, string(body), path)
+	}
+
+	resp, err := http.Get(server.URL + "/.well-known/history")
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, _ := ioutil.ReadAll(resp.Body)
+	assert.Regexp(t, `^(\S+ readiness redis \S+ UNAVAILABLE connection refused\n)+$`, string(body))
+}
@@ -0,0 +1,83 @@
+package health
+
+import "errors"
+
+// Errors classifying why a probe failed. Built-in probes wrap their errors, so use errors.Is to classify them.
+// Custom probes can wrap them as well, e.g. `fmt.Errorf("%w: broker is down", health.ErrUnreachable)`.
+var (
+	// The probe did not finish in time
+	ErrTimeout = errors.New("timeout")
+	// The service could not be connected to
+	ErrUnreachable = errors.New("unreachable")
+	// The service rejected the credentials of the probe
+	ErrUnauthorized = errors.New("unauthorized")
+	// The service is reachable, but reports to be unhealthy
+	ErrUnhealthy = errors.New("unhealthy")
+)
+
+// An error of a built-in probe matching one of the sentinel errors, while keeping the underlying error.
+type classifiedError struct {
+	class error
+	err   error
+}
+
+// Classifies err as class, which is one of the sentinel errors.
+func classify(class, err error) error {
+	return &classifiedError{class: class, err: err}
+}
+
+func (e *classifiedError) Error() string {
+	return e.err.Error()
+}
+
+func (e *classifiedError) Unwrap() error {
+	return e.err
+}
+
+func (e *classifiedError) Is(target error) bool {
+	return target == e.class
+}
+
+// An error carrying the ID of the trace recorded while the probe ran.
+type tracedError struct {
+	err     error
+	traceID string
+}
+
+// Attaches the ID of the trace recorded by a probe to its error. The ID is exposed as exemplar of the failure
+// counter by the MetricsHandler, so a failure spike in Grafana links to the trace of the failing probe.
+//
+// Example:
+//		checker.AddReadinessProbe("api", func() error {
+//			ctx, span := tracer.Start(context.Background(), "health.api")
+//			defer span.End()
+//			if err := pingAPI(ctx); err != nil {
+//				return health.WithTraceID(err, span.SpanContext().TraceID().String())
+//			}
+//			return nil
+//		})
+func WithTraceID(err error, traceID string) error {
+	if err == nil {
+		return nil
+	}
+
+	return &tracedError{err: err, traceID: traceID}
+}
+
+func (e *tracedError) Error() string {
+	return e.err.Error()
+}
+
+func (e *tracedError) Unwrap() error {
+	return e.err
+}
+
+// Returns the trace ID attached to err by WithTraceID or an empty string.
+func traceIDOf(err error) string {
+	var t *tracedError
+	if errors.As(err, &t) {
+		return t.traceID
+	}
+
+	return ""
+}
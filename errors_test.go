@@ -0,0 +1,51 @@
+package health
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassify(t *testing.T) {
+	cause := fmt.Errorf("connection refused")
+	err := classify(ErrUnreachable, fmt.Errorf("endpoint could not be reached: %w", cause))
+
+	assert.EqualError(t, err, "endpoint could not be reached: connection refused")
+	assert.True(t, errors.Is(err, ErrUnreachable))
+	assert.True(t, errors.Is(err, cause))
+	assert.False(t, errors.Is(err, ErrUnhealthy))
+}
+
+func TestProbeErrors(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer s.Close()
+
+	l, _ := net.Listen("tcp", "127.0.0.1:0")
+	addr := l.Addr().String()
+	_ = l.Close()
+
+	slow := func() error {
+		time.Sleep(time.Second)
+		return nil
+	}
+
+	assert.True(t, errors.Is(HTTPProbe(s.URL)(), ErrUnauthorized))
+	assert.True(t, errors.Is(TCPProbe(addr, time.Second)(), ErrUnreachable))
+	assert.True(t, errors.Is(runUntil(time.After(time.Millisecond), time.Millisecond, slow), ErrTimeout))
+}
+
+func TestReasonKindOf(t *testing.T) {
+	assert.Equal(t, KindError, reasonKindOf(fmt.Errorf("failed")))
+	assert.Equal(t, KindTimeout, reasonKindOf(&timeoutError{timeout: time.Second}))
+	assert.Equal(t, KindUnreachable, reasonKindOf(fmt.Errorf("%w: broker is down", ErrUnreachable)))
+	assert.Equal(t, KindUnauthorized, reasonKindOf(classify(ErrUnauthorized, fmt.Errorf("bind failed"))))
+	assert.Equal(t, KindUnhealthy, reasonKindOf(classify(ErrUnhealthy, fmt.Errorf("vault is sealed"))))
+}
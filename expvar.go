@@ -0,0 +1,93 @@
+package health
+
+import (
+	"expvar"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Guards publishing, as expvar panics if a name is published twice
+var expvarMu sync.Mutex
+
+// State of the checker published by PublishExpvar
+type expvarState struct {
+	Ready     *bool                  `json:"ready,omitempty"`
+	Status    Status                 `json:"status,omitempty"`
+	CheckedAt *time.Time             `json:"checkedAt,omitempty"`
+	Liveness  map[string]expvarProbe `json:"liveness"`
+	Readiness map[string]expvarProbe `json:"readiness"`
+}
+
+type expvarProbe struct {
+	Failures  uint64     `json:"failures"`
+	Status    Status     `json:"status,omitempty"`
+	Error     string     `json:"error,omitempty"`
+	CheckedAt *time.Time `json:"checkedAt,omitempty"`
+	Duration  string     `json:"duration,omitempty"`
+}
+
+// Publishes the state of the checker via expvar under the given name, so tools reading `/debug/vars` pick up the
+// health of the service. Includes the latest readiness result and the number of failures and latest result of each
+// probe. Probes are not run to publish the state. Fails if the name is already published, e.g. by another checker.
+//
+// Example:
+//		if err := checker.PublishExpvar("health"); err != nil {
+//			log.Fatal(err)
+//		}
+//		defer checker.EvaluateInBackground(10 * time.Second)()
+func (h *Checker) PublishExpvar(name string) error {
+	expvarMu.Lock()
+	defer expvarMu.Unlock()
+
+	if expvar.Get(name) != nil {
+		return fmt.Errorf("expvar %q is already published", name)
+	}
+
+	expvar.Publish(name, expvar.Func(func() interface{} {
+		return h.expvarState()
+	}))
+
+	return nil
+}
+
+func (h *Checker) expvarState() expvarState {
+	state := expvarState{
+		Liveness:  expvarProbes(h.livenessProbes),
+		Readiness: expvarProbes(h.readinessProbes),
+	}
+
+	h.mu.Lock()
+	last := h.lastResult
+	h.mu.Unlock()
+
+	if last != nil {
+		state.Ready = &last.Ready
+		state.Status = last.Status
+		state.CheckedAt = &last.CheckedAt
+	}
+
+	return state
+}
+
+func expvarProbes(probes map[string]*registeredProbe) map[string]expvarProbe {
+	states := make(map[string]expvarProbe, len(probes))
+	for name, probe := range probes {
+		failures, _ := probe.failureCount()
+		state := expvarProbe{Failures: failures}
+
+		if recent := probe.recent(); len(recent) > 0 {
+			r := recent[len(recent)-1]
+			state.Status = r.Status
+			state.CheckedAt = &r.CheckedAt
+			state.Duration = r.Duration.String()
+			if r.Err != nil {
+				state.Error = r.Err.Error()
+			}
+		}
+
+		states[name] = state
+	}
+
+	return states
+}
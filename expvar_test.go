@@ -0,0 +1,35 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChecker_PublishExpvar(t *testing.T) {
+	checker := &Checker{}
+	checker.AddReadinessProbe("redis", func() error {
+		return fmt.Errorf("connection refused")
+	})
+	// Names can not be unpublished, so each run of the test publishes a new one
+	name := fmt.Sprintf("health_test_%d", time.Now().UnixNano())
+	assert.NoError(t, checker.PublishExpvar(name))
+	assert.EqualError(t, (&Checker{}).PublishExpvar(name), fmt.Sprintf("expvar %q is already published", name))
+
+	checker.Check(context.Background())
+	checker.Check(context.Background())
+
+	var state expvarState
+	assert.NoError(t, json.Unmarshal([]byte(expvar.Get(name).String()), &state))
+
+	assert.False(t, *state.Ready)
+	assert.Equal(t, StatusFail, state.Status)
+	assert.Equal(t, uint64(2), state.Readiness["redis"].Failures)
+	assert.Equal(t, "connection refused", state.Readiness["redis"].Error)
+	assert.Empty(t, state.Liveness)
+}
@@ -0,0 +1,55 @@
+package health
+
+import "time"
+
+// State a response was built from. Responses built from the same state are equal.
+type responseState struct {
+	checkedAt time.Time
+	status    Status
+	ready     bool
+	peers     PeerStatus
+}
+
+type responseKey struct {
+	state  responseState
+	ok     bool
+	query  string
+	accept string
+}
+
+type encodedResponse struct {
+	key  responseKey
+	body []byte
+	etag string
+}
+
+// Returns the response encoded for the latest request to path if it was built for the same key.
+func (h *Checker) cachedResponse(path string, key responseKey, enabled bool) (*encodedResponse, bool) {
+	if !enabled {
+		return nil, false
+	}
+
+	h.responsesMu.Lock()
+	defer h.responsesMu.Unlock()
+
+	cached, ok := h.responses[path]
+	if !ok || cached.key != key {
+		return nil, false
+	}
+
+	return cached, true
+}
+
+// Keeps the encoded response for the next request to path. Only the latest response per path is kept.
+func (h *Checker) cacheResponse(path string, resp *encodedResponse) {
+	h.responsesMu.Lock()
+	defer h.responsesMu.Unlock()
+
+	if h.responses == nil {
+		h.responses = map[string]*encodedResponse{}
+	}
+	h.responses[path] = resp
+}
+
+// State of the liveness endpoint without liveness probes, which is always alive
+var staticAliveState = &responseState{status: StatusPass, ready: true}
@@ -0,0 +1,75 @@
+package health
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChecker_alive_cached(t *testing.T) {
+	checker := &Checker{}
+	m := checker.serverMux()
+
+	get := func(target string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		m.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		return rec
+	}
+
+	first := get("/.well-known/alive")
+	assert.Equal(t, http.StatusOK, first.Code)
+	assert.JSONEq(t, `{"alive": true, "status": "pass"}`, first.Body.String())
+
+	cached := checker.responses["/.well-known/alive"]
+	assert.NotNil(t, cached)
+	assert.Equal(t, first.Body.String(), get("/.well-known/alive").Body.String())
+	assert.Same(t, cached, checker.responses["/.well-known/alive"])
+
+	// Other queries are encoded again
+	assert.JSONEq(t, `{"alive": true}`, get("/.well-known/alive?brief=1").Body.String())
+}
+
+func TestChecker_ready_cached(t *testing.T) {
+	var healthy int32 = 1
+	checker := &Checker{}
+	checker.AddReadinessProbe("database", func() error {
+		if atomic.LoadInt32(&healthy) == 0 {
+			return errors.New("connection refused")
+		}
+		return nil
+	})
+
+	evaluated := make(chan Result, 1)
+	checker.AddListener(func(r Result) {
+		select {
+		case evaluated <- r:
+		default:
+		}
+	})
+
+	defer checker.EvaluateInBackground(50 * time.Millisecond)()
+	<-evaluated
+
+	m := checker.serverMux()
+	get := func() *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		m.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/.well-known/ready", nil))
+		return rec
+	}
+
+	first := get()
+	assert.Equal(t, http.StatusOK, first.Code)
+	assert.NotEmpty(t, first.Header().Get("ETag"))
+
+	// A new evaluation is encoded again
+	atomic.StoreInt32(&healthy, 0)
+	assert.Eventually(t, func() bool {
+		return get().Code == http.StatusServiceUnavailable
+	}, time.Second, 10*time.Millisecond)
+	assert.Contains(t, get().Body.String(), "connection refused")
+}
@@ -0,0 +1,85 @@
+package health
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// Selects probes by name
+type probeFilter func(name string) bool
+
+// Returns a filter for the probes given by the `include` and `exclude` query parameters, or nil if none is given.
+// Fails for names not matching a registered probe.
+func parseProbeFilter(r *http.Request, probes map[string]*registeredProbe) (probeFilter, error) {
+	query := r.URL.Query()
+	include, exclude := query["include"], query["exclude"]
+	if len(include) == 0 && len(exclude) == 0 {
+		return nil, nil
+	}
+
+	included, err := probeNames(include, probes)
+	if err != nil {
+		return nil, err
+	}
+
+	excluded, err := probeNames(exclude, probes)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(name string) bool {
+		return (len(included) == 0 || included[name]) && !excluded[name]
+	}, nil
+}
+
+// Returns the names as set. Fails for names not matching a registered probe.
+func probeNames(names []string, probes map[string]*registeredProbe) (map[string]bool, error) {
+	set := map[string]bool{}
+	for _, name := range names {
+		if _, ok := probes[name]; !ok {
+			return nil, fmt.Errorf("no probe named %q", name)
+		}
+
+		set[name] = true
+	}
+
+	return set, nil
+}
+
+// Returns the probes selected by keep, or all probes if keep is nil.
+func selectProbes(probes map[string]*registeredProbe, keep probeFilter) map[string]*registeredProbe {
+	if keep == nil {
+		return probes
+	}
+
+	selected := map[string]*registeredProbe{}
+	for name, probe := range probes {
+		if keep(name) {
+			selected[name] = probe
+		}
+	}
+
+	return selected
+}
+
+// Returns the result of the probes selected by keep, or the result itself if keep is nil.
+func (r Result) filter(keep probeFilter) Result {
+	if keep == nil {
+		return r
+	}
+
+	var probes []ProbeResult
+	for _, p := range r.Probes {
+		if keep(p.Name) {
+			probes = append(probes, p)
+		}
+	}
+
+	status, reasons := summarize(probes)
+	r.Ready = status != StatusFail
+	r.Status = status
+	r.Reasons = reasons
+	r.Probes = probes
+
+	return r
+}
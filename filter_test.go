@@ -0,0 +1,54 @@
+package health
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newFilterTestChecker() *Checker {
+	checker := &Checker{}
+	checker.AddReadinessProbe("database", func() error { return nil })
+	checker.AddReadinessProbe("vault", func() error { return fmt.Errorf("vault is sealed") })
+	checker.AddReadinessProbe("redis", func() error { return fmt.Errorf("connection refused") })
+
+	return checker
+}
+
+func TestChecker_ready_exclude(t *testing.T) {
+	server := httptest.NewServer(newFilterTestChecker().serverMux())
+	defer server.Close()
+
+	resp, err := http.Get(fmt.Sprintf("%v/.well-known/ready?exclude=vault", server.URL))
+	assert.NoError(t, err)
+	assert.EqualValues(t, http.StatusServiceUnavailable, resp.StatusCode)
+
+	resp, err = http.Get(fmt.Sprintf("%v/.well-known/ready?exclude=vault&exclude=redis", server.URL))
+	assert.NoError(t, err)
+	assert.EqualValues(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestChecker_ready_include(t *testing.T) {
+	checker := newFilterTestChecker()
+	defer checker.EvaluateInBackground(time.Hour)()
+
+	server := httptest.NewServer(checker.serverMux())
+	defer server.Close()
+
+	resp, err := http.Get(fmt.Sprintf("%v/.well-known/ready?include=database", server.URL))
+	assert.NoError(t, err)
+	assert.EqualValues(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestChecker_ready_err_unknownProbe(t *testing.T) {
+	server := httptest.NewServer(newFilterTestChecker().serverMux())
+	defer server.Close()
+
+	resp, err := http.Get(fmt.Sprintf("%v/.well-known/ready?exclude=unknown", server.URL))
+	assert.NoError(t, err)
+	assert.EqualValues(t, http.StatusBadRequest, resp.StatusCode)
+}
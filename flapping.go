@@ -0,0 +1,60 @@
+package health
+
+import (
+	"fmt"
+	"time"
+)
+
+// Holds a probe changing between passing and failing more than transitions times within window in a degraded state
+// until it stabilizes, so load balancers aren't whipsawed by an unstable dependency. While flapping, the probe is
+// marked as flapping and passing results are reported with StatusWarn. Failures are reported as they are.
+//
+// Example:
+//		checker.AddReadinessProbe("broker", health.TCPProbe("broker:5672", time.Second), health.FlapDetection(3, time.Minute))
+func FlapDetection(transitions int, window time.Duration) ProbeOption {
+	return func(p *registeredProbe) {
+		p.flapTransitions = transitions
+		p.flapWindow = window
+	}
+}
+
+// Records the state change of the result and dampens it if the probe is flapping.
+func (p *registeredProbe) dampen(r ProbeResult) ProbeResult {
+	if p.flapWindow <= 0 {
+		return r
+	}
+
+	p.flapMu.Lock()
+	defer p.flapMu.Unlock()
+
+	healthy := r.Err == nil
+	if p.flapSeen && healthy != p.flapHealthy {
+		p.flapChanges = append(p.flapChanges, r.CheckedAt)
+	}
+	p.flapSeen = true
+	p.flapHealthy = healthy
+
+	// Forget state changes outside of the window
+	recent := p.flapChanges[:0]
+	for _, t := range p.flapChanges {
+		if r.CheckedAt.Sub(t) < p.flapWindow {
+			recent = append(recent, t)
+		}
+	}
+	p.flapChanges = recent
+
+	if len(p.flapChanges) <= p.flapTransitions {
+		return r
+	}
+
+	r.Flapping = true
+	if r.Err != nil {
+		// A failure is reported as it is, dampening must not turn a failing critical probe into a warning
+		return r
+	}
+
+	r.Status = StatusWarn
+	r.Err = Warning(fmt.Errorf("probe is flapping: %d state changes within %v", len(p.flapChanges), p.flapWindow))
+
+	return r
+}
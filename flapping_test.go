@@ -0,0 +1,57 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFlapDetection(t *testing.T) {
+	healthy := true
+	checker := &Checker{}
+	checker.AddReadinessProbe("broker", func() error {
+		if healthy {
+			return nil
+		}
+		return fmt.Errorf("connection refused")
+	}, FlapDetection(2, time.Minute))
+
+	var results []Result
+	for i := 0; i < 5; i++ {
+		results = append(results, checker.Check(context.Background()))
+		healthy = !healthy
+	}
+
+	// pass, fail, pass: two state changes are tolerated
+	assert.Equal(t, StatusPass, results[0].Status)
+	assert.Equal(t, StatusFail, results[1].Status)
+	assert.Equal(t, StatusPass, results[2].Status)
+	assert.False(t, results[2].Probes[0].Flapping)
+
+	// fail, pass: failures are still reported, passes are held in degraded state
+	assert.False(t, results[3].Ready)
+	assert.Equal(t, StatusFail, results[3].Status)
+	assert.True(t, results[3].Probes[0].Flapping)
+	assert.Equal(t, "connection refused", results[3].Reasons[0].Error)
+
+	assert.Equal(t, StatusWarn, results[4].Status)
+	assert.True(t, results[4].Probes[0].Flapping)
+	assert.Equal(t, "probe is flapping: 4 state changes within 1m0s", results[4].Reasons[0].Error)
+}
+
+func TestFlapDetection_stabilizes(t *testing.T) {
+	p := newRegisteredProbe(nil, []ProbeOption{FlapDetection(1, time.Minute)})
+	start := time.Now()
+
+	p.dampen(ProbeResult{CheckedAt: start})
+	p.dampen(ProbeResult{CheckedAt: start.Add(time.Second), Err: fmt.Errorf("down")})
+	r := p.dampen(ProbeResult{CheckedAt: start.Add(2 * time.Second), Status: StatusPass})
+	assert.True(t, r.Flapping)
+
+	r = p.dampen(ProbeResult{CheckedAt: start.Add(2 * time.Minute), Status: StatusPass})
+	assert.False(t, r.Flapping)
+	assert.Equal(t, StatusPass, r.Status)
+}
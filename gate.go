@@ -0,0 +1,133 @@
+package health
+
+import (
+	"context"
+	"sync"
+)
+
+// A ReadinessGate follows the readiness of the service, so message consumers can pause fetching while the service
+// is unready and resume automatically once it recovers. It is driven by the evaluations of the readiness probes,
+// use it together with EvaluateInBackground.
+type ReadinessGate struct {
+	mu     sync.Mutex
+	ready  bool
+	readyC chan struct{}
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	done    chan struct{}
+	stopped chan struct{}
+}
+
+// Returns a gate following the readiness of the service, see ReadinessGate. The gate starts with the latest result
+// and is unready until the first evaluation. Call Close to stop following the checker.
+//
+// Example:
+//		gate := checker.ReadinessGate()
+//		defer gate.Close()
+//
+//		for {
+//			<-gate.Ready()
+//			// Fetching is canceled as soon as the service becomes unready
+//			consume(gate.Context(), subscription)
+//		}
+func (h *Checker) ReadinessGate() *ReadinessGate {
+	updates, unsubscribe := h.subscribe()
+
+	g := &ReadinessGate{
+		readyC:  make(chan struct{}),
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	g.ctx, g.cancel = context.WithCancel(context.Background())
+	g.cancel()
+
+	h.mu.Lock()
+	last := h.lastResult
+	h.mu.Unlock()
+
+	if last != nil {
+		g.update(last.Ready)
+	}
+
+	go func() {
+		defer close(g.stopped)
+		defer unsubscribe()
+
+		for {
+			select {
+			case r := <-updates:
+				g.update(r.Ready)
+			case <-g.done:
+				return
+			}
+		}
+	}()
+
+	return g
+}
+
+// Applies a transition of the readiness.
+func (g *ReadinessGate) update(ready bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if ready == g.ready {
+		return
+	}
+
+	g.ready = ready
+	if ready {
+		close(g.readyC)
+		g.ctx, g.cancel = context.WithCancel(context.Background())
+	} else {
+		g.readyC = make(chan struct{})
+		g.cancel()
+	}
+}
+
+// Returns a channel which is closed while the service is ready. Call it again after the service became unready to
+// wait for the recovery.
+func (g *ReadinessGate) Ready() <-chan struct{} {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.readyC
+}
+
+// Returns a context which is canceled when the service becomes unready. While unready, the context is already
+// canceled.
+func (g *ReadinessGate) Context() context.Context {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.ctx
+}
+
+// Returns whether the service is ready.
+func (g *ReadinessGate) IsReady() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.ready
+}
+
+// Blocks until the service is ready or ctx is done.
+func (g *ReadinessGate) Wait(ctx context.Context) error {
+	select {
+	case <-g.Ready():
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Stops following the checker. Cancels the context of the gate.
+func (g *ReadinessGate) Close() {
+	close(g.done)
+	<-g.stopped
+
+	g.mu.Lock()
+	g.cancel()
+	g.mu.Unlock()
+}
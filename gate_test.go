@@ -0,0 +1,62 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChecker_ReadinessGate(t *testing.T) {
+	var healthy int32 = 1
+	checker := &Checker{}
+	checker.AddReadinessProbe("broker", func() error {
+		if atomic.LoadInt32(&healthy) == 0 {
+			return fmt.Errorf("connection refused")
+		}
+		return nil
+	})
+
+	gate := checker.ReadinessGate()
+	defer gate.Close()
+
+	assert.False(t, gate.IsReady())
+	assert.Error(t, gate.Context().Err())
+
+	checker.evaluateReadiness(context.Background(), true)
+	assert.NoError(t, gate.Wait(timeoutContext(t)))
+
+	ctx := gate.Context()
+	assert.NoError(t, ctx.Err())
+
+	atomic.StoreInt32(&healthy, 0)
+	checker.evaluateReadiness(context.Background(), true)
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("context was not canceled when the service became unready")
+	}
+
+	assert.False(t, gate.IsReady())
+	select {
+	case <-gate.Ready():
+		t.Fatal("ready channel is closed while unready")
+	default:
+	}
+
+	atomic.StoreInt32(&healthy, 1)
+	checker.evaluateReadiness(context.Background(), true)
+	assert.NoError(t, gate.Wait(timeoutContext(t)))
+	assert.NoError(t, gate.Context().Err())
+}
+
+func timeoutContext(t *testing.T) context.Context {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	t.Cleanup(cancel)
+
+	return ctx
+}
@@ -0,0 +1,30 @@
+package health
+
+// Returns the result with StatusStarting instead of StatusFail while the grace period is not over.
+// The grace period starts with the first evaluation of the checker.
+func (h *Checker) withGrace(r Result) Result {
+	if h.GracePeriod <= 0 {
+		return r
+	}
+
+	h.mu.Lock()
+	if h.startedAt.IsZero() {
+		h.startedAt = r.CheckedAt
+	}
+	started := h.startedAt
+	h.mu.Unlock()
+
+	if r.Status != StatusFail || r.CheckedAt.Sub(started) >= h.GracePeriod {
+		return r
+	}
+
+	r.Status = StatusStarting
+	r.Ready = h.ReadyWhileStarting
+
+	return r
+}
+
+// Returns true if the result of liveness probes reports the service as alive. Services are alive while starting.
+func (r Result) alive() bool {
+	return r.Ready || r.Status == StatusStarting
+}
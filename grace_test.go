@@ -0,0 +1,56 @@
+package health
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChecker_GracePeriod(t *testing.T) {
+	checker := &Checker{GracePeriod: time.Hour}
+	checker.AddLivenessProbe("deadlock", func() error {
+		return fmt.Errorf("no progress")
+	})
+	checker.AddReadinessProbe("database", func() error {
+		return fmt.Errorf("connection refused")
+	})
+
+	server := httptest.NewServer(checker.serverMux())
+	defer server.Close()
+
+	resp, err := http.Get(fmt.Sprintf("%v/.well-known/alive", server.URL))
+	assert.NoError(t, err)
+	assert.EqualValues(t, http.StatusOK, resp.StatusCode)
+
+	resp, err = http.DefaultClient.Do(detailedRequest(fmt.Sprintf("%v/.well-known/ready", server.URL)))
+	assert.NoError(t, err)
+	assert.EqualValues(t, http.StatusServiceUnavailable, resp.StatusCode)
+	body, _ := ioutil.ReadAll(resp.Body)
+	assert.Contains(t, string(body), `"status":"starting"`)
+
+	checker.ReadyWhileStarting = true
+	resp, err = http.Get(fmt.Sprintf("%v/.well-known/ready", server.URL))
+	assert.NoError(t, err)
+	assert.EqualValues(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestChecker_GracePeriod_over(t *testing.T) {
+	checker := &Checker{GracePeriod: time.Millisecond}
+	checker.AddLivenessProbe("deadlock", func() error {
+		return fmt.Errorf("no progress")
+	})
+
+	alive, _ := checker.IsAlive()
+	assert.True(t, alive)
+
+	time.Sleep(10 * time.Millisecond)
+
+	alive, reasons := checker.IsAlive()
+	assert.False(t, alive)
+	assert.Equal(t, []string{"deadlock: no progress"}, reasons)
+}
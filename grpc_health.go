@@ -0,0 +1,36 @@
+package health
+
+import "google.golang.org/grpc/health/grpc_health_v1"
+
+// Interface matching the method of a grpc health server (google.golang.org/grpc/health) to set the status of a service.
+type GrpcHealthSetter interface {
+	SetServingStatus(service string, status grpc_health_v1.HealthCheckResponse_ServingStatus)
+}
+
+// Returns a listener syncing the results of the readiness probes to a grpc health server, so HTTP and gRPC health
+// never disagree. The server as a whole, i.e. the empty service name, is SERVING while the service is ready.
+// Each probe is set as a service of its own, which is SERVING unless the probe fails.
+//
+// Example:
+//		healthServer := grpchealth.NewServer()
+//		grpc_health_v1.RegisterHealthServer(grpcServer, healthServer)
+//
+//		checker.AddListener(health.GrpcHealthListener(healthServer))
+//		defer checker.EvaluateInBackground(10 * time.Second)()
+func GrpcHealthListener(server GrpcHealthSetter) Listener {
+	return func(r Result) {
+		server.SetServingStatus("", servingStatus(r.Ready))
+
+		for _, p := range r.Probes {
+			server.SetServingStatus(p.Name, servingStatus(p.Status != StatusFail))
+		}
+	}
+}
+
+func servingStatus(serving bool) grpc_health_v1.HealthCheckResponse_ServingStatus {
+	if serving {
+		return grpc_health_v1.HealthCheckResponse_SERVING
+	}
+
+	return grpc_health_v1.HealthCheckResponse_NOT_SERVING
+}
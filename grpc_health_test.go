@@ -0,0 +1,34 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	grpchealth "google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestGrpcHealthListener(t *testing.T) {
+	server := grpchealth.NewServer()
+
+	checker := &Checker{}
+	checker.AddReadinessProbe("database", func() error { return nil })
+	checker.AddReadinessProbe("cache", func() error {
+		return fmt.Errorf("connection refused")
+	})
+	checker.AddListener(GrpcHealthListener(server))
+
+	checker.evaluateReadiness(context.Background(), true)
+
+	for service, expected := range map[string]grpc_health_v1.HealthCheckResponse_ServingStatus{
+		"":         grpc_health_v1.HealthCheckResponse_NOT_SERVING,
+		"database": grpc_health_v1.HealthCheckResponse_SERVING,
+		"cache":    grpc_health_v1.HealthCheckResponse_NOT_SERVING,
+	} {
+		resp, err := server.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{Service: service})
+		assert.NoError(t, err)
+		assert.Equal(t, expected, resp.Status, service)
+	}
+}
@@ -0,0 +1,84 @@
+package health
+
+import (
+	"compress/gzip"
+	"net/http"
+	"strings"
+)
+
+// Compresses the response of next with gzip if the client accepts it.
+func gzipHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+		if !acceptsGzip(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		gw := &gzipResponseWriter{ResponseWriter: w}
+		defer gw.Close()
+
+		next.ServeHTTP(gw, r)
+	})
+}
+
+// Returns true if the Accept-Encoding header of r contains gzip and does not disable it by `q=0`.
+func acceptsGzip(r *http.Request) bool {
+	for _, encoding := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		parts := strings.Split(encoding, ";")
+		if strings.TrimSpace(parts[0]) != "gzip" {
+			continue
+		}
+
+		for _, param := range parts[1:] {
+			if q := strings.TrimSpace(param); q == "q=0" || q == "q=0.0" || q == "q=0.00" || q == "q=0.000" {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	return false
+}
+
+// Compresses the body lazily, so responses without body, e.g. 304 or HEAD, are left untouched.
+// The status code is held back until the first write, as the headers depend on whether a body is written.
+type gzipResponseWriter struct {
+	http.ResponseWriter
+	gz   *gzip.Writer
+	code int
+}
+
+func (w *gzipResponseWriter) WriteHeader(code int) {
+	if w.code == 0 {
+		w.code = code
+	}
+}
+
+func (w *gzipResponseWriter) Write(b []byte) (int, error) {
+	if w.gz == nil {
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Header().Del("Content-Length")
+		w.gz = gzip.NewWriter(w.ResponseWriter)
+		w.writeHeader()
+	}
+
+	return w.gz.Write(b)
+}
+
+// Flushes the compressed body or the held back status code if no body was written.
+func (w *gzipResponseWriter) Close() {
+	if w.gz != nil {
+		_ = w.gz.Close()
+		return
+	}
+
+	w.writeHeader()
+}
+
+func (w *gzipResponseWriter) writeHeader() {
+	if w.code != 0 {
+		w.ResponseWriter.WriteHeader(w.code)
+	}
+}
@@ -0,0 +1,64 @@
+package health
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChecker_compress(t *testing.T) {
+	checker := &Checker{Compress: true}
+	checker.AddReadinessProbe("redis", func() error {
+		return fmt.Errorf("connection refused")
+	})
+
+	server := httptest.NewServer(checker.serverMux())
+	defer server.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("%v/.well-known/ready", server.URL), nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	resp, err := http.DefaultTransport.RoundTrip(req)
+	assert.NoError(t, err)
+	assert.EqualValues(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, "gzip", resp.Header.Get("Content-Encoding"))
+
+	gz, err := gzip.NewReader(resp.Body)
+	assert.NoError(t, err)
+	body, _ := ioutil.ReadAll(gz)
+	assert.Contains(t, string(body), "connection refused")
+}
+
+func TestChecker_compress_quiet(t *testing.T) {
+	checker := &Checker{Compress: true}
+	server := httptest.NewServer(checker.serverMux())
+	defer server.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("%v/.well-known/ready?quiet", server.URL), nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	resp, err := http.DefaultTransport.RoundTrip(req)
+
+	assert.NoError(t, err)
+	assert.Empty(t, resp.Header.Get("Content-Encoding"))
+	body, _ := ioutil.ReadAll(resp.Body)
+	assert.Empty(t, body)
+}
+
+func TestAcceptsGzip(t *testing.T) {
+	for header, expected := range map[string]bool{
+		"":                  false,
+		"gzip":              true,
+		"deflate, gzip;q=1": true,
+		"gzip;q=0":          false,
+		"br":                false,
+	} {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Accept-Encoding", header)
+
+		assert.Equal(t, expected, acceptsGzip(r), header)
+	}
+}
@@ -0,0 +1,84 @@
+package healthtest
+
+import (
+	"context"
+	"testing"
+
+	health "github.com/regiocom/healthchecker"
+)
+
+// Fails the test if the checker is not ready.
+func AssertReady(t testing.TB, checker *health.Checker) bool {
+	t.Helper()
+
+	r := checker.Check(context.Background())
+	if !r.Ready {
+		t.Errorf("expected service to be ready, but it is not: %v", r.Reasons)
+		return false
+	}
+
+	return true
+}
+
+// Fails the test if the checker is ready or one of the given probes did not fail.
+func AssertNotReady(t testing.TB, checker *health.Checker, failing ...string) bool {
+	t.Helper()
+
+	r := checker.Check(context.Background())
+	if r.Ready {
+		t.Errorf("expected service not to be ready, but it is")
+		return false
+	}
+
+	return assertFailing(t, r, failing)
+}
+
+// Fails the test if the checker is not alive.
+func AssertAlive(t testing.TB, checker *health.Checker) bool {
+	t.Helper()
+
+	if alive, reasons := checker.IsAlive(); !alive {
+		t.Errorf("expected service to be alive, but it is not: %v", reasons)
+		return false
+	}
+
+	return true
+}
+
+// Fails the test if the checker is alive.
+func AssertNotAlive(t testing.TB, checker *health.Checker) bool {
+	t.Helper()
+
+	if alive, _ := checker.IsAlive(); alive {
+		t.Errorf("expected service not to be alive, but it is")
+		return false
+	}
+
+	return true
+}
+
+// Fails the test if one of the given probes passed or is missing in the result.
+func assertFailing(t testing.TB, r health.Result, failing []string) bool {
+	t.Helper()
+
+	ok := true
+	for _, name := range failing {
+		found := false
+		for _, p := range r.Probes {
+			if p.Name == name {
+				found = true
+				if p.Err == nil {
+					t.Errorf("expected probe %q to fail, but it passed", name)
+					ok = false
+				}
+			}
+		}
+
+		if !found {
+			t.Errorf("expected probe %q to fail, but it is not registered", name)
+			ok = false
+		}
+	}
+
+	return ok
+}
@@ -0,0 +1,134 @@
+package healthtest
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	health "github.com/regiocom/healthchecker"
+)
+
+// Clock is a fake health.Clock, which only moves forward when advanced. Use it to test grace periods, flap detection,
+// probe intervals, timeouts and the background evaluation without real sleeps.
+//
+// Example:
+//		clock := healthtest.NewClock(time.Now())
+//		checker := &health.Checker{Clock: clock, GracePeriod: time.Minute}
+//		checker.AddReadinessProbe("database", healthtest.AlwaysFail("connection refused"))
+//
+//		clock.Advance(2 * time.Minute)
+//		healthtest.AssertNotReady(t, checker, "database")
+type Clock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*timer
+}
+
+// A pending call of After or a running ticker
+type timer struct {
+	at     time.Time
+	period time.Duration
+	ch     chan time.Time
+}
+
+// Returns a clock starting at the given time.
+func NewClock(start time.Time) *Clock {
+	return &Clock{now: start}
+}
+
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *Clock) After(d time.Duration) <-chan time.Time {
+	return c.add(d, 0).ch
+}
+
+func (c *Clock) NewTicker(d time.Duration) health.Ticker {
+	if d <= 0 {
+		panic("non-positive interval for NewTicker")
+	}
+
+	return &ticker{clock: c, timer: c.add(d, d)}
+}
+
+// Moves the clock forward by d and fires all timers and tickers due until then in order.
+// Like time.Ticker, ticks are dropped if the receiver is too slow.
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	target := c.now.Add(d)
+	for {
+		sort.Slice(c.timers, func(i, j int) bool {
+			return c.timers[i].at.Before(c.timers[j].at)
+		})
+
+		if len(c.timers) == 0 || c.timers[0].at.After(target) {
+			break
+		}
+
+		t := c.timers[0]
+		c.now = t.at
+		c.fire(t)
+	}
+
+	c.now = target
+}
+
+func (c *Clock) add(d, period time.Duration) *timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t := &timer{at: c.now.Add(d), period: period, ch: make(chan time.Time, 1)}
+	if d <= 0 {
+		t.ch <- c.now
+		return t
+	}
+
+	c.timers = append(c.timers, t)
+	return t
+}
+
+// Sends the current time to the timer and reschedules tickers.
+func (c *Clock) fire(t *timer) {
+	select {
+	case t.ch <- c.now:
+	default:
+	}
+
+	if t.period > 0 {
+		t.at = t.at.Add(t.period)
+		return
+	}
+
+	c.remove(t)
+}
+
+func (c *Clock) remove(t *timer) {
+	for i := range c.timers {
+		if c.timers[i] == t {
+			c.timers = append(c.timers[:i], c.timers[i+1:]...)
+			return
+		}
+	}
+}
+
+type ticker struct {
+	clock *Clock
+	timer *timer
+}
+
+func (t *ticker) C() <-chan time.Time {
+	return t.timer.ch
+}
+
+func (t *ticker) Stop() {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	t.clock.remove(t.timer)
+}
@@ -0,0 +1,197 @@
+package healthtest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	health "github.com/regiocom/healthchecker"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/websocket"
+)
+
+// Records failures instead of failing the test
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestFailNTimes(t *testing.T) {
+	probe := FailNTimes(2, "connection refused")
+
+	assert.EqualError(t, probe(), "connection refused")
+	assert.EqualError(t, probe(), "connection refused")
+	assert.NoError(t, probe())
+}
+
+func TestBlocking(t *testing.T) {
+	probe, release := Blocking()
+	checker := &health.Checker{}
+	checker.AddReadinessProbe("slow", probe, health.Timeout(10*time.Millisecond))
+
+	AssertNotReady(t, checker, "slow")
+
+	release()
+	release()
+	AssertReady(t, checker)
+}
+
+func TestAssertions(t *testing.T) {
+	checker := &health.Checker{}
+	checker.AddReadinessProbe("database", AlwaysPass())
+	checker.AddReadinessProbe("cache", AlwaysFail("connection refused"))
+	checker.AddLivenessProbe("deadlock", AlwaysPass())
+
+	AssertNotReady(t, checker, "cache")
+	AssertAlive(t, checker)
+
+	r := &recorder{TB: t}
+	assert.False(t, AssertReady(r, checker))
+	assert.False(t, AssertNotReady(r, checker, "database", "queue"))
+	assert.False(t, AssertNotAlive(r, checker))
+	assert.Equal(t, []string{
+		"expected service to be ready, but it is not: [cache: connection refused]",
+		`expected probe "database" to fail, but it passed`,
+		`expected probe "queue" to fail, but it is not registered`,
+		"expected service not to be alive, but it is",
+	}, r.errors)
+}
+
+func TestServer(t *testing.T) {
+	checker := &health.Checker{}
+	checker.AddReadinessProbe("database", AlwaysPass())
+	checker.AddReadinessProbe("cache", AlwaysFail("connection refused"))
+
+	server := NewServer(checker)
+	defer server.Close()
+
+	code, ready, err := server.Ready()
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.False(t, ready.Ready)
+	assert.Len(t, ready.Probes, 2)
+
+	code, alive, err := server.Alive()
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, code)
+	assert.True(t, alive.Alive)
+}
+
+func TestClock(t *testing.T) {
+	start := time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+	clock := NewClock(start)
+
+	after := clock.After(time.Minute)
+	ticker := clock.NewTicker(30 * time.Second)
+	defer ticker.Stop()
+
+	clock.Advance(45 * time.Second)
+	assert.Equal(t, start.Add(30*time.Second), <-ticker.C())
+	assert.Len(t, after, 0)
+
+	clock.Advance(15 * time.Second)
+	assert.Equal(t, start.Add(time.Minute), <-after)
+	assert.Equal(t, start.Add(time.Minute), <-ticker.C())
+	assert.Equal(t, start.Add(time.Minute), clock.Now())
+}
+
+func TestClock_GracePeriod(t *testing.T) {
+	clock := NewClock(time.Now())
+	checker := &health.Checker{Clock: clock, GracePeriod: time.Minute}
+	checker.AddReadinessProbe("database", AlwaysFail("connection refused"))
+
+	assert.Equal(t, health.StatusStarting, checker.Check(context.Background()).Status)
+
+	clock.Advance(2 * time.Minute)
+	assert.Equal(t, health.StatusFail, checker.Check(context.Background()).Status)
+}
+
+func TestClock_EvaluateInBackground(t *testing.T) {
+	clock := NewClock(time.Now())
+	evaluated := make(chan health.Result, 1)
+
+	checker := &health.Checker{Clock: clock}
+	checker.AddReadinessProbe("database", FailNTimes(1, "connection refused"))
+	checker.AddListener(func(r health.Result) {
+		evaluated <- r
+	})
+
+	defer checker.EvaluateInBackground(time.Minute)()
+	assert.False(t, (<-evaluated).Ready)
+
+	// The interval varies by up to 10%
+	clock.Advance(time.Minute + 6*time.Second)
+	assert.True(t, (<-evaluated).Ready)
+}
+
+func TestClock_WebSocketHandler(t *testing.T) {
+	clock := NewClock(time.Now())
+	checker := &health.Checker{Clock: clock}
+	checker.AddReadinessProbe("database", FailNTimes(1, "connection refused"))
+
+	server := httptest.NewServer(checker.WebSocketHandler(time.Minute))
+	defer server.Close()
+
+	ws, err := websocket.Dial(strings.Replace(server.URL, "http://", "ws://", 1), "", server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ws.Close()
+
+	var status struct {
+		Ready bool `json:"ready"`
+	}
+	assert.NoError(t, websocket.JSON.Receive(ws, &status))
+	assert.False(t, status.Ready)
+
+	// Sent on the keepalive without sleeping
+	clock.Advance(time.Minute)
+	assert.NoError(t, websocket.JSON.Receive(ws, &status))
+	assert.True(t, status.Ready)
+}
+
+func TestAssertValidResponse(t *testing.T) {
+	checker := &health.Checker{Metadata: map[string]string{"version": "1.0.0"}}
+	checker.AddLivenessProbe("goroutines", AlwaysPass())
+	checker.AddReadinessProbe("database", AlwaysFail("connection refused"),
+		health.Labels(map[string]string{"team": "billing"}), health.DependencyOf("postgres", "db:5432"))
+	checker.AddReadinessProbe("cache", AlwaysPass(), health.NonCritical())
+
+	handler := checker.Middleware(http.NotFoundHandler())
+	for path, name := range map[string]string{
+		"/.well-known/alive":          "AliveResponse",
+		"/.well-known/ready":          "ReadyResponseV1",
+		"/.well-known/ready/database": "ProbeStatus",
+		"/.well-known/history":        "HistoryResponse",
+		"/.well-known/dependencies":   "DependenciesResponse",
+		"/.well-known/stats":          "StatsResponse",
+	} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		AssertValidResponse(t, name, rec.Body.Bytes())
+	}
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/.well-known/ready", nil)
+	req.Header.Set("Accept", health.MediaTypeHealthV2)
+	handler.ServeHTTP(rec, req)
+	AssertValidResponse(t, "ReadyResponse", rec.Body.Bytes())
+
+	r := &recorder{TB: t}
+	assert.False(t, AssertValidResponse(r, "ReadyResponse", []byte(`{"ready": "yes", "status": "broken", "extra": 1}`)))
+	assert.Equal(t, []string{
+		"invalid ReadyResponse: $: property extra is not in the schema",
+		"invalid ReadyResponse: $.ready: expected boolean, got string",
+		"invalid ReadyResponse: $.status: broken is not one of [pass warn fail starting standby]",
+	}, r.errors)
+}
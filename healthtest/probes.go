@@ -0,0 +1,76 @@
+// Package healthtest provides fake probes, a test server and assertions to test the health checks of an application.
+//
+// Example:
+//		func TestReadiness(t *testing.T) {
+//			checker := &health.Checker{}
+//			checker.AddReadinessProbe("database", healthtest.AlwaysFail("connection refused"))
+//
+//			healthtest.AssertNotReady(t, checker, "database")
+//		}
+package healthtest
+
+import (
+	"errors"
+	"sync"
+
+	health "github.com/regiocom/healthchecker"
+)
+
+// Returns a probe that always passes.
+func AlwaysPass() health.Probe {
+	return func() error {
+		return nil
+	}
+}
+
+// Returns a probe that always fails with the given message.
+func AlwaysFail(message string) health.Probe {
+	err := errors.New(message)
+
+	return func() error {
+		return err
+	}
+}
+
+// Returns a probe that fails the first n calls and passes afterwards, e.g. to test a dependency coming up.
+func FailNTimes(n int, message string) health.Probe {
+	err := errors.New(message)
+	mu := sync.Mutex{}
+	calls := 0
+
+	return func() error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		calls++
+		if calls <= n {
+			return err
+		}
+
+		return nil
+	}
+}
+
+// Returns a probe that blocks until release is called and passes afterwards, e.g. to test timeouts.
+//
+// Example:
+//		probe, release := healthtest.Blocking()
+//		defer release()
+//		checker.AddReadinessProbe("slow", probe, health.Timeout(10*time.Millisecond))
+func Blocking() (probe health.Probe, release func()) {
+	released := make(chan struct{})
+	once := sync.Once{}
+
+	probe = func() error {
+		<-released
+		return nil
+	}
+
+	release = func() {
+		once.Do(func() {
+			close(released)
+		})
+	}
+
+	return probe, release
+}
@@ -0,0 +1,155 @@
+package healthtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	health "github.com/regiocom/healthchecker"
+)
+
+// Fails the test if body is no valid response of the given type, e.g. `ReadyResponse`, according to
+// health.ResponseSchema. Properties missing in the schema are reported as well, so changes of the response shape are
+// caught before they reach consumers generating parsers from the schema.
+//
+// Example:
+//		rec := httptest.NewRecorder()
+//		checker.Middleware(api).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/.well-known/ready?verbose=1", nil))
+//		healthtest.AssertValidResponse(t, "ReadyResponse", rec.Body.Bytes())
+func AssertValidResponse(t testing.TB, name string, body []byte) bool {
+	t.Helper()
+
+	var v interface{}
+	if err := json.Unmarshal(body, &v); err != nil {
+		t.Errorf("invalid JSON response: %v", err)
+		return false
+	}
+
+	defs := definitions()
+	schema, ok := defs[name]
+	if !ok {
+		t.Errorf("unknown response %q", name)
+		return false
+	}
+
+	errs := validate(defs, schema, v, "")
+	for _, err := range errs {
+		t.Errorf("invalid %v: %v", name, err)
+	}
+
+	return len(errs) == 0
+}
+
+// Returns the definitions of the schema as decoded from JSON.
+func definitions() map[string]interface{} {
+	b, err := json.Marshal(health.ResponseSchema())
+	if err != nil {
+		panic(err)
+	}
+
+	var schema struct {
+		Defs map[string]interface{} `json:"$defs"`
+	}
+	if err := json.Unmarshal(b, &schema); err != nil {
+		panic(err)
+	}
+
+	return schema.Defs
+}
+
+// Validates v against the subset of JSON Schema used by the response schemas.
+func validate(defs map[string]interface{}, schema interface{}, v interface{}, path string) []string {
+	s, _ := schema.(map[string]interface{})
+	if ref, ok := s["$ref"].(string); ok {
+		return validate(defs, defs[strings.TrimPrefix(ref, "#/$defs/")], v, path)
+	}
+
+	if path == "" {
+		path = "$"
+	}
+
+	if enum, ok := s["enum"].([]interface{}); ok {
+		found := false
+		for _, e := range enum {
+			found = found || reflect.DeepEqual(e, v)
+		}
+
+		if !found {
+			return []string{fmt.Sprintf("%v: %v is not one of %v", path, v, enum)}
+		}
+	}
+
+	switch s["type"] {
+	case "object":
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			return []string{fmt.Sprintf("%v: expected object, got %T", path, v)}
+		}
+
+		return validateObject(defs, s, obj, path)
+	case "array":
+		arr, ok := v.([]interface{})
+		if !ok {
+			return []string{fmt.Sprintf("%v: expected array, got %T", path, v)}
+		}
+
+		var errs []string
+		for i, item := range arr {
+			errs = append(errs, validate(defs, s["items"], item, fmt.Sprintf("%v[%v]", path, i))...)
+		}
+
+		return errs
+	case "string":
+		if _, ok := v.(string); !ok {
+			return []string{fmt.Sprintf("%v: expected string, got %T", path, v)}
+		}
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			return []string{fmt.Sprintf("%v: expected boolean, got %T", path, v)}
+		}
+	case "number":
+		if _, ok := v.(float64); !ok {
+			return []string{fmt.Sprintf("%v: expected number, got %T", path, v)}
+		}
+	case "integer":
+		if n, ok := v.(float64); !ok || n != math.Trunc(n) {
+			return []string{fmt.Sprintf("%v: expected integer, got %v", path, v)}
+		}
+	}
+
+	return nil
+}
+
+func validateObject(defs map[string]interface{}, s map[string]interface{}, obj map[string]interface{}, path string) []string {
+	var errs []string
+
+	required, _ := s["required"].([]interface{})
+	for _, name := range required {
+		if _, ok := obj[name.(string)]; !ok {
+			errs = append(errs, fmt.Sprintf("%v: missing property %v", path, name))
+		}
+	}
+
+	keys := make([]string, 0, len(obj))
+	for key := range obj {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	properties, _ := s["properties"].(map[string]interface{})
+	for _, key := range keys {
+		if schema, ok := properties[key]; ok {
+			errs = append(errs, validate(defs, schema, obj[key], path+"."+key)...)
+		} else if schema, ok := s["additionalProperties"]; ok {
+			errs = append(errs, validate(defs, schema, obj[key], path+"."+key)...)
+		} else {
+			errs = append(errs, fmt.Sprintf("%v: property %v is not in the schema", path, key))
+		}
+	}
+
+	return errs
+}
@@ -0,0 +1,76 @@
+package healthtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	health "github.com/regiocom/healthchecker"
+)
+
+// Server serves the health endpoints of a Checker for tests. Close it when done.
+type Server struct {
+	*httptest.Server
+
+	checker *health.Checker
+}
+
+// Starts a server serving the health endpoints of the checker.
+//
+// Example:
+//		server := healthtest.NewServer(checker)
+//		defer server.Close()
+//
+//		code, resp, err := server.Ready()
+func NewServer(checker *health.Checker) *Server {
+	m := http.NewServeMux()
+	checker.AppendHealthEndpoints(m)
+
+	return &Server{Server: httptest.NewServer(m), checker: checker}
+}
+
+// Requests the liveness endpoint with all details and returns the status code and the decoded response.
+func (s *Server) Alive() (int, *health.AliveResponse, error) {
+	resp := &health.AliveResponse{}
+	code, err := s.get(s.path(s.checker.AlivePath, "/.well-known/alive"), resp)
+
+	return code, resp, err
+}
+
+// Requests the detailed readiness response with all probes listed and returns the status code and the decoded
+// response.
+func (s *Server) Ready() (int, *health.ReadyResponse, error) {
+	resp := &health.ReadyResponse{}
+	code, err := s.get(s.path(s.checker.ReadyPath, "/.well-known/ready")+"?verbose=1", resp)
+
+	return code, resp, err
+}
+
+func (s *Server) path(path, fallback string) string {
+	if path == "" {
+		return fallback
+	}
+
+	return path
+}
+
+func (s *Server) get(path string, v interface{}) (int, error) {
+	req, err := http.NewRequest(http.MethodGet, s.URL+path, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Accept", health.MediaTypeHealthV2)
+
+	resp, err := s.Client().Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return resp.StatusCode, fmt.Errorf("could not decode response of %v: %w", path, err)
+	}
+
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,74 @@
+package health
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const defaultHeartbeatTimeout = 10 * time.Second
+
+type heartbeatConfig struct {
+	client *http.Client
+}
+
+// A HeartbeatOption configures a HeartbeatListener.
+type HeartbeatOption func(c *heartbeatConfig)
+
+// Sets the http client used to send heartbeats. Defaults to a client with a timeout of 10s.
+func HeartbeatClient(client *http.Client) HeartbeatOption {
+	return func(c *heartbeatConfig) {
+		c.client = client
+	}
+}
+
+// Pings a dead-man's-switch like healthchecks.io or Cronitor after each evaluation of the readiness probes.
+// successURL is requested if the service is ready, failURL with the reasons as body if not. Leave failURL empty
+// to report successful evaluations only. If the process dies or hangs, the pings stop and the external service alerts.
+// Pings are sent asynchronously, failures are logged.
+//
+// Example:
+//		checker.AddListener(health.HeartbeatListener("https://hc-ping.com/<uuid>", "https://hc-ping.com/<uuid>/fail"))
+//		defer checker.EvaluateInBackground(time.Minute)()
+func HeartbeatListener(successURL, failURL string, opts ...HeartbeatOption) Listener {
+	cfg := &heartbeatConfig{
+		client: &http.Client{Timeout: defaultHeartbeatTimeout},
+	}
+
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return func(r Result) {
+		if r.Ready {
+			go sendHeartbeat(r.log(), cfg.client, successURL, "")
+		} else if failURL != "" {
+			go sendHeartbeat(r.log(), cfg.client, failURL, strings.Join(reasonStrings(r.Reasons), "\n"))
+		}
+	}
+}
+
+func sendHeartbeat(logger Logger, client *http.Client, url, body string) {
+	if err := postHeartbeat(client, url, body); err != nil {
+		logger.Printf("failed to send heartbeat: %v", err)
+	}
+}
+
+func postHeartbeat(client *http.Client, url, body string) error {
+	resp, err := client.Post(url, "text/plain", strings.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status code %d from %v", resp.StatusCode, url)
+	}
+
+	return nil
+}
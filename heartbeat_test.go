@@ -0,0 +1,61 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newHeartbeatTestServer() (*httptest.Server, chan string) {
+	pings := make(chan string, 10)
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		pings <- r.URL.Path + " " + string(body)
+	}))
+
+	return s, pings
+}
+
+func receivePing(t *testing.T, pings chan string) string {
+	select {
+	case ping := <-pings:
+		return ping
+	case <-time.After(time.Second):
+		t.Fatal("no heartbeat received")
+		return ""
+	}
+}
+
+func TestHeartbeatListener(t *testing.T) {
+	s, pings := newHeartbeatTestServer()
+	defer s.Close()
+
+	checker := &Checker{}
+	checker.AddListener(HeartbeatListener(s.URL+"/uuid", s.URL+"/uuid/fail"))
+	stop := checker.EvaluateInBackground(time.Hour)
+	defer stop()
+
+	assert.Equal(t, "/uuid ", receivePing(t, pings))
+}
+
+func TestHeartbeatListener_fail(t *testing.T) {
+	s, pings := newHeartbeatTestServer()
+	defer s.Close()
+
+	checker := &Checker{}
+	checker.AddReadinessProbe("redis", func() error {
+		return fmt.Errorf("connection refused")
+	})
+	checker.AddListener(HeartbeatListener(s.URL+"/uuid", s.URL+"/uuid/fail"))
+
+	r := checker.evaluateReadiness(context.Background(), true)
+
+	assert.False(t, r.Ready)
+	assert.Equal(t, "/uuid/fail redis: connection refused", receivePing(t, pings))
+}
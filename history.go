@@ -0,0 +1,71 @@
+package health
+
+import "time"
+
+// History contains the latest results of each probe by name, oldest first. See Checker.HistorySize.
+type History struct {
+	Liveness  map[string][]ProbeResult
+	Readiness map[string][]ProbeResult
+}
+
+// HistoryResponse is the response of the history endpoint.
+type HistoryResponse struct {
+	Liveness  map[string][]HistoryEntry `json:"liveness"`
+	Readiness map[string][]HistoryEntry `json:"readiness"`
+}
+
+// HistoryEntry is a single result of a probe in HistoryResponse.
+type HistoryEntry struct {
+	CheckedAt time.Time `json:"checkedAt"`
+	Healthy   bool      `json:"healthy"`
+	Duration  string    `json:"duration"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// Returns the latest results of all probes, e.g. to find out what was failing before a restart.
+//
+// Example:
+//		for name, results := range checker.History().Liveness {
+//			for _, r := range results {
+//				log.Printf("%v %v: %v", r.CheckedAt, name, r.Err)
+//			}
+//		}
+func (h *Checker) History() History {
+	return History{
+		Liveness:  probeHistory(h.livenessProbes),
+		Readiness: probeHistory(h.readinessProbes),
+	}
+}
+
+func probeHistory(probes map[string]*registeredProbe) map[string][]ProbeResult {
+	history := make(map[string][]ProbeResult, len(probes))
+	for name, probe := range probes {
+		history[name] = probe.recent()
+	}
+
+	return history
+}
+
+func newHistoryResponse(history History) *HistoryResponse {
+	return &HistoryResponse{
+		Liveness:  newHistoryEntries(history.Liveness),
+		Readiness: newHistoryEntries(history.Readiness),
+	}
+}
+
+func newHistoryEntries(history map[string][]ProbeResult) map[string][]HistoryEntry {
+	entries := make(map[string][]HistoryEntry, len(history))
+	for name, results := range history {
+		entries[name] = make([]HistoryEntry, 0, len(results))
+		for _, r := range results {
+			entry := HistoryEntry{CheckedAt: r.CheckedAt, Healthy: r.Err == nil, Duration: r.Duration.String()}
+			if r.Err != nil {
+				entry.Error = r.Err.Error()
+			}
+
+			entries[name] = append(entries[name], entry)
+		}
+	}
+
+	return entries
+}
@@ -0,0 +1,50 @@
+package health
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChecker_History(t *testing.T) {
+	calls := 0
+	checker := &Checker{HistorySize: 2}
+	checker.AddLivenessProbe("worker", func() error {
+		calls++
+		return fmt.Errorf("call %d", calls)
+	})
+
+	for i := 0; i < 3; i++ {
+		_, _ = checker.IsAlive()
+	}
+
+	history := checker.History().Liveness["worker"]
+
+	assert.Len(t, history, 2)
+	assert.EqualError(t, history[0].Err, "call 2")
+	assert.EqualError(t, history[1].Err, "call 3")
+}
+
+func TestChecker_History_endpoint(t *testing.T) {
+	checker := &Checker{}
+	checker.AddReadinessProbe("redis", func() error {
+		return fmt.Errorf("connection refused")
+	})
+	_, _ = checker.IsReady()
+
+	server := httptest.NewServer(checker.serverMux())
+	defer server.Close()
+
+	resp, err := http.Get(fmt.Sprintf("%v/.well-known/history", server.URL))
+	assert.NoError(t, err)
+	assert.EqualValues(t, http.StatusOK, resp.StatusCode)
+
+	var history HistoryResponse
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&history))
+	assert.Len(t, history.Readiness["redis"], 1)
+	assert.Equal(t, "connection refused", history.Readiness["redis"][0].Error)
+}
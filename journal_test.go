@@ -0,0 +1,58 @@
+package health
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJournalListener(t *testing.T) {
+	socket := filepath.Join(os.TempDir(), fmt.Sprintf("healthchecker-journal-%d", os.Getpid()))
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		t.Skipf("unix datagram sockets are not supported: %v", err)
+	}
+	defer os.Remove(socket)
+	defer conn.Close()
+
+	defer func(s string) { journalSocket = s }(journalSocket)
+	journalSocket = socket
+
+	failed := Result{Status: StatusFail, Reasons: []Reason{{Service: "db", Error: "connection refused\nretrying"}}}
+
+	listener := JournalListener("my-service")
+	listener(Result{Ready: true, Status: StatusPass})
+	listener(failed)
+	listener(failed)
+	listener(Result{Ready: true, Status: StatusPass})
+
+	buf := make([]byte, 1024)
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+
+	n, err := conn.Read(buf)
+	assert.NoError(t, err)
+
+	var expected bytes.Buffer
+	expected.WriteString(`MESSAGE=msg="status changed" status=fail previous=pass ready=false reasons="db: connection refused\nretrying"` + "\n")
+	expected.WriteString("PRIORITY=3\nSYSLOG_IDENTIFIER=my-service\nHEALTH_STATUS=fail\nHEALTH_PREVIOUS_STATUS=pass\nHEALTH_READY=false\n")
+	expected.WriteString("HEALTH_REASON\n")
+	_ = binary.Write(&expected, binary.LittleEndian, uint64(len("db: connection refused\nretrying")))
+	expected.WriteString("db: connection refused\nretrying\n")
+	assert.Equal(t, expected.String(), string(buf[:n]))
+
+	n, err = conn.Read(buf)
+	assert.NoError(t, err)
+	assert.Contains(t, string(buf[:n]), "PRIORITY=5\n")
+	assert.Contains(t, string(buf[:n]), "HEALTH_STATUS=pass\nHEALTH_PREVIOUS_STATUS=fail\nHEALTH_READY=true\n")
+
+	_ = conn.SetReadDeadline(time.Now().Add(50 * time.Millisecond))
+	_, err = conn.Read(buf)
+	assert.Error(t, err, "no record without a transition")
+}
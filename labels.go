@@ -0,0 +1,111 @@
+package health
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Attaches key/value labels to the probe, e.g. the owning team, the tier or the type of the dependency. Labels are
+// part of the JSON response, the reasons passed to listeners, the Prometheus metrics and the Sentry events, so
+// failures can be routed and sliced by owner. Calling it multiple times merges the labels. Registering a probe panics
+// if two labels result in the same Prometheus label name, e.g. `dependency-type` and `dependency_type`.
+//
+// Example:
+//		checker.AddReadinessProbe("payments", health.HTTPProbe("http://payments/health", health.HTTPTimeout(time.Second)),
+//			health.Labels(map[string]string{"team": "billing", "tier": "1", "dependency": "http"}))
+func Labels(labels map[string]string) ProbeOption {
+	return func(p *registeredProbe) {
+		if p.labels == nil {
+			p.labels = make(map[string]string, len(labels))
+		}
+
+		for key, value := range labels {
+			p.labels[key] = value
+		}
+	}
+}
+
+var invalidMetricLabelChars = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+// Labels written by the metrics themselves, which probe labels must not override
+var reservedMetricLabels = map[string]bool{"probe": true, "critical": true, "status": true}
+
+// Returns the Prometheus label name of a probe label, false if it is skipped as it is empty or reserved.
+// Invalid characters are replaced by underscores.
+func metricLabelName(name string) (string, bool) {
+	metricName := invalidMetricLabelChars.ReplaceAllString(name, "_")
+	if metricName == "" || reservedMetricLabels[metricName] || strings.HasPrefix(metricName, "__") {
+		return "", false
+	}
+	if metricName[0] >= '0' && metricName[0] <= '9' {
+		metricName = "_" + metricName
+	}
+
+	return metricName, true
+}
+
+// Returns an error if two labels result in the same Prometheus label name, which would make the series invalid.
+func checkMetricLabels(labels map[string]string) error {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	seen := make(map[string]string, len(names))
+	for _, name := range names {
+		metricName, ok := metricLabelName(name)
+		if !ok {
+			continue
+		}
+
+		if other, exists := seen[metricName]; exists {
+			return fmt.Errorf("labels %q and %q are both exported as metric label %q", other, name, metricName)
+		}
+		seen[metricName] = name
+	}
+
+	return nil
+}
+
+// Returns a copy of the labels, so the labels of a probe can not be changed through a result.
+func copyLabels(labels map[string]string) map[string]string {
+	if labels == nil {
+		return nil
+	}
+
+	c := make(map[string]string, len(labels))
+	for key, value := range labels {
+		c[key] = value
+	}
+
+	return c
+}
+
+// Formats the labels as additional Prometheus label pairs, e.g. `,team="billing",tier="1"`, ordered by name.
+// Invalid characters in names are replaced by underscores, reserved names are skipped.
+func formatMetricLabels(labels map[string]string) string {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		metricName, ok := metricLabelName(name)
+		if !ok {
+			continue
+		}
+
+		b.WriteString(",")
+		b.WriteString(metricName)
+		b.WriteString(`="`)
+		b.WriteString(escapeLabel(labels[name]))
+		b.WriteString(`"`)
+	}
+
+	return b.String()
+}
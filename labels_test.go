@@ -0,0 +1,60 @@
+package health
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLabels(t *testing.T) {
+	checker := &Checker{}
+	checker.AddReadinessProbe("payments", func() error { return fmt.Errorf("connection refused") },
+		Labels(map[string]string{"team": "billing"}), Labels(map[string]string{"tier": "1"}))
+
+	r := checker.readiness(context.Background(), nil)
+	labels := map[string]string{"team": "billing", "tier": "1"}
+	assert.Equal(t, labels, r.Probes[0].Labels)
+	assert.Equal(t, labels, r.Reasons[0].Labels)
+
+	rec := httptest.NewRecorder()
+	checker.serverMux().ServeHTTP(rec, detailedRequest("/.well-known/ready"))
+
+	var body struct {
+		Probes []ProbeStatus `json:"probes"`
+	}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, labels, body.Probes[0].Labels)
+	assert.Equal(t, labels, checker.Probes()[0].Labels)
+
+	// Results and infos do not expose the labels of the probe
+	r.Probes[0].Labels["team"] = "changed"
+	checker.Probes()[0].Labels["team"] = "changed"
+	assert.Equal(t, labels, checker.readiness(context.Background(), nil).Probes[0].Labels)
+}
+
+func TestLabels_collision(t *testing.T) {
+	checker := &Checker{}
+
+	assert.PanicsWithValue(t, `a health probe should have distinct labels: labels "a-b" and "a_b" are both exported as metric label "a_b"`, func() {
+		checker.AddReadinessProbe("payments", func() error { return nil }, Labels(map[string]string{"a-b": "1", "a_b": "2"}))
+	})
+}
+
+func TestWritePrometheus_labels(t *testing.T) {
+	var b bytes.Buffer
+	assert.NoError(t, WritePrometheus(&b, Result{Probes: []ProbeResult{{
+		Name:     "payments",
+		Critical: true,
+		Status:   StatusPass,
+		Labels:   map[string]string{"team": "billing", "dependency-type": "http", "probe": "ignored"},
+	}}}))
+
+	assert.Contains(t, b.String(), `health_probe_up{probe="payments",critical="true",dependency_type="http",team="billing"} 1`)
+	assert.Contains(t, b.String(), `health_probe_status{probe="payments",status="pass",dependency_type="http",team="billing"} 1`)
+	assert.Contains(t, b.String(), `health_probe_duration_seconds{probe="payments"} 0`)
+}
@@ -0,0 +1,94 @@
+package health
+
+import (
+	"fmt"
+	"time"
+)
+
+// A MaintenanceWindow tells whether a point in time is within planned downtime of a dependency, see Maintenance.
+type MaintenanceWindow func(t time.Time) bool
+
+// Returns a window from start to end, e.g. for a single announced downtime.
+func Between(start, end time.Time) MaintenanceWindow {
+	return func(t time.Time) bool {
+		return !t.Before(start) && t.Before(end)
+	}
+}
+
+// Returns a window recurring each day at the time of day given as `15:04` in loc for d, e.g. for nightly database
+// maintenance. Restrict it to certain weekdays by passing them. Fails if at is no valid time of day.
+//
+// Example:
+//		window, err := health.Recurring("02:00", time.Hour, time.UTC, time.Saturday, time.Sunday)
+func Recurring(at string, d time.Duration, loc *time.Location, days ...time.Weekday) (MaintenanceWindow, error) {
+	start, err := time.Parse("15:04", at)
+	if err != nil {
+		return nil, fmt.Errorf("invalid time of day %q: %w", at, err)
+	}
+
+	if loc == nil {
+		loc = time.Local
+	}
+
+	offset := time.Duration(start.Hour())*time.Hour + time.Duration(start.Minute())*time.Minute
+	onDay := func(day time.Weekday) bool {
+		if len(days) == 0 {
+			return true
+		}
+
+		for _, d := range days {
+			if d == day {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	return func(t time.Time) bool {
+		t = t.In(loc)
+
+		// Check the window starting today and the ones starting on previous days, which may last until now
+		for back := 0; time.Duration(back)*24*time.Hour < d+24*time.Hour; back++ {
+			y, m, day := t.AddDate(0, 0, -back).Date()
+			windowStart := time.Date(y, m, day, 0, 0, 0, 0, loc).Add(offset)
+			if onDay(windowStart.Weekday()) && !t.Before(windowStart) && t.Before(windowStart.Add(d)) {
+				return true
+			}
+		}
+
+		return false
+	}, nil
+}
+
+// Treats the probe as non-critical during the window, so planned downtime of a dependency is reported as degraded
+// without draining the service or paging anyone. With skip set, the probe is not run during the window and reported
+// as passing. Results during the window are marked as maintenance. Can be given multiple times.
+//
+// Example:
+//		nightly, _ := health.Recurring("02:00", 30*time.Minute, time.UTC)
+//		checker.AddReadinessProbe("database", health.SQLProbe(db), health.Maintenance(nightly, false))
+func Maintenance(window MaintenanceWindow, skip bool) ProbeOption {
+	return func(p *registeredProbe) {
+		p.maintenance = append(p.maintenance, maintenanceWindow{window: window, skip: skip})
+	}
+}
+
+type maintenanceWindow struct {
+	window MaintenanceWindow
+	skip   bool
+}
+
+// Returns the settings of the probe at t including its maintenance windows.
+func (p *registeredProbe) settingsAt(t time.Time) probeSettings {
+	settings := p.settings()
+	for _, m := range p.maintenance {
+		if m.window(t) {
+			settings.maintenance = true
+			settings.critical = false
+			settings.skipped = settings.skipped || m.skip
+		}
+	}
+
+	return settings
+}
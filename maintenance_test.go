@@ -0,0 +1,106 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecurring(t *testing.T) {
+	window, err := Recurring("23:30", time.Hour, time.UTC, time.Saturday)
+	assert.NoError(t, err)
+
+	// 2024-03-02 is a Saturday
+	assert.True(t, window(time.Date(2024, 3, 2, 23, 30, 0, 0, time.UTC)))
+	assert.True(t, window(time.Date(2024, 3, 3, 0, 15, 0, 0, time.UTC)))
+	assert.False(t, window(time.Date(2024, 3, 3, 0, 30, 0, 0, time.UTC)))
+	assert.False(t, window(time.Date(2024, 3, 2, 23, 29, 0, 0, time.UTC)))
+	assert.False(t, window(time.Date(2024, 3, 3, 23, 45, 0, 0, time.UTC)))
+
+	_, err = Recurring("25:00", time.Hour, time.UTC)
+	assert.Error(t, err)
+}
+
+func TestMaintenance(t *testing.T) {
+	now := time.Now()
+	active := Between(now.Add(-time.Hour), now.Add(time.Hour))
+	inactive := Between(now.Add(time.Hour), now.Add(2*time.Hour))
+
+	ran := false
+	checker := &Checker{}
+	checker.AddReadinessProbe("database", func() error { return errors.New("maintenance") }, Maintenance(active, false))
+	checker.AddReadinessProbe("cache", func() error { ran = true; return errors.New("down") }, Maintenance(active, true))
+	checker.AddReadinessProbe("queue", func() error { return nil }, Maintenance(inactive, true))
+
+	r := checker.readiness(context.Background(), nil)
+	assert.True(t, r.Ready)
+	assert.Equal(t, StatusWarn, r.Status)
+	assert.False(t, ran)
+
+	assert.Equal(t, "cache", r.Probes[0].Name)
+	assert.True(t, r.Probes[0].Maintenance)
+	assert.Equal(t, StatusPass, r.Probes[0].Status)
+	assert.False(t, r.Probes[0].Disabled)
+
+	assert.Equal(t, "database", r.Probes[1].Name)
+	assert.True(t, r.Probes[1].Maintenance)
+	assert.False(t, r.Probes[1].Critical)
+	assert.Equal(t, StatusWarn, r.Probes[1].Status)
+
+	assert.Equal(t, "queue", r.Probes[2].Name)
+	assert.False(t, r.Probes[2].Maintenance)
+	assert.True(t, r.Probes[2].Critical)
+}
+
+func TestMaintenance_scheduled(t *testing.T) {
+	var active int32
+	window := func(time.Time) bool { return atomic.LoadInt32(&active) == 1 }
+	evaluated := make(chan Result, 1)
+
+	checker := &Checker{}
+	checker.AddReadinessProbe("database", func() error { return errors.New("maintenance") },
+		Interval(time.Hour), Maintenance(window, false))
+	checker.AddListener(func(r Result) {
+		select {
+		case evaluated <- r:
+		default:
+		}
+	})
+
+	defer checker.EvaluateInBackground(10 * time.Millisecond)()
+	r := <-evaluated
+	assert.Equal(t, StatusFail, r.Status)
+
+	atomic.StoreInt32(&active, 1)
+	<-evaluated
+	r = <-evaluated
+	assert.True(t, r.Ready, "the scheduled result is non-critical within the window")
+	assert.Equal(t, StatusWarn, r.Probes[0].Status)
+	assert.False(t, r.Probes[0].Critical)
+	assert.True(t, r.Probes[0].Maintenance)
+}
+
+func TestMaintenanceConfig_build(t *testing.T) {
+	for _, mc := range []MaintenanceConfig{
+		{Start: "2024-03-01T22:00:00Z", End: "2024-03-01T23:00:00Z"},
+		{At: "02:00", Duration: "1h", Days: []string{"Sunday"}, TimeZone: "UTC", Skip: true},
+	} {
+		_, err := mc.build()
+		assert.NoError(t, err)
+	}
+
+	for _, mc := range []MaintenanceConfig{
+		{Start: "2024-03-01T22:00:00Z"},
+		{At: "02:00"},
+		{At: "2am", Duration: "1h"},
+		{At: "02:00", Duration: "1h", Days: []string{"someday"}},
+		{At: "02:00", Duration: "1h", TimeZone: "Nowhere/Atlantis"},
+	} {
+		_, err := mc.build()
+		assert.Error(t, err)
+	}
+}
@@ -0,0 +1,31 @@
+package health
+
+import "os"
+
+// Environment variables commonly set via the Kubernetes downward API and their metadata keys
+var metadataEnv = map[string]string{
+	"POD_NAME":      "pod",
+	"POD_NAMESPACE": "namespace",
+	"NODE_NAME":     "node",
+}
+
+// Returns the hostname and, if set, the pod name, namespace and node name given by the environment variables
+// POD_NAME, POD_NAMESPACE and NODE_NAME. Add custom keys like the zone as needed.
+//
+// Example:
+//		checker := &health.Checker{Metadata: health.InstanceMetadata()}
+//		checker.Metadata["zone"] = os.Getenv("ZONE")
+func InstanceMetadata() map[string]string {
+	metadata := map[string]string{}
+	if hostname, err := os.Hostname(); err == nil {
+		metadata["hostname"] = hostname
+	}
+
+	for env, key := range metadataEnv {
+		if v := os.Getenv(env); v != "" {
+			metadata[key] = v
+		}
+	}
+
+	return metadata
+}
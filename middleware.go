@@ -0,0 +1,70 @@
+package health
+
+import "time"
+
+// A ProbeMiddleware wraps a probe, so cross-cutting concerns like logging, metrics, tracing or retries are attached
+// once for all probes instead of wrapping each probe by hand. See Checker.ProbeMiddlewares.
+//
+// Example:
+//		checker.ProbeMiddlewares = append(checker.ProbeMiddlewares, func(next health.Probe) health.Probe {
+//			return func() error {
+//				start := time.Now()
+//				err := next()
+//				probeDuration.Observe(time.Since(start).Seconds())
+//				return err
+//			}
+//		})
+type ProbeMiddleware func(next Probe) Probe
+
+// Returns the probe wrapped by the middlewares, the first one being the outermost.
+func chainProbe(probe Probe, middlewares []ProbeMiddleware) Probe {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		probe = middlewares[i](probe)
+	}
+
+	return probe
+}
+
+// A RetryOption configures Retry.
+type RetryOption func(c *retryConfig)
+
+type retryConfig struct {
+	clock Clock
+}
+
+// Sets the clock timing the waits between the attempts, e.g. the fake clock of the checker in tests. Defaults to the
+// real time.
+func RetryClock(clock Clock) RetryOption {
+	return func(c *retryConfig) {
+		c.clock = clock
+	}
+}
+
+// Retries a failing probe up to the given number of attempts in total, waiting between the attempts, so a single
+// dropped connection does not fail the probe. Returns the error of the last attempt.
+//
+// Example:
+//		checker.ProbeMiddlewares = []health.ProbeMiddleware{health.Retry(3, 100*time.Millisecond)}
+func Retry(attempts int, wait time.Duration, opts ...RetryOption) ProbeMiddleware {
+	c := &retryConfig{clock: realClock{}}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return func(next Probe) Probe {
+		return func() error {
+			var err error
+			for i := 0; i < attempts || i == 0; i++ {
+				if i > 0 {
+					<-c.clock.After(wait)
+				}
+
+				if err = next(); err == nil {
+					return nil
+				}
+			}
+
+			return err
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChecker_ProbeMiddlewares(t *testing.T) {
+	var calls []string
+	record := func(name string) ProbeMiddleware {
+		return func(next Probe) Probe {
+			return func() error {
+				calls = append(calls, name)
+				return next()
+			}
+		}
+	}
+
+	checker := &Checker{ProbeMiddlewares: []ProbeMiddleware{record("outer"), record("inner")}}
+	checker.AddReadinessProbe("database", func() error {
+		calls = append(calls, "probe")
+		return nil
+	})
+
+	assert.True(t, checker.readiness(context.Background(), nil).Ready)
+	assert.Equal(t, []string{"outer", "inner", "probe"}, calls)
+}
+
+func TestRetry(t *testing.T) {
+	attempts := 0
+	flaky := func() error {
+		attempts++
+		if attempts < 3 {
+			return fmt.Errorf("connection reset")
+		}
+		return nil
+	}
+
+	assert.NoError(t, Retry(3, time.Millisecond)(flaky)())
+	assert.Equal(t, 3, attempts)
+
+	attempts = 0
+	assert.EqualError(t, Retry(2, time.Millisecond)(flaky)(), "connection reset")
+	assert.Equal(t, 2, attempts)
+}
+
+// Clock firing all timers immediately and recording their durations
+type instantClock struct {
+	realClock
+	waits []time.Duration
+}
+
+func (c *instantClock) After(d time.Duration) <-chan time.Time {
+	c.waits = append(c.waits, d)
+
+	ch := make(chan time.Time, 1)
+	ch <- c.Now()
+	return ch
+}
+
+func TestRetry_clock(t *testing.T) {
+	clock := &instantClock{}
+	failing := func() error {
+		return fmt.Errorf("connection reset")
+	}
+
+	assert.EqualError(t, Retry(3, time.Hour, RetryClock(clock))(failing)(), "connection reset")
+	assert.Equal(t, []time.Duration{time.Hour, time.Hour}, clock.waits)
+}
@@ -0,0 +1,240 @@
+package health
+
+import "net/http"
+
+const (
+	openAPIPath = "/.well-known/openapi.json"
+	// Version of the health API described by the OpenAPI document. Incremented on changes of the response schemas.
+	HealthAPIVersion = "1.3.0"
+)
+
+type jsonObject = map[string]interface{}
+
+// Returns an OpenAPI 3 document describing the health endpoints of the checker and their response schemas, so API
+// gateways and client generators can consume them. Reflects the configured paths.
+//
+// Example:
+//		b, _ := json.MarshalIndent(checker.OpenAPISpec(), "", "  ")
+//		_ = ioutil.WriteFile("health.openapi.json", b, 0644)
+func (h *Checker) OpenAPISpec() map[string]interface{} {
+	filters := []interface{}{
+		queryParameter("exclude", "Name of a probe not to evaluate, can be repeated", true),
+		queryParameter("include", "Name of a probe to evaluate exclusively, can be repeated", true),
+	}
+	flags := []interface{}{
+		queryParameter("brief", "Omits everything but the state", false),
+		queryParameter("quiet", "Responds with the status code only", false),
+	}
+
+	return jsonObject{
+		"openapi": "3.0.3",
+		"info": jsonObject{
+			"title":   "Health API",
+			"version": HealthAPIVersion,
+		},
+		"paths": jsonObject{
+			h.alivePath(): jsonObject{
+				"get": operation("alive", "Evaluates the liveness probes", "AliveResponse", append(filters, flags...)),
+			},
+			h.readyPath(): jsonObject{
+				"get": versionedOperation("ready", "Evaluates the readiness probes", "ReadyResponseV1", "ReadyResponse", append(append(filters, flags...),
+					queryParameter("verbose", "Lists passing probes as well", false))),
+			},
+			h.readyPath() + "/{probe}": jsonObject{
+				"get": operation("probe", "Evaluates a single readiness probe", "ProbeStatus", []interface{}{jsonObject{
+					"name":     "probe",
+					"in":       "path",
+					"required": true,
+					"schema":   jsonObject{"type": "string"},
+				}}),
+			},
+			h.historyPath(): jsonObject{
+				"get": operation("history", "Lists the latest results of each probe", "HistoryResponse", nil),
+			},
+			h.dependenciesPath(): jsonObject{
+				"get": operation("dependencies", "Lists the probes as dependencies without running them", "DependenciesResponse", nil),
+			},
+			h.statsPath(): jsonObject{
+				"get": operation("stats", "Reports the availability of the service and its probes in percent", "StatsResponse", nil),
+			},
+		},
+		"components": jsonObject{
+			"schemas": responseSchemas(),
+		},
+	}
+}
+
+// Returns the schemas of the responses by name, referencing each other by ref.
+func responseSchemas() jsonObject {
+	return jsonObject{
+		"Status": jsonObject{
+			"type": "string",
+			"enum": []Status{StatusPass, StatusWarn, StatusFail, StatusStarting, StatusStandby},
+		},
+		"Reason": objectSchema([]string{"service", "error", "kind"}, jsonObject{
+			"service": stringSchema(),
+			"error":   stringSchema(),
+			"kind": jsonObject{
+				"type": "string",
+				"enum": []ReasonKind{KindError, KindTimeout, KindUnreachable, KindUnauthorized, KindUnhealthy, KindStandby},
+			},
+			"labels": labelsSchema(),
+		}),
+		"AliveResponse": objectSchema([]string{"alive"}, jsonObject{
+			"alive":   boolSchema(),
+			"status":  ref("Status"),
+			"reasons": arrayOf(ref("Reason")),
+		}),
+		"ReadyResponseV1": objectSchema([]string{"ready"}, jsonObject{
+			"ready":     boolSchema(),
+			"status":    ref("Status"),
+			"reasons":   arrayOf(stringSchema()),
+			"checkedAt": jsonObject{"type": "string", "format": "date-time"},
+			"duration":  stringSchema(),
+			"probes":    arrayOf(ref("ProbeStatus")),
+			"peers":     ref("PeerStatus"),
+			"metadata":  jsonObject{"type": "object", "additionalProperties": stringSchema()},
+		}),
+		"ReadyResponse": objectSchema([]string{"ready"}, jsonObject{
+			"ready":     boolSchema(),
+			"status":    ref("Status"),
+			"reasons":   arrayOf(ref("Reason")),
+			"checkedAt": jsonObject{"type": "string", "format": "date-time"},
+			"duration":  stringSchema(),
+			"probes":    arrayOf(ref("ProbeStatus")),
+			"peers":     ref("PeerStatus"),
+			"metadata":  jsonObject{"type": "object", "additionalProperties": stringSchema()},
+		}),
+		"ProbeStatus": objectSchema([]string{"name", "critical", "healthy", "status", "duration"}, jsonObject{
+			"name":     stringSchema(),
+			"critical": boolSchema(),
+			"healthy":  boolSchema(),
+			"status":   ref("Status"),
+			"duration": stringSchema(),
+			"error":    stringSchema(),
+			"kind":     stringSchema(),
+			"flapping": boolSchema(),
+			"disabled": boolSchema(),
+			"labels":   labelsSchema(),
+
+			"maintenance": boolSchema(),
+
+			"failures":      jsonObject{"type": "integer"},
+			"lastError":     stringSchema(),
+			"lastFailureAt": jsonObject{"type": "string", "format": "date-time"},
+		}),
+		"PeerStatus": objectSchema([]string{"ready", "total"}, jsonObject{
+			"ready": jsonObject{"type": "integer"},
+			"total": jsonObject{"type": "integer"},
+			"error": stringSchema(),
+		}),
+		"HistoryEntry": objectSchema([]string{"checkedAt", "healthy", "duration"}, jsonObject{
+			"checkedAt": jsonObject{"type": "string", "format": "date-time"},
+			"healthy":   boolSchema(),
+			"duration":  stringSchema(),
+			"error":     stringSchema(),
+		}),
+		"Dependency": objectSchema([]string{"name", "kind", "critical"}, jsonObject{
+			"name":     stringSchema(),
+			"kind":     jsonObject{"type": "string", "enum": []string{"liveness", "readiness"}},
+			"type":     stringSchema(),
+			"target":   stringSchema(),
+			"critical": boolSchema(),
+			"timeout":  stringSchema(),
+			"labels":   labelsSchema(),
+		}),
+		"DependenciesResponse": objectSchema([]string{"dependencies"}, jsonObject{
+			"dependencies": arrayOf(ref("Dependency")),
+		}),
+		"HistoryResponse": objectSchema([]string{"liveness", "readiness"}, jsonObject{
+			"liveness":  jsonObject{"type": "object", "additionalProperties": arrayOf(ref("HistoryEntry"))},
+			"readiness": jsonObject{"type": "object", "additionalProperties": arrayOf(ref("HistoryEntry"))},
+		}),
+		"WatchResponse": objectSchema([]string{"version", "ready", "status", "probes"}, jsonObject{
+			"version":  stringSchema(),
+			"ready":    boolSchema(),
+			"status":   ref("Status"),
+			"reasons":  arrayOf(ref("Reason")),
+			"probes":   arrayOf(ref("ProbeStatus")),
+			"metadata": jsonObject{"type": "object", "additionalProperties": stringSchema()},
+		}),
+		"StatsEntry": objectSchema([]string{}, jsonObject{
+			"1h":  jsonObject{"type": "number"},
+			"24h": jsonObject{"type": "number"},
+			"7d":  jsonObject{"type": "number"},
+		}),
+		"StatsResponse": objectSchema([]string{"service", "liveness", "readiness"}, jsonObject{
+			"service":   ref("StatsEntry"),
+			"liveness":  jsonObject{"type": "object", "additionalProperties": ref("StatsEntry")},
+			"readiness": jsonObject{"type": "object", "additionalProperties": ref("StatsEntry")},
+		}),
+	}
+}
+
+// Serves the OpenAPI document of the checker as JSON.
+func (h *Checker) openAPIHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+	_ = JSONEncoder{}.Encode(w, h.OpenAPISpec())
+}
+
+// Describes an endpoint responding with 200 or 503 and the given schema.
+func operation(id, summary, schema string, parameters []interface{}) jsonObject {
+	return versionedOperation(id, summary, schema, schema, parameters)
+}
+
+// Describes an endpoint like operation, responding with the schema v1 by default and v2 if requested.
+func versionedOperation(id, summary, v1, v2 string, parameters []interface{}) jsonObject {
+	content := jsonObject{
+		"application/json": jsonObject{"schema": ref(v1)},
+		MediaTypeHealthV1:  jsonObject{"schema": ref(v1)},
+		MediaTypeHealthV2:  jsonObject{"schema": ref(v2)},
+	}
+
+	op := jsonObject{
+		"operationId": id,
+		"summary":     summary,
+		"responses": jsonObject{
+			"200": jsonObject{"description": "Healthy", "content": content},
+			"503": jsonObject{"description": "Unhealthy", "content": content},
+		},
+	}
+	if len(parameters) > 0 {
+		op["parameters"] = parameters
+	}
+
+	return op
+}
+
+func queryParameter(name, description string, repeated bool) jsonObject {
+	schema := jsonObject{"type": "boolean"}
+	if repeated {
+		schema = arrayOf(stringSchema())
+	}
+
+	return jsonObject{"name": name, "in": "query", "description": description, "schema": schema}
+}
+
+func objectSchema(required []string, properties jsonObject) jsonObject {
+	return jsonObject{"type": "object", "required": required, "properties": properties}
+}
+
+func arrayOf(items jsonObject) jsonObject {
+	return jsonObject{"type": "array", "items": items}
+}
+
+func ref(schema string) jsonObject {
+	return jsonObject{"$ref": "#/components/schemas/" + schema}
+}
+
+func stringSchema() jsonObject {
+	return jsonObject{"type": "string"}
+}
+
+func labelsSchema() jsonObject {
+	return jsonObject{"type": "object", "additionalProperties": stringSchema()}
+}
+
+func boolSchema() jsonObject {
+	return jsonObject{"type": "boolean"}
+}
@@ -0,0 +1,55 @@
+package health
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChecker_OpenAPI(t *testing.T) {
+	checker := &Checker{OpenAPI: true, ReadyPath: "/readyz"}
+
+	server := httptest.NewServer(checker.serverMux())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/.well-known/openapi.json")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+
+	var doc struct {
+		OpenAPI string `json:"openapi"`
+		Info    struct {
+			Version string `json:"version"`
+		} `json:"info"`
+		Paths      map[string]interface{} `json:"paths"`
+		Components struct {
+			Schemas map[string]interface{} `json:"schemas"`
+		} `json:"components"`
+	}
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&doc))
+	assert.Equal(t, "3.0.3", doc.OpenAPI)
+	assert.Equal(t, HealthAPIVersion, doc.Info.Version)
+	assert.Contains(t, doc.Paths, "/readyz")
+	assert.Contains(t, doc.Paths, "/readyz/{probe}")
+	assert.Contains(t, doc.Paths, "/.well-known/alive")
+	assert.Contains(t, doc.Paths, "/.well-known/history")
+
+	b, _ := json.Marshal(checker.OpenAPISpec())
+	for _, m := range regexp.MustCompile(`#/components/schemas/(\w+)`).FindAllStringSubmatch(string(b), -1) {
+		assert.Contains(t, doc.Components.Schemas, m[1], "referenced schema is defined")
+	}
+}
+
+func TestChecker_OpenAPI_disabled(t *testing.T) {
+	server := httptest.NewServer((&Checker{}).serverMux())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/.well-known/openapi.json")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
@@ -0,0 +1,211 @@
+package health
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultOpsgenieURL     = "https://api.opsgenie.com"
+	defaultOpsgenieTimeout = 10 * time.Second
+)
+
+type opsgenieConfig struct {
+	apiURL              string
+	client              *http.Client
+	after               time.Duration
+	criticalPriority    string
+	nonCriticalPriority string
+	tags                []string
+}
+
+// An OpsgenieOption configures an OpsgenieListener.
+type OpsgenieOption func(c *opsgenieConfig)
+
+// Sets the url of the Opsgenie API, e.g. `https://api.eu.opsgenie.com` for the EU instance.
+// Defaults to `https://api.opsgenie.com`.
+func OpsgenieAPIURL(apiURL string) OpsgenieOption {
+	return func(c *opsgenieConfig) {
+		c.apiURL = strings.TrimSuffix(apiURL, "/")
+	}
+}
+
+// Sets the http client used to call the API. Defaults to a client with a timeout of 10s.
+func OpsgenieClient(client *http.Client) OpsgenieOption {
+	return func(c *opsgenieConfig) {
+		c.client = client
+	}
+}
+
+// Only creates an alert once a probe failed for at least d, so short blips don't page anyone.
+func OpsgenieAfter(d time.Duration) OpsgenieOption {
+	return func(c *opsgenieConfig) {
+		c.after = d
+	}
+}
+
+// Sets the priorities of alerts for critical and non-critical probes. Defaults to P2 and P4.
+func OpsgeniePriorities(critical, nonCritical string) OpsgenieOption {
+	return func(c *opsgenieConfig) {
+		c.criticalPriority = critical
+		c.nonCriticalPriority = nonCritical
+	}
+}
+
+// Adds tags to the alerts, e.g. the team. The labels of the probe are added as `key:value` tags as well.
+func OpsgenieTags(tags ...string) OpsgenieOption {
+	return func(c *opsgenieConfig) {
+		c.tags = append(c.tags, tags...)
+	}
+}
+
+// Returns a listener creating an Opsgenie alert when a readiness probe keeps failing and closing it when the probe
+// passes again. Alerts are identified by an alias of the host and the probe, so Opsgenie deduplicates them across
+// evaluations. The priority is chosen by the criticality of the probe. Requests are sent asynchronously, but in order
+// per probe, failures are logged.
+//
+// Example:
+//		checker.AddListener(health.OpsgenieListener(os.Getenv("OPSGENIE_API_KEY"),
+//			health.OpsgenieAfter(5*time.Minute), health.OpsgenieTags("team:billing")))
+func OpsgenieListener(apiKey string, opts ...OpsgenieOption) Listener {
+	cfg := &opsgenieConfig{
+		apiURL:              defaultOpsgenieURL,
+		client:              &http.Client{Timeout: defaultOpsgenieTimeout},
+		criticalPriority:    "P2",
+		nonCriticalPriority: "P4",
+	}
+
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	hostname, _ := os.Hostname()
+	mu := sync.Mutex{}
+	failingSince := map[string]time.Time{}
+	alerted := map[string]bool{}
+	// Requests of an alert are sent in order, so an alert is never closed before it was created
+	queues := map[string]*orderedQueue{}
+
+	return func(r Result) {
+		for _, p := range r.Probes {
+			// Planned downtime pages no one
+			if p.Maintenance {
+				continue
+			}
+
+			// Standby instances are not failing
+			failed := p.Err != nil && p.Status != StatusStandby
+
+			mu.Lock()
+			since, wasFailing := failingSince[p.Name]
+			if failed && !wasFailing {
+				since = p.CheckedAt
+				failingSince[p.Name] = since
+			} else if !failed {
+				delete(failingSince, p.Name)
+			}
+
+			create := failed && !alerted[p.Name] && p.CheckedAt.Sub(since) >= cfg.after
+			closeAlert := !failed && alerted[p.Name]
+			if create {
+				alerted[p.Name] = true
+			} else if closeAlert {
+				delete(alerted, p.Name)
+			}
+
+			queue, ok := queues[p.Name]
+			if !ok {
+				queue = &orderedQueue{}
+				queues[p.Name] = queue
+			}
+			mu.Unlock()
+
+			logger := r.log()
+			alias := fmt.Sprintf("healthchecker-%v-%v", hostname, p.Name)
+			switch {
+			case create:
+				alert := cfg.alert(hostname, alias, p)
+				queue.add(func() { cfg.send(logger, apiKey, "/v2/alerts", alert) })
+			case closeAlert:
+				closePath := "/v2/alerts/" + url.PathEscape(alias) + "/close?identifierType=alias"
+				note := map[string]interface{}{
+					"source": hostname,
+					"note":   fmt.Sprintf("probe %v passes again", p.Name),
+				}
+				queue.add(func() { cfg.send(logger, apiKey, closePath, note) })
+			}
+		}
+	}
+}
+
+// Returns the request creating an alert for the failing probe.
+func (c *opsgenieConfig) alert(hostname, alias string, p ProbeResult) map[string]interface{} {
+	priority := c.criticalPriority
+	if !p.Critical {
+		priority = c.nonCriticalPriority
+	}
+
+	tags := append([]string{"healthchecker"}, c.tags...)
+	keys := make([]string, 0, len(p.Labels))
+	for key := range p.Labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		tags = append(tags, key+":"+p.Labels[key])
+	}
+
+	return map[string]interface{}{
+		"message":     fmt.Sprintf("probe %v of %v failed", p.Name, hostname),
+		"alias":       alias,
+		"description": p.Err.Error(),
+		"priority":    priority,
+		"source":      hostname,
+		"tags":        tags,
+		"details": map[string]string{
+			"probe":    p.Name,
+			"critical": fmt.Sprint(p.Critical),
+			"kind":     string(reasonKindOf(p.Err)),
+			"duration": p.Duration.String(),
+		},
+	}
+}
+
+// Posts the request to the API and logs failures.
+func (c *opsgenieConfig) send(logger Logger, apiKey, path string, payload map[string]interface{}) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		logger.Printf("failed to encode opsgenie request: %v", err)
+		return
+	}
+
+	req, err := http.NewRequest(http.MethodPost, c.apiURL+path, bytes.NewReader(body))
+	if err != nil {
+		logger.Printf("failed to create opsgenie request: %v", err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "GenieKey "+apiKey)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		logger.Printf("failed to send opsgenie request: %v", err)
+		return
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		logger.Printf("opsgenie responded with %v", resp.Status)
+	}
+}
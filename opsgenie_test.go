@@ -0,0 +1,100 @@
+package health
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type opsgenieRequest struct {
+	path string
+	body map[string]interface{}
+}
+
+func TestOpsgenieListener(t *testing.T) {
+	requests := make(chan opsgenieRequest, 10)
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "GenieKey secret", r.Header.Get("Authorization"))
+
+		var body map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		requests <- opsgenieRequest{path: r.URL.RequestURI(), body: body}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer s.Close()
+
+	listener := OpsgenieListener("secret", OpsgenieAPIURL(s.URL+"/"), OpsgenieAfter(time.Minute), OpsgenieTags("team:billing"))
+	receive := func() opsgenieRequest {
+		select {
+		case req := <-requests:
+			return req
+		case <-time.After(2 * time.Second):
+			t.Fatal("no request received")
+			return opsgenieRequest{}
+		}
+	}
+
+	start := time.Now()
+	failing := func(at time.Time) Result {
+		return Result{Probes: []ProbeResult{{
+			Name: "database", Critical: true, Status: StatusFail, Err: errors.New("connection refused"), CheckedAt: at,
+			Labels: map[string]string{"tier": "1"},
+		}}}
+	}
+
+	listener(failing(start))
+	listener(failing(start.Add(time.Minute)))
+	listener(failing(start.Add(2 * time.Minute)))
+
+	req := receive()
+	assert.Equal(t, "/v2/alerts", req.path)
+	assert.Equal(t, "P2", req.body["priority"])
+	assert.Equal(t, "connection refused", req.body["description"])
+	assert.Equal(t, []interface{}{"healthchecker", "team:billing", "tier:1"}, req.body["tags"])
+	alias := req.body["alias"].(string)
+
+	listener(Result{Probes: []ProbeResult{{Name: "database", Critical: true, Status: StatusPass, CheckedAt: start.Add(3 * time.Minute)}}})
+
+	req = receive()
+	assert.Equal(t, "/v2/alerts/"+alias+"/close?identifierType=alias", req.path)
+
+	for i := 4; i < 7; i++ {
+		maintenance := failing(start.Add(time.Duration(i) * time.Minute))
+		maintenance.Probes[0].Maintenance = true
+		listener(maintenance)
+	}
+
+	select {
+	case req := <-requests:
+		t.Fatalf("unexpected request: %v", req.path)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestOpsgenieListener_ordered(t *testing.T) {
+	release := make(chan struct{})
+	paths := make(chan string, 10)
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The alert is created slowly
+		if r.URL.Path == "/v2/alerts" {
+			<-release
+		}
+		paths <- r.URL.Path
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer s.Close()
+
+	listener := OpsgenieListener("secret", OpsgenieAPIURL(s.URL))
+	now := time.Now()
+	listener(Result{Probes: []ProbeResult{{Name: "database", Critical: true, Status: StatusFail, Err: errors.New("down"), CheckedAt: now}}})
+	listener(Result{Probes: []ProbeResult{{Name: "database", Critical: true, Status: StatusPass, CheckedAt: now.Add(time.Second)}}})
+	close(release)
+
+	assert.Equal(t, "/v2/alerts", <-paths)
+	assert.Contains(t, <-paths, "/close")
+}
@@ -0,0 +1,174 @@
+package health
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	defaultPeerTimeout = 2 * time.Second
+	defaultPeerMaxAge  = 10 * time.Second
+)
+
+// PeerConfig configures how a Checker finds the sibling instances of a horizontally scaled service. The readiness
+// response then tells how many peers are ready, which distinguishes a single bad instance from an outage of a
+// shared dependency.
+type PeerConfig struct {
+	// Readiness endpoints of the peers, e.g. `http://10.0.0.2:8080/.well-known/ready`.
+	URLs []string `json:"urls" yaml:"urls"`
+	// Name resolving to the addresses of all instances, e.g. a headless Kubernetes service. Each address is queried
+	// at Port and the ReadyPath of the checker, the own addresses are skipped.
+	DNSName string `json:"dnsName" yaml:"dnsName"`
+	// Port of the health endpoints of the instances found by DNSName.
+	Port int `json:"port" yaml:"port"`
+	// Client used to query the peers. Defaults to a client with a timeout of 2s.
+	Client *http.Client `json:"-" yaml:"-"`
+	// Time the status of the peers is reused before they are queried again. Defaults to 10s.
+	MaxAge time.Duration `json:"-" yaml:"-"`
+}
+
+// PeerStatus is the number of ready peers, reported in ReadyResponse.
+type PeerStatus struct {
+	Ready int    `json:"ready"`
+	Total int    `json:"total"`
+	Error string `json:"error,omitempty"`
+}
+
+// Returns the latest status of the peers, nil if Peers is not set or they were not queried yet. The peers are
+// queried in background once the status is older than MaxAge, so requests never wait for the slowest peer.
+func (h *Checker) cachedPeerStatus() *PeerStatus {
+	if h.Peers == nil {
+		return nil
+	}
+
+	maxAge := h.Peers.MaxAge
+	if maxAge <= 0 {
+		maxAge = defaultPeerMaxAge
+	}
+
+	h.peersMu.Lock()
+	defer h.peersMu.Unlock()
+
+	if !h.peersQuerying && (h.peers == nil || h.clock().Now().Sub(h.peersAt) >= maxAge) {
+		h.peersQuerying = true
+		go func() {
+			status := h.peerStatus(context.Background())
+
+			h.peersMu.Lock()
+			h.peers, h.peersAt, h.peersQuerying = status, h.clock().Now(), false
+			h.peersMu.Unlock()
+		}()
+	}
+
+	if h.peers == nil {
+		return nil
+	}
+
+	status := *h.peers
+	return &status
+}
+
+// Queries the readiness of all peers. Peers are asked for the status code only, so they do not query their peers.
+func (h *Checker) peerStatus(ctx context.Context) *PeerStatus {
+	urls, err := h.peerURLs(ctx)
+	if err != nil {
+		return &PeerStatus{Error: err.Error()}
+	}
+
+	client := h.Peers.Client
+	if client == nil {
+		client = &http.Client{Timeout: defaultPeerTimeout}
+	}
+
+	status := &PeerStatus{Total: len(urls)}
+	mu := sync.Mutex{}
+	wg := sync.WaitGroup{}
+	for _, u := range urls {
+		u := u
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			if peerReady(ctx, client, u) {
+				mu.Lock()
+				status.Ready++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	return status
+}
+
+// Returns the readiness endpoints of the configured peers and of the instances found by DNS.
+func (h *Checker) peerURLs(ctx context.Context) ([]string, error) {
+	urls := append([]string{}, h.Peers.URLs...)
+	if h.Peers.DNSName == "" {
+		return urls, nil
+	}
+
+	addrs, err := net.DefaultResolver.LookupHost(ctx, h.Peers.DNSName)
+	if err != nil {
+		return nil, err
+	}
+
+	own := map[string]bool{}
+	if ifaceAddrs, err := net.InterfaceAddrs(); err == nil {
+		for _, a := range ifaceAddrs {
+			if ipNet, ok := a.(*net.IPNet); ok {
+				own[ipNet.IP.String()] = true
+			}
+		}
+	}
+
+	for _, addr := range addrs {
+		if own[addr] {
+			continue
+		}
+
+		u := url.URL{
+			Scheme: "http",
+			Host:   net.JoinHostPort(addr, strconv.Itoa(h.Peers.Port)),
+			Path:   h.readyPath(),
+		}
+		urls = append(urls, u.String())
+	}
+
+	return urls, nil
+}
+
+// Returns true if the peer responds with 2xx.
+func peerReady(ctx context.Context, client *http.Client, endpoint string) bool {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return false
+	}
+
+	query := u.Query()
+	query.Set("brief", "1")
+	query.Set("quiet", "1")
+	u.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return false
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return false
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+
+	return resp.StatusCode >= 200 && resp.StatusCode < 300
+}
@@ -0,0 +1,78 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChecker_Peers(t *testing.T) {
+	healthy := &Checker{}
+	healthyServer := httptest.NewServer(healthy.serverMux())
+	defer healthyServer.Close()
+
+	broken := &Checker{}
+	broken.AddReadinessProbe("db", func() error {
+		return fmt.Errorf("connection refused")
+	})
+	brokenServer := httptest.NewServer(broken.serverMux())
+	defer brokenServer.Close()
+
+	checker := &Checker{Peers: &PeerConfig{URLs: []string{
+		healthyServer.URL + "/.well-known/ready",
+		brokenServer.URL + "/.well-known/ready",
+	}}}
+	server := httptest.NewServer(checker.serverMux())
+	defer server.Close()
+
+	peers := func() *PeerStatus {
+		resp, err := http.DefaultClient.Do(detailedRequest(fmt.Sprintf("%v/.well-known/ready", server.URL)))
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+
+		var body ReadyResponse
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		return body.Peers
+	}
+
+	assert.Nil(t, peers(), "queried in background")
+	assert.Eventually(t, func() bool {
+		return assert.ObjectsAreEqual(&PeerStatus{Ready: 1, Total: 2}, peers())
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestChecker_Peers_slow(t *testing.T) {
+	release := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer slow.Close()
+	defer close(release)
+
+	checker := &Checker{Peers: &PeerConfig{URLs: []string{slow.URL}}}
+	handler := checker.serverMux()
+
+	for i := 0; i < 3; i++ {
+		start := time.Now()
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/.well-known/ready", nil))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Less(t, int64(time.Since(start)), int64(100*time.Millisecond), "requests do not wait for peers")
+	}
+}
+
+func TestChecker_peerURLs(t *testing.T) {
+	checker := &Checker{Peers: &PeerConfig{DNSName: "localhost", Port: 8080}}
+
+	urls, err := checker.peerURLs(context.Background())
+
+	assert.NoError(t, err)
+	assert.Empty(t, urls, "own addresses are skipped")
+}
@@ -0,0 +1,22 @@
+package health
+
+import (
+	"net/http"
+	"net/http/pprof"
+)
+
+const pprofPath = "/debug/pprof/"
+
+// Serves the handlers of net/http/pprof at `/debug/pprof/`, e.g. `/debug/pprof/goroutine?debug=2`,
+// `/debug/pprof/profile?seconds=10` or `/debug/pprof/trace?seconds=5`. Works with `go tool pprof`.
+// Note that importing net/http/pprof registers the same handlers at http.DefaultServeMux, so don't serve it publicly.
+func pprofHandler() http.Handler {
+	m := http.NewServeMux()
+	m.HandleFunc(pprofPath, pprof.Index)
+	m.HandleFunc(pprofPath+"cmdline", pprof.Cmdline)
+	m.HandleFunc(pprofPath+"profile", pprof.Profile)
+	m.HandleFunc(pprofPath+"symbol", pprof.Symbol)
+	m.HandleFunc(pprofPath+"trace", pprof.Trace)
+
+	return m
+}
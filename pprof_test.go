@@ -0,0 +1,49 @@
+package health
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChecker_Pprof(t *testing.T) {
+	checker := &Checker{Pprof: true}
+	server := httptest.NewServer(checker.serverMux())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/debug/pprof/")
+	assert.NoError(t, err)
+	body, _ := ioutil.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	assert.Contains(t, string(body), "goroutine?debug=1")
+
+	resp, err = http.Get(server.URL + "/debug/pprof/goroutine?debug=1")
+	assert.NoError(t, err)
+	body, _ = ioutil.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	assert.Contains(t, string(body), "goroutine profile:")
+
+	resp, err = http.Get(server.URL + "/debug/pprof/cmdline")
+	assert.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.EqualValues(t, http.StatusOK, resp.StatusCode)
+
+	resp, err = http.Get(server.URL + "/debug/pprof/unknown")
+	assert.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.EqualValues(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestChecker_Pprof_disabled(t *testing.T) {
+	checker := &Checker{}
+	server := httptest.NewServer(checker.serverMux())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/debug/pprof/")
+
+	assert.NoError(t, err)
+	assert.EqualValues(t, http.StatusNotFound, resp.StatusCode)
+}
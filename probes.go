@@ -1,14 +1,28 @@
 package health
 
 import (
+	"bytes"
 	"context"
-	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
 	"net/http"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/gomodule/redigo/redis"
 	vault "github.com/hashicorp/vault/api"
 	"github.com/nats-io/go-nats"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.mongodb.org/mongo-driver/mongo/readpref"
 	"google.golang.org/grpc/connectivity"
 )
@@ -18,59 +32,480 @@ type GrpcStateReporter interface {
 	GetState() connectivity.State
 }
 
+type grpcProbeConfig struct {
+	reconnect bool
+	wait      time.Duration
+}
+
+// A GrpcOption configures a GrpcProbe.
+type GrpcOption func(c *grpcProbeConfig)
+
+// Triggers a reconnect if the connection is idle or connecting, as channels drop to IDLE after inactivity
+// although the backend is fine. Calls `Connect()` if supported by the connection (grpc-go >= 1.41)
+// and waits up to the given duration for the connection to become ready.
+func GrpcReconnect(wait time.Duration) GrpcOption {
+	return func(c *grpcProbeConfig) {
+		c.reconnect = true
+		c.wait = wait
+	}
+}
+
+// Interface matching a gRPC client's connect method.
+type grpcConnector interface {
+	Connect()
+}
+
+// Interface matching a gRPC client's method to wait for state changes.
+type grpcStateWaiter interface {
+	WaitForStateChange(ctx context.Context, sourceState connectivity.State) bool
+}
+
 // Checks a grpc connection for readiness.
 //
 // Example:
 //		cc, _ := grpc.Dial(...)
 //		checker.AddReadinessProbe("my-grpc-service", health.GrpcProbe(cc))
-func GrpcProbe(conn GrpcStateReporter) Probe {
+//		checker.AddReadinessProbe("my-idle-grpc-service", health.GrpcProbe(cc, health.GrpcReconnect(time.Second)))
+func GrpcProbe(conn GrpcStateReporter, opts ...GrpcOption) Probe {
+	c := &grpcProbeConfig{}
+	for _, opt := range opts {
+		opt(c)
+	}
+
 	return func() error {
 		state := conn.GetState()
+		if c.reconnect && (state == connectivity.Idle || state == connectivity.Connecting) {
+			state = reconnectGrpc(conn, state, c.wait)
+		}
+
 		if state != connectivity.Ready {
-			return fmt.Errorf("grpc connection is in unready state: %v", state)
+			return classify(ErrUnreachable, fmt.Errorf("grpc connection is in unready state: %v", state))
 		}
 
 		return nil
 	}
 }
 
+// Triggers a reconnect and waits up to wait for the connection to become ready. Returns the resulting state.
+func reconnectGrpc(conn GrpcStateReporter, state connectivity.State, wait time.Duration) connectivity.State {
+	if connector, ok := conn.(grpcConnector); ok {
+		connector.Connect()
+		state = conn.GetState()
+	}
+
+	waiter, ok := conn.(grpcStateWaiter)
+	if !ok || wait <= 0 {
+		return state
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), wait)
+	defer cancel()
+
+	for state != connectivity.Ready && waiter.WaitForStateChange(ctx, state) {
+		state = conn.GetState()
+	}
+
+	return state
+}
+
+// Transport shared by the HTTP probes, unless a probe is given its own client or transport. It keeps the connections
+// to the probed endpoints alive, so evaluations don't open fresh TCP and TLS connections each time. Replace it before
+// registering the probes to change the defaults of all of them.
+var SharedTransport http.RoundTripper = newSharedTransport()
+
+// Max idle connections kept per host by the SharedTransport
+const sharedTransportIdleConnsPerHost = 8
+
+func newSharedTransport() *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.MaxIdleConnsPerHost = sharedTransportIdleConnsPerHost
+	t.Proxy = defaultProxyOf
+
+	return t
+}
+
+type httpProbeConfig struct {
+	client   *http.Client
+	proxy    *url.URL
+	timeout  time.Duration
+	method   string
+	header   http.Header
+	body     []byte
+	statuses [][2]int
+	asserts  []func(body []byte) error
+}
+
+// Max bytes of a response body read for assertions
+const maxHTTPAssertBodySize = 1 << 20
+
+// A HTTPOption configures a HTTPProbe.
+type HTTPOption func(c *httpProbeConfig)
+
+// Sets the client used to send the request, e.g. configured for mTLS or a proxy. Defaults to a client using the
+// SharedTransport.
+func HTTPClient(client *http.Client) HTTPOption {
+	return func(c *httpProbeConfig) {
+		c.client = client
+	}
+}
+
+// Sets the transport used to send the request instead of the SharedTransport, e.g. to keep the connections to an
+// endpoint apart.
+func HTTPTransport(rt http.RoundTripper) HTTPOption {
+	return func(c *httpProbeConfig) {
+		c.client = &http.Client{Transport: rt}
+	}
+}
+
+// Sets a timeout for the whole request including reading the response. By default the request is not canceled.
+func HTTPTimeout(d time.Duration) HTTPOption {
+	return func(c *httpProbeConfig) {
+		c.timeout = d
+	}
+}
+
+// Sets the request method. Defaults to GET.
+func HTTPMethod(method string) HTTPOption {
+	return func(c *httpProbeConfig) {
+		c.method = method
+	}
+}
+
+// Adds a request header, e.g. an authorization token. Setting `Host` overrides the requested host.
+func HTTPHeader(key, value string) HTTPOption {
+	return func(c *httpProbeConfig) {
+		c.header.Add(key, value)
+	}
+}
+
+// Sets the request body. Use HTTPHeader to set the matching `Content-Type`.
+func HTTPBody(body []byte) HTTPOption {
+	return func(c *httpProbeConfig) {
+		c.body = body
+	}
+}
+
+// Accepts the given status codes. Replaces the default of accepting any 2xx status; can be combined with HTTPExpectStatusRange.
+func HTTPExpectStatus(codes ...int) HTTPOption {
+	return func(c *httpProbeConfig) {
+		for _, code := range codes {
+			c.statuses = append(c.statuses, [2]int{code, code})
+		}
+	}
+}
+
+// Accepts status codes from min to max (inclusive). Replaces the default of accepting any 2xx status; can be combined with HTTPExpectStatus.
+func HTTPExpectStatusRange(min, max int) HTTPOption {
+	return func(c *httpProbeConfig) {
+		c.statuses = append(c.statuses, [2]int{min, max})
+	}
+}
+
+// Fails if the response body does not contain substr.
+func HTTPExpectBody(substr string) HTTPOption {
+	return func(c *httpProbeConfig) {
+		c.asserts = append(c.asserts, func(body []byte) error {
+			if !bytes.Contains(body, []byte(substr)) {
+				return fmt.Errorf("response body does not contain %q", substr)
+			}
+
+			return nil
+		})
+	}
+}
+
+// Fails if the response body does not match re.
+func HTTPExpectBodyMatch(re *regexp.Regexp) HTTPOption {
+	return func(c *httpProbeConfig) {
+		c.asserts = append(c.asserts, func(body []byte) error {
+			if !re.Match(body) {
+				return fmt.Errorf("response body does not match %v", re)
+			}
+
+			return nil
+		})
+	}
+}
+
+// Fails if the response is not a json object or the field at path does not equal expected.
+// Nested fields are separated by dots, e.g. `checks.database.status`.
+//
+// Example:
+//		health.HTTPProbe("http://my-service:8080/status", health.HTTPExpectJSON("status", "ok"))
+func HTTPExpectJSON(path string, expected interface{}) HTTPOption {
+	// Normalize expected to the types produced by decoding json
+	var want interface{}
+	if b, err := json.Marshal(expected); err == nil {
+		_ = json.Unmarshal(b, &want)
+	}
+
+	return func(c *httpProbeConfig) {
+		c.asserts = append(c.asserts, func(body []byte) error {
+			var value interface{}
+			if err := json.Unmarshal(body, &value); err != nil {
+				return fmt.Errorf("response body is no valid json: %w", err)
+			}
+
+			for _, key := range strings.Split(path, ".") {
+				obj, ok := value.(map[string]interface{})
+				if !ok {
+					return fmt.Errorf("response has no field %v", path)
+				}
+
+				if value, ok = obj[key]; !ok {
+					return fmt.Errorf("response has no field %v", path)
+				}
+			}
+
+			if !reflect.DeepEqual(value, want) {
+				return fmt.Errorf("response field %v is %v, expected %v", path, value, expected)
+			}
+
+			return nil
+		})
+	}
+}
+
 // Pings a http endpoint for readiness. Called endpoint should return 2xx as status.
 // **INFO:** If you check another service using this lib, always use the `/.well-known/alive endpoint` to prevent cascading requests.
 //
 // Example:
 //		checker.AddReadinessProbe("my-http-service", health.HTTPProbe("http://my-service:8080/.well-known/alive"))
-func HTTPProbe(endpoint string) Probe {
+//		checker.AddReadinessProbe("my-api", health.HTTPProbe("http://my-api:8080/status", health.HTTPMethod(http.MethodHead), health.HTTPExpectStatus(200, 204)))
+func HTTPProbe(endpoint string, opts ...HTTPOption) Probe {
+	c := &httpProbeConfig{
+		client: &http.Client{Transport: SharedTransport},
+		method: http.MethodGet,
+		header: http.Header{},
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if len(c.statuses) == 0 {
+		c.statuses = [][2]int{{200, 299}}
+	}
+
+	if c.proxy != nil {
+		transport := newSharedTransport()
+		if t, ok := c.client.Transport.(*http.Transport); ok {
+			transport = t.Clone()
+		}
+		transport.Proxy = http.ProxyURL(c.proxy)
+
+		client := *c.client
+		client.Transport = transport
+		c.client = &client
+	}
+
 	return func() error {
-		// #nosec G107
-		resp, err := http.Get(endpoint)
+		var body io.Reader
+		if c.body != nil {
+			body = bytes.NewReader(c.body)
+		}
+
+		ctx := context.Background()
+		if c.timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, c.timeout)
+			defer cancel()
+		}
+
+		req, err := http.NewRequestWithContext(ctx, c.method, endpoint, body)
+		if err != nil {
+			return fmt.Errorf("invalid request: %w", err)
+		}
+
+		for key, values := range c.header {
+			req.Header[key] = values
+		}
+
+		if host := c.header.Get("Host"); host != "" {
+			req.Host = host
+		}
+
+		resp, err := c.client.Do(req)
 		if err != nil {
-			return fmt.Errorf("endpoint could not be reached: %v", err)
+			return classify(ErrUnreachable, fmt.Errorf("endpoint could not be reached: %w", err))
 		}
+		defer resp.Body.Close()
+
+		if !c.acceptsStatus(resp.StatusCode) {
+			_, _ = io.Copy(ioutil.Discard, resp.Body)
+			err := fmt.Errorf("service is not ready: %v - %v", resp.StatusCode, resp.Status)
+			if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+				return classify(ErrUnauthorized, err)
+			}
 
-		if resp.StatusCode >= 200 && resp.StatusCode <= 299 {
+			return classify(ErrUnhealthy, err)
+		}
+
+		if len(c.asserts) == 0 {
+			_, _ = io.Copy(ioutil.Discard, resp.Body)
 			return nil
 		}
 
-		return fmt.Errorf("service is not ready: %v - %v", resp.StatusCode, resp.Status)
+		b, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxHTTPAssertBodySize))
+		if err != nil {
+			return fmt.Errorf("could not read response: %w", err)
+		}
+
+		for _, check := range c.asserts {
+			if err := check(b); err != nil {
+				return classify(ErrUnhealthy, err)
+			}
+		}
+
+		return nil
 	}
 }
 
+func (c *httpProbeConfig) acceptsStatus(code int) bool {
+	for _, status := range c.statuses {
+		if code >= status[0] && code <= status[1] {
+			return true
+		}
+	}
+
+	return false
+}
+
 // Interface matching a mongodb client's ping method.
 type MongoStateReporter interface {
 	Ping(ctx context.Context, rp *readpref.ReadPref) error
 }
 
+type mongoProbeConfig struct {
+	readPref *readpref.ReadPref
+	timeout  time.Duration
+}
+
+// A MongoOption configures a MongoProbe.
+type MongoOption func(c *mongoProbeConfig)
+
+// Sets the read preference used to select the pinged server. Defaults to primary.
+// Use e.g. `readpref.PrimaryPreferred()` for read-only services to stay ready during a primary election.
+func MongoReadPref(rp *readpref.ReadPref) MongoOption {
+	return func(c *mongoProbeConfig) {
+		c.readPref = rp
+	}
+}
+
+// Sets a timeout for the ping. By default the ping is not canceled.
+func MongoTimeout(d time.Duration) MongoOption {
+	return func(c *mongoProbeConfig) {
+		c.timeout = d
+	}
+}
+
 // Checks a mongodb connection for readiness.
 //
 // Example:
 //		client, _ := mongo.Connect(ctx, options.Client().ApplyURI(uri))
-//		checker.AddReadinessProbe("my-mongo-client", health.MongoProbe(client))
-func MongoProbe(client MongoStateReporter) Probe {
+//		checker.AddReadinessProbe("my-mongo-client", health.MongoProbe(client, health.MongoTimeout(2*time.Second)))
+func MongoProbe(client MongoStateReporter, opts ...MongoOption) Probe {
+	c := &mongoProbeConfig{
+		readPref: readpref.Primary(),
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return func() error {
+		ctx := context.Background()
+		if c.timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, c.timeout)
+			defer cancel()
+		}
+
+		return client.Ping(ctx, c.readPref)
+	}
+}
+
+// Interface matching a mongodb database's run command method.
+type MongoCommandRunner interface {
+	RunCommand(ctx context.Context, runCommand interface{}, opts ...*options.RunCmdOptions) *mongo.SingleResult
+}
+
+type mongoReplicaSetStatus struct {
+	Members []mongoReplicaSetMember `bson:"members"`
+}
+
+type mongoReplicaSetMember struct {
+	Name   string `bson:"name"`
+	Health int    `bson:"health"`
+	State  int    `bson:"state"`
+}
+
+const (
+	mongoStatePrimary   = 1
+	mongoStateSecondary = 2
+)
+
+// Checks a mongodb replica set has a primary and at least minSecondaries healthy secondaries, e.g. to be able to
+// acknowledge writes with majority write concern. Runs `replSetGetStatus`, thus db has to be the admin database
+// and the user needs the clusterMonitor role. Supports the same options as MongoProbe.
+//
+// Example:
+//		client, _ := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+//		checker.AddReadinessProbe("mongo-replica-set", health.MongoReplicaSetProbe(client.Database("admin"), 1))
+func MongoReplicaSetProbe(db MongoCommandRunner, minSecondaries int, opts ...MongoOption) Probe {
+	c := &mongoProbeConfig{
+		readPref: readpref.Primary(),
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
 	return func() error {
-		return client.Ping(context.Background(), readpref.Primary())
+		ctx := context.Background()
+		if c.timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, c.timeout)
+			defer cancel()
+		}
+
+		var status mongoReplicaSetStatus
+		res := db.RunCommand(ctx, bson.D{{Key: "replSetGetStatus", Value: 1}}, options.RunCmd().SetReadPreference(c.readPref))
+		if err := res.Decode(&status); err != nil {
+			return fmt.Errorf("could not get replica set status: %w", err)
+		}
+
+		return checkReplicaSetStatus(&status, minSecondaries)
 	}
 }
 
+func checkReplicaSetStatus(status *mongoReplicaSetStatus, minSecondaries int) error {
+	hasPrimary := false
+	secondaries := 0
+	for _, m := range status.Members {
+		if m.Health != 1 {
+			continue
+		}
+
+		switch m.State {
+		case mongoStatePrimary:
+			hasPrimary = true
+		case mongoStateSecondary:
+			secondaries++
+		}
+	}
+
+	if !hasPrimary {
+		return classify(ErrUnhealthy, fmt.Errorf("replica set has no primary"))
+	}
+
+	if secondaries < minSecondaries {
+		return classify(ErrUnhealthy, fmt.Errorf("replica set has %v healthy secondaries, need %v", secondaries, minSecondaries))
+	}
+
+	return nil
+}
+
 // Interface matching a nats client's status method.
 type NatsStateReporter interface {
 	Status() nats.Status
@@ -86,7 +521,7 @@ func NatsProbe(conn NatsStateReporter) Probe {
 		state := conn.Status()
 
 		if state != nats.CONNECTED {
-			return fmt.Errorf("nats connection is in unready state: %v", state)
+			return classify(ErrUnreachable, fmt.Errorf("nats connection is in unready state: %v", state))
 		}
 
 		return nil
@@ -96,19 +531,34 @@ func NatsProbe(conn NatsStateReporter) Probe {
 // Checks a pool of redis connection for readiness.
 func RedisPoolProbe(pool *redis.Pool) Probe {
 	return func() error {
-		err := pool.Get().Err()
+		conn := pool.Get()
+		defer conn.Close()
+
+		err := conn.Err()
 		if err != nil {
-			return fmt.Errorf("redis connection is not useable: %v", err.Error())
+			return classify(ErrUnreachable, fmt.Errorf("redis connection is not useable: %w", err))
 		}
 
 		return nil
 	}
 }
 
-// Checks a SQL connection for readiness.
-func SQLProbe(db *sql.DB) Probe {
+// Checks a TCP endpoint (host:port) accepts connections. Connects through the DefaultProxy if it is set.
+//
+// Example:
+//		checker.AddReadinessProbe("legacy-backend", health.TCPProbe("backend.example.com:4000", 2*time.Second))
+func TCPProbe(addr string, timeout time.Duration) Probe {
 	return func() error {
-		return db.Ping()
+		if DefaultProxy != nil {
+			return TCPProxyProbe(addr, DefaultProxy, timeout)()
+		}
+
+		conn, err := net.DialTimeout("tcp", addr, timeout)
+		if err != nil {
+			return classify(ErrUnreachable, fmt.Errorf("endpoint could not be reached: %w", err))
+		}
+
+		return conn.Close()
 	}
 }
 
@@ -117,26 +567,141 @@ type VaultHealthReporter interface {
 	Health() (*vault.HealthResponse, error)
 }
 
+type vaultProbeConfig struct {
+	allowStandby bool
+}
+
+// A VaultOption configures a VaultProbe.
+type VaultOption func(c *vaultProbeConfig)
+
+// Reports a vault node on standby as ready. Use it for HA clusters, where standby nodes forward requests to the active node.
+func AllowStandby() VaultOption {
+	return func(c *vaultProbeConfig) {
+		c.allowStandby = true
+	}
+}
+
 // Checks a vault connection for readiness
-func VaultProbe(hr VaultHealthReporter) Probe {
+//
+// Example:
+//		client, _ := vault.NewClient(vault.DefaultConfig())
+//		checker.AddReadinessProbe("vault", health.VaultProbe(client.Sys(), health.AllowStandby()))
+func VaultProbe(hr VaultHealthReporter, opts ...VaultOption) Probe {
+	c := &vaultProbeConfig{}
+	for _, opt := range opts {
+		opt(c)
+	}
+
 	return func() error {
 		hc, err := hr.Health()
 		if err != nil {
-			return fmt.Errorf("could not get vault health: %v", err.Error())
+			return classify(ErrUnreachable, fmt.Errorf("could not get vault health: %w", err))
 		}
 
 		if !hc.Initialized {
-			return fmt.Errorf("vault is not initialized")
+			return classify(ErrUnhealthy, fmt.Errorf("vault is not initialized"))
 		}
 
 		if hc.Sealed {
-			return fmt.Errorf("vault is sealed")
+			return classify(ErrUnhealthy, fmt.Errorf("vault is sealed"))
 		}
 
-		if hc.Standby {
-			return fmt.Errorf("vault is on standby")
+		if hc.Standby && !c.allowStandby {
+			return classify(ErrUnhealthy, fmt.Errorf("vault is on standby"))
 		}
 
 		return nil
 	}
 }
+
+// Interface matching a vault token client's lookup-self method.
+type VaultTokenLookuper interface {
+	LookupSelf() (*vault.Secret, error)
+}
+
+// Checks the vault token used by the service is valid and does not expire within minTTL.
+// Tokens without a TTL, e.g. root tokens, never expire.
+//
+// Example:
+//		client, _ := vault.NewClient(vault.DefaultConfig())
+//		checker.AddReadinessProbe("vault-token", health.VaultTokenProbe(client.Auth().Token(), 5*time.Minute))
+func VaultTokenProbe(tl VaultTokenLookuper, minTTL time.Duration) Probe {
+	return func() error {
+		secret, err := tl.LookupSelf()
+		if err != nil {
+			err = fmt.Errorf("could not lookup vault token: %w", err)
+
+			var respErr *vault.ResponseError
+			if !errors.As(err, &respErr) {
+				return classify(ErrUnreachable, err)
+			}
+
+			// Vault answers lookups of invalid or expired tokens with 403
+			if respErr.StatusCode == http.StatusUnauthorized || respErr.StatusCode == http.StatusForbidden {
+				return classify(ErrUnauthorized, err)
+			}
+
+			return classify(ErrUnhealthy, err)
+		}
+
+		ttl, err := secret.TokenTTL()
+		if err != nil {
+			return classify(ErrUnhealthy, fmt.Errorf("could not get vault token ttl: %w", err))
+		}
+
+		if ttl > 0 && ttl < minTTL {
+			return classify(ErrUnauthorized, fmt.Errorf("vault token expires in %v", ttl))
+		}
+
+		return nil
+	}
+}
+
+// Runs fn and returns a timeout error if it does not complete before expired receives.
+// fn keeps running in background after the timeout has elapsed, use a boundedRunner for repeated runs.
+func runUntil(expired <-chan time.Time, timeout time.Duration, fn func() error) error {
+	return (&boundedRunner{}).runUntil(context.Background(), expired, timeout, fn)
+}
+
+// Runs a function with a timeout, keeping at most one run in flight. Functions can not be canceled, so a run
+// abandoned by a timeout keeps running. Instead of starting another run while it hangs, the next call waits for it,
+// so a hanging dependency leaks a single goroutine and connection instead of one per evaluation.
+type boundedRunner struct {
+	mu      sync.Mutex
+	pending *pendingRun
+}
+
+type pendingRun struct {
+	done chan struct{}
+	err  error
+}
+
+// Runs fn and returns a timeout error if it does not complete before expired receives, or the error of ctx if it is
+// done first. A nil expired never times out. Waits for the run in flight instead if there is one.
+func (b *boundedRunner) runUntil(ctx context.Context, expired <-chan time.Time, timeout time.Duration, fn func() error) error {
+	b.mu.Lock()
+	run := b.pending
+	if run == nil {
+		run = &pendingRun{done: make(chan struct{})}
+		b.pending = run
+
+		go func() {
+			run.err = fn()
+
+			b.mu.Lock()
+			b.pending = nil
+			b.mu.Unlock()
+			close(run.done)
+		}()
+	}
+	b.mu.Unlock()
+
+	select {
+	case <-run.done:
+		return run.err
+	case <-expired:
+		return &timeoutError{timeout: timeout}
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
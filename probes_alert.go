@@ -0,0 +1,209 @@
+package health
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+const defaultAlertQueryTimeout = 5 * time.Second
+
+type alertProbeConfig struct {
+	client *http.Client
+	labels map[string]string
+}
+
+// An AlertOption configures the probes querying alerts of Prometheus or Alertmanager.
+type AlertOption func(c *alertProbeConfig)
+
+// Sets the client used to query the API. Defaults to a client using the SharedTransport with a timeout of 5 seconds.
+func AlertClient(client *http.Client) AlertOption {
+	return func(c *alertProbeConfig) {
+		c.client = client
+	}
+}
+
+// Only considers alerts with the given label, e.g. the service or namespace the alert is about.
+func AlertLabel(key, value string) AlertOption {
+	return func(c *alertProbeConfig) {
+		c.labels[key] = value
+	}
+}
+
+func newAlertProbeConfig(opts []AlertOption) *alertProbeConfig {
+	cfg := &alertProbeConfig{labels: map[string]string{}}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	if cfg.client == nil {
+		cfg.client = &http.Client{Transport: SharedTransport, Timeout: defaultAlertQueryTimeout}
+	}
+
+	return cfg
+}
+
+// Returns true if the labels contain all labels the probe filters for.
+func (c *alertProbeConfig) matches(labels map[string]string) bool {
+	for key, value := range c.labels {
+		if labels[key] != value {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (c *alertProbeConfig) get(endpoint string, v interface{}) error {
+	resp, err := c.client.Get(endpoint)
+	if err != nil {
+		return classify(ErrUnreachable, fmt.Errorf("alerts could not be queried: %w", err))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		err := fmt.Errorf("alerts could not be queried: %v", resp.Status)
+		if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+			return classify(ErrUnauthorized, err)
+		}
+
+		return classify(ErrUnreachable, err)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("invalid response: %w", err)
+	}
+
+	return nil
+}
+
+// Fails while the alert with the given name fires in Prometheus, so traffic can be gated on alerts computed outside
+// of the service, e.g. SLO burn rates. Pending alerts are ignored.
+//
+// Example:
+//		checker.AddReadinessProbe("slo", health.PrometheusAlertProbe("http://prometheus:9090", "CheckoutErrorBudgetBurn", health.AlertLabel("service", "checkout")))
+func PrometheusAlertProbe(prometheusURL, alertName string, opts ...AlertOption) Probe {
+	cfg := newAlertProbeConfig(opts)
+	endpoint := strings.TrimSuffix(prometheusURL, "/") + "/api/v1/alerts"
+
+	return func() error {
+		var resp struct {
+			Data struct {
+				Alerts []struct {
+					Labels map[string]string `json:"labels"`
+					State  string            `json:"state"`
+				} `json:"alerts"`
+			} `json:"data"`
+		}
+		if err := cfg.get(endpoint, &resp); err != nil {
+			return err
+		}
+
+		for _, alert := range resp.Data.Alerts {
+			if alert.State == "firing" && alert.Labels["alertname"] == alertName && cfg.matches(alert.Labels) {
+				return classify(ErrUnhealthy, fmt.Errorf("alert %v is firing", alertName))
+			}
+		}
+
+		return nil
+	}
+}
+
+// Fails while the PromQL expression returns a result, like an alerting rule does. Use it for conditions without
+// an alerting rule of their own.
+//
+// Example:
+//		checker.AddReadinessProbe("slo", health.PrometheusQueryProbe("http://prometheus:9090", `slo:error_budget_burn:rate1h{service="checkout"} > 14.4`))
+func PrometheusQueryProbe(prometheusURL, expr string, opts ...AlertOption) Probe {
+	cfg := newAlertProbeConfig(opts)
+	endpoint := strings.TrimSuffix(prometheusURL, "/") + "/api/v1/query?query=" + url.QueryEscape(expr)
+
+	return func() error {
+		var resp struct {
+			Status string `json:"status"`
+			Error  string `json:"error"`
+			Data   struct {
+				ResultType string `json:"resultType"`
+				Result     []struct {
+					Metric map[string]string `json:"metric"`
+				} `json:"result"`
+			} `json:"data"`
+		}
+		if err := cfg.get(endpoint, &resp); err != nil {
+			return err
+		}
+
+		if resp.Status != "success" {
+			return fmt.Errorf("query failed: %v", resp.Error)
+		}
+
+		var firing []string
+		for _, r := range resp.Data.Result {
+			if cfg.matches(r.Metric) {
+				firing = append(firing, formatLabels(r.Metric))
+			}
+		}
+
+		if len(firing) > 0 {
+			return classify(ErrUnhealthy, fmt.Errorf("query returned %v series: %v", len(firing), strings.Join(firing, ", ")))
+		}
+
+		return nil
+	}
+}
+
+// Fails while the alert with the given name is active in Alertmanager. Silenced and inhibited alerts are ignored,
+// so responders can silence an alert to release the gate.
+//
+// Example:
+//		checker.AddReadinessProbe("slo", health.AlertmanagerAlertProbe("http://alertmanager:9093", "CheckoutErrorBudgetBurn"))
+func AlertmanagerAlertProbe(alertmanagerURL, alertName string, opts ...AlertOption) Probe {
+	cfg := newAlertProbeConfig(opts)
+
+	query := url.Values{}
+	query.Set("active", "true")
+	query.Set("silenced", "false")
+	query.Set("inhibited", "false")
+	query.Add("filter", fmt.Sprintf("alertname=%q", alertName))
+	endpoint := strings.TrimSuffix(alertmanagerURL, "/") + "/api/v2/alerts?" + query.Encode()
+
+	return func() error {
+		var alerts []struct {
+			Labels map[string]string `json:"labels"`
+			Status struct {
+				State string `json:"state"`
+			} `json:"status"`
+		}
+		if err := cfg.get(endpoint, &alerts); err != nil {
+			return err
+		}
+
+		for _, alert := range alerts {
+			if alert.Status.State == "active" && alert.Labels["alertname"] == alertName && cfg.matches(alert.Labels) {
+				return classify(ErrUnhealthy, fmt.Errorf("alert %v is firing", alertName))
+			}
+		}
+
+		return nil
+	}
+}
+
+// Formats labels like Prometheus does, e.g. `{job="api",service="checkout"}`.
+func formatLabels(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, fmt.Sprintf("%v=%q", key, labels[key]))
+	}
+
+	return "{" + strings.Join(pairs, ",") + "}"
+}
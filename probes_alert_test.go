@@ -0,0 +1,73 @@
+package health
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrometheusAlertProbe(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/alerts", r.URL.Path)
+		fmt.Fprint(w, `{"status":"success","data":{"alerts":[
+			{"labels":{"alertname":"ErrorBudgetBurn","service":"checkout"},"state":"firing"},
+			{"labels":{"alertname":"HighLatency","service":"checkout"},"state":"pending"}
+		]}}`)
+	}))
+	defer server.Close()
+
+	err := PrometheusAlertProbe(server.URL, "ErrorBudgetBurn")()
+	assert.EqualError(t, err, "alert ErrorBudgetBurn is firing")
+	assert.True(t, errors.Is(err, ErrUnhealthy))
+
+	assert.NoError(t, PrometheusAlertProbe(server.URL, "ErrorBudgetBurn", AlertLabel("service", "billing"))())
+	assert.NoError(t, PrometheusAlertProbe(server.URL, "HighLatency")(), "pending")
+}
+
+func TestPrometheusQueryProbe(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("query") == "up == 0" {
+			fmt.Fprint(w, `{"status":"success","data":{"resultType":"vector","result":[{"metric":{"job":"api"},"value":[1,"0"]}]}}`)
+			return
+		}
+		fmt.Fprint(w, `{"status":"success","data":{"resultType":"vector","result":[]}}`)
+	}))
+	defer server.Close()
+
+	err := PrometheusQueryProbe(server.URL, "up == 0")()
+	assert.EqualError(t, err, `query returned 1 series: {job="api"}`)
+	assert.NoError(t, PrometheusQueryProbe(server.URL, "up == 2")())
+}
+
+func TestAlertmanagerAlertProbe(t *testing.T) {
+	firing := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, `alertname="ErrorBudgetBurn"`, r.URL.Query().Get("filter"))
+		assert.Equal(t, "false", r.URL.Query().Get("silenced"))
+		if !firing {
+			fmt.Fprint(w, `[]`)
+			return
+		}
+		fmt.Fprint(w, `[{"labels":{"alertname":"ErrorBudgetBurn"},"status":{"state":"active"}}]`)
+	}))
+	defer server.Close()
+
+	probe := AlertmanagerAlertProbe(server.URL, "ErrorBudgetBurn")
+	assert.EqualError(t, probe(), "alert ErrorBudgetBurn is firing")
+
+	firing = false
+	assert.NoError(t, probe())
+}
+
+func TestPrometheusAlertProbe_unreachable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	assert.True(t, errors.Is(PrometheusAlertProbe(server.URL, "ErrorBudgetBurn")(), ErrUnreachable))
+}
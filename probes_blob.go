@@ -0,0 +1,49 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const defaultBucketTimeout = 5 * time.Second
+
+// Interface matching the access check of a gocloud.dev `*blob.Bucket`, so the probe works with buckets of any
+// provider without depending on the Go CDK.
+type BucketAccessChecker interface {
+	IsAccessible(ctx context.Context) (bool, error)
+}
+
+// Checks a bucket of the Go CDK (gocloud.dev/blob) for readiness, independent of the cloud provider behind it.
+// Fails if the bucket does not exist, can not be accessed or the check does not finish within timeout, which
+// defaults to 5 seconds if 0.
+//
+// Example:
+//		bucket, _ := blob.OpenBucket(ctx, "s3://my-bucket?region=eu-central-1")
+//		checker.AddReadinessProbe("bucket", health.BucketProbe(bucket, time.Second))
+func BucketProbe(bucket BucketAccessChecker, timeout time.Duration) Probe {
+	if timeout == 0 {
+		timeout = defaultBucketTimeout
+	}
+
+	return func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		accessible, err := bucket.IsAccessible(ctx)
+		if err != nil {
+			if errors.Is(err, context.DeadlineExceeded) {
+				return &timeoutError{timeout: timeout}
+			}
+
+			return classify(ErrUnreachable, fmt.Errorf("bucket could not be reached: %w", err))
+		}
+
+		if !accessible {
+			return classify(ErrUnhealthy, fmt.Errorf("bucket does not exist or is not accessible"))
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,40 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeBucket struct {
+	accessible bool
+	err        error
+	wait       time.Duration
+}
+
+func (b *fakeBucket) IsAccessible(ctx context.Context) (bool, error) {
+	select {
+	case <-time.After(b.wait):
+		return b.accessible, b.err
+	case <-ctx.Done():
+		return false, ctx.Err()
+	}
+}
+
+func TestBucketProbe(t *testing.T) {
+	assert.NoError(t, BucketProbe(&fakeBucket{accessible: true}, time.Second)())
+
+	err := BucketProbe(&fakeBucket{}, time.Second)()
+	assert.True(t, errors.Is(err, ErrUnhealthy))
+
+	err = BucketProbe(&fakeBucket{err: fmt.Errorf("connection refused")}, time.Second)()
+	assert.EqualError(t, err, "bucket could not be reached: connection refused")
+	assert.True(t, errors.Is(err, ErrUnreachable))
+
+	err = BucketProbe(&fakeBucket{accessible: true, wait: time.Second}, 10*time.Millisecond)()
+	assert.True(t, errors.Is(err, ErrTimeout))
+}
@@ -0,0 +1,106 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+const (
+	defaultContainerRuntimeTimeout = 5 * time.Second
+	// Default sockets of the container runtimes
+	DockerSocket     = "/var/run/docker.sock"
+	ContainerdSocket = "/run/containerd/containerd.sock"
+)
+
+// Checks the Docker daemon (or a compatible one like Podman) listening at the unix socket for readiness, for agents
+// and CI runners depending on the container runtime. Requests `/version` and fails if the daemon does not answer
+// within timeout, which defaults to 5 seconds if 0. Uses DockerSocket if socket is empty.
+//
+// Example:
+//		checker.AddReadinessProbe("docker", health.DockerProbe("", time.Second))
+func DockerProbe(socket string, timeout time.Duration) Probe {
+	if socket == "" {
+		socket = DockerSocket
+	}
+
+	if timeout == 0 {
+		timeout = defaultContainerRuntimeTimeout
+	}
+
+	client := &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, "unix", socket)
+			},
+		},
+	}
+
+	return func() error {
+		resp, err := client.Get("http://docker/version")
+		if err != nil {
+			return classify(ErrUnreachable, fmt.Errorf("docker daemon could not be reached: %w", err))
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return classify(ErrUnhealthy, fmt.Errorf("docker daemon is not ready: %v", resp.Status))
+		}
+
+		var version struct {
+			Version string `json:"Version"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&version); err != nil || version.Version == "" {
+			return classify(ErrUnhealthy, fmt.Errorf("docker daemon returned an invalid version"))
+		}
+
+		return nil
+	}
+}
+
+// Checks containerd listening at the unix socket for readiness using its gRPC health service. Fails if containerd
+// does not answer within timeout, which defaults to 5 seconds if 0. Uses ContainerdSocket if socket is empty.
+//
+// Example:
+//		checker.AddReadinessProbe("containerd", health.ContainerdProbe("", time.Second))
+func ContainerdProbe(socket string, timeout time.Duration) Probe {
+	if socket == "" {
+		socket = ContainerdSocket
+	}
+
+	if timeout == 0 {
+		timeout = defaultContainerRuntimeTimeout
+	}
+
+	return func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		cc, err := grpc.DialContext(ctx, socket, grpc.WithInsecure(), grpc.WithBlock(),
+			grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, "unix", addr)
+			}))
+		if err != nil {
+			return classify(ErrUnreachable, fmt.Errorf("containerd could not be reached: %w", err))
+		}
+		defer cc.Close()
+
+		resp, err := grpc_health_v1.NewHealthClient(cc).Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+		if err != nil {
+			return classify(ErrUnreachable, fmt.Errorf("containerd health check failed: %w", err))
+		}
+
+		if resp.Status != grpc_health_v1.HealthCheckResponse_SERVING {
+			return classify(ErrUnhealthy, fmt.Errorf("containerd is %v", resp.Status))
+		}
+
+		return nil
+	}
+}
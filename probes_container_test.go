@@ -0,0 +1,65 @@
+package health
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	grpchealth "google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func unixListener(t *testing.T) (net.Listener, string) {
+	dir, err := ioutil.TempDir("", "health")
+	assert.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+
+	socket := filepath.Join(dir, "runtime.sock")
+	lis, err := net.Listen("unix", socket)
+	assert.NoError(t, err)
+
+	return lis, socket
+}
+
+func TestDockerProbe(t *testing.T) {
+	lis, socket := unixListener(t)
+
+	server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/version", r.URL.Path)
+		fmt.Fprint(w, `{"Version":"20.10.5","ApiVersion":"1.41"}`)
+	})}
+	go func() { _ = server.Serve(lis) }()
+	defer server.Close()
+
+	assert.NoError(t, DockerProbe(socket, time.Second)())
+
+	err := DockerProbe(socket+".missing", time.Second)()
+	assert.True(t, errors.Is(err, ErrUnreachable))
+}
+
+func TestContainerdProbe(t *testing.T) {
+	lis, socket := unixListener(t)
+
+	grpcServer := grpc.NewServer()
+	healthServer := grpchealth.NewServer()
+	grpc_health_v1.RegisterHealthServer(grpcServer, healthServer)
+	go func() { _ = grpcServer.Serve(lis) }()
+	defer grpcServer.Stop()
+
+	assert.NoError(t, ContainerdProbe(socket, time.Second)())
+
+	healthServer.SetServingStatus("", grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+	err := ContainerdProbe(socket, time.Second)()
+	assert.EqualError(t, err, "containerd is NOT_SERVING")
+
+	err = ContainerdProbe(socket+".missing", 100*time.Millisecond)()
+	assert.True(t, errors.Is(err, ErrUnreachable))
+}
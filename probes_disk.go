@@ -0,0 +1,32 @@
+package health
+
+import (
+	"fmt"
+)
+
+// Checks the free space of the filesystem containing path. Fails if less than minFreeBytes
+// or less than minFreePercent (0-100) of the total space is available. Pass 0 to disable a threshold.
+//
+// Example:
+//		checker.AddReadinessProbe("spool-disk", health.DiskSpaceProbe("/var/spool/app", 512<<20, 5))
+func DiskSpaceProbe(path string, minFreeBytes uint64, minFreePercent float64) Probe {
+	return func() error {
+		free, total, err := diskUsage(path)
+		if err != nil {
+			return fmt.Errorf("could not determine disk usage of %v: %w", path, err)
+		}
+
+		if free < minFreeBytes {
+			return fmt.Errorf("only %v bytes available on %v, need %v", free, path, minFreeBytes)
+		}
+
+		if total > 0 {
+			percent := float64(free) / float64(total) * 100
+			if percent < minFreePercent {
+				return fmt.Errorf("only %.1f%% available on %v, need %.1f%%", percent, path, minFreePercent)
+			}
+		}
+
+		return nil
+	}
+}
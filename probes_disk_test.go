@@ -0,0 +1,32 @@
+package health
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiskSpaceProbe(t *testing.T) {
+	probe := DiskSpaceProbe(os.TempDir(), 1, 0)
+
+	assert.NoError(t, probe())
+}
+
+func TestDiskSpaceProbe_err_minFreeBytes(t *testing.T) {
+	probe := DiskSpaceProbe(os.TempDir(), 1<<62, 0)
+
+	assert.Error(t, probe())
+}
+
+func TestDiskSpaceProbe_err_minFreePercent(t *testing.T) {
+	probe := DiskSpaceProbe(os.TempDir(), 0, 100.1)
+
+	assert.Error(t, probe())
+}
+
+func TestDiskSpaceProbe_err_missingPath(t *testing.T) {
+	probe := DiskSpaceProbe("/not/existing/path", 0, 0)
+
+	assert.Error(t, probe())
+}
@@ -0,0 +1,58 @@
+package health
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+const (
+	defaultExecTimeout = 10 * time.Second
+	// Max length of the stderr output used as reason
+	maxExecReasonLength = 512
+)
+
+// Runs an external command, e.g. a vendor provided health script, and fails if it exits with a non-zero exit code.
+// The trimmed stderr output of the command is used as reason. The command is killed after 10 seconds.
+//
+// Example:
+//		checker.AddReadinessProbe("license-server", health.ExecProbe("/opt/vendor/bin/check-license", "--quiet"))
+func ExecProbe(name string, args ...string) Probe {
+	return ExecProbeWithTimeout(defaultExecTimeout, name, args...)
+}
+
+// Same as ExecProbe, but kills the command after the given timeout.
+func ExecProbeWithTimeout(timeout time.Duration, name string, args ...string) Probe {
+	return func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		var stderr bytes.Buffer
+		// #nosec G204
+		cmd := exec.CommandContext(ctx, name, args...)
+		cmd.Stderr = &stderr
+
+		err := cmd.Run()
+		if ctx.Err() == context.DeadlineExceeded {
+			return classify(ErrTimeout, fmt.Errorf("%v timed out after %v", name, timeout))
+		}
+
+		if err != nil {
+			reason := strings.TrimSpace(stderr.String())
+			if len(reason) > maxExecReasonLength {
+				reason = reason[:maxExecReasonLength] + "..."
+			}
+
+			if reason == "" {
+				return fmt.Errorf("%v failed: %w", name, err)
+			}
+
+			return fmt.Errorf("%v failed: %w: %v", name, err, reason)
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,49 @@
+package health
+
+import (
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func skipWithoutShell(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a posix shell")
+	}
+}
+
+func TestExecProbe(t *testing.T) {
+	skipWithoutShell(t)
+
+	probe := ExecProbe("sh", "-c", "exit 0")
+
+	assert.NoError(t, probe())
+}
+
+func TestExecProbe_err_exitCode(t *testing.T) {
+	skipWithoutShell(t)
+
+	probe := ExecProbe("sh", "-c", "echo '  license expired  ' >&2; exit 3")
+
+	err := probe()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "exit status 3: license expired")
+}
+
+func TestExecProbe_err_missingCommand(t *testing.T) {
+	probe := ExecProbe("not-existing-healthchecker-command")
+
+	assert.Error(t, probe())
+}
+
+func TestExecProbeWithTimeout_err_timeout(t *testing.T) {
+	skipWithoutShell(t)
+
+	probe := ExecProbeWithTimeout(50*time.Millisecond, "sleep", "5")
+
+	err := probe()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "timed out")
+}
@@ -0,0 +1,89 @@
+package health
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+)
+
+// A FileAccess selects the kind of access verified by a FileProbe. Values can be combined.
+type FileAccess int
+
+// Only verify the path exists
+const FileExists FileAccess = 0
+
+const (
+	// Verify the file or directory can be opened for reading
+	FileReadable FileAccess = 1 << iota
+	// Verify the file can be opened for writing. For directories a temporary file is created and removed again.
+	FileWritable
+)
+
+// Checks a file or directory exists and grants the requested access, e.g. for NFS mounts, secret volumes or spool directories.
+//
+// Example:
+//		checker.AddReadinessProbe("spool", health.FileProbe("/var/spool/app", health.FileReadable|health.FileWritable))
+func FileProbe(path string, access FileAccess) Probe {
+	return func() error {
+		info, err := os.Stat(path)
+		if err != nil {
+			return fmt.Errorf("could not access %v: %w", path, err)
+		}
+
+		if access&FileReadable != 0 {
+			if err := checkReadable(path, info); err != nil {
+				return fmt.Errorf("%v is not readable: %w", path, err)
+			}
+		}
+
+		if access&FileWritable != 0 {
+			if err := checkWritable(path, info); err != nil {
+				return fmt.Errorf("%v is not writable: %w", path, err)
+			}
+		}
+
+		return nil
+	}
+}
+
+func checkReadable(path string, info os.FileInfo) error {
+	// #nosec G304
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if info.IsDir() {
+		_, err = f.Readdirnames(1)
+		if err != nil && err != io.EOF {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func checkWritable(path string, info os.FileInfo) error {
+	if !info.IsDir() {
+		// #nosec G302 G304
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+		if err != nil {
+			return err
+		}
+
+		return f.Close()
+	}
+
+	f, err := ioutil.TempFile(path, ".healthcheck-")
+	if err != nil {
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return os.Remove(f.Name())
+}
@@ -0,0 +1,52 @@
+package health
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileProbe(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "healthchecker")
+	defer os.RemoveAll(dir)
+
+	probe := FileProbe(dir, FileReadable|FileWritable)
+
+	assert.NoError(t, probe())
+
+	files, _ := ioutil.ReadDir(dir)
+	assert.Empty(t, files)
+}
+
+func TestFileProbe_file(t *testing.T) {
+	f, _ := ioutil.TempFile("", "healthchecker")
+	_ = f.Close()
+	defer os.Remove(f.Name())
+
+	probe := FileProbe(f.Name(), FileReadable|FileWritable)
+
+	assert.NoError(t, probe())
+}
+
+func TestFileProbe_err_missing(t *testing.T) {
+	probe := FileProbe(filepath.Join(os.TempDir(), "not-existing-healthchecker-file"), FileExists)
+
+	assert.Error(t, probe())
+}
+
+func TestFileProbe_err_notWritable(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("permissions are not enforced for root")
+	}
+
+	dir, _ := ioutil.TempDir("", "healthchecker")
+	defer os.RemoveAll(dir)
+	_ = os.Chmod(dir, 0500)
+
+	probe := FileProbe(dir, FileWritable)
+
+	assert.Error(t, probe())
+}
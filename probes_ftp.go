@@ -0,0 +1,160 @@
+package health
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/textproto"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+const defaultFTPTimeout = 10 * time.Second
+
+// Matches the address of the data connection in replies to EPSV and PASV
+var (
+	ftpEPSVReply = regexp.MustCompile(`\(\|\|\|(\d+)\|\)`)
+	ftpPASVReply = regexp.MustCompile(`(\d+),(\d+),(\d+),(\d+),(\d+),(\d+)`)
+)
+
+// Checks an FTP server for readiness by logging in and listing dir, for services exchanging files with partners.
+// Logs in anonymously if user is empty. Fails if the session is not completed within timeout, which defaults to
+// 10 seconds if 0.
+//
+// Example:
+//		checker.AddReadinessProbe("partner-ftp", health.FTPProbe("ftp.partner.example:21", "user", "secret", "/inbox", 0))
+func FTPProbe(addr, user, password, dir string, timeout time.Duration) Probe {
+	if timeout == 0 {
+		timeout = defaultFTPTimeout
+	}
+
+	if user == "" {
+		user, password = "anonymous", "anonymous"
+	}
+
+	return func() error {
+		deadline := time.Now().Add(timeout)
+
+		conn, err := net.DialTimeout("tcp", addr, timeout)
+		if err != nil {
+			return classify(ErrUnreachable, fmt.Errorf("ftp server could not be reached: %w", err))
+		}
+		defer conn.Close()
+		_ = conn.SetDeadline(deadline)
+
+		c := textproto.NewConn(conn)
+		if _, _, err := c.ReadResponse(220); err != nil {
+			return classify(ErrUnhealthy, fmt.Errorf("ftp server is not ready: %w", err))
+		}
+
+		if err := ftpLogin(c, user, password); err != nil {
+			return err
+		}
+
+		if dir != "" {
+			code, msg, err := ftpCmd(c, "CWD %v", dir)
+			if err != nil {
+				return err
+			}
+			if code != 250 {
+				return classify(ErrUnhealthy, fmt.Errorf("could not change to %v: %v %v", dir, code, msg))
+			}
+		}
+
+		host, _, _ := net.SplitHostPort(conn.RemoteAddr().String())
+		dataAddr, err := ftpPassive(c, host)
+		if err != nil {
+			return err
+		}
+
+		data, err := net.DialTimeout("tcp", dataAddr, time.Until(deadline))
+		if err != nil {
+			return classify(ErrUnreachable, fmt.Errorf("could not open data connection: %w", err))
+		}
+		defer data.Close()
+		_ = data.SetDeadline(deadline)
+
+		code, msg, err := ftpCmd(c, "NLST")
+		if err != nil {
+			return err
+		}
+		if code != 125 && code != 150 {
+			return classify(ErrUnhealthy, fmt.Errorf("could not list directory: %v %v", code, msg))
+		}
+
+		if _, err := io.Copy(ioutil.Discard, data); err != nil {
+			return fmt.Errorf("could not read listing: %w", err)
+		}
+
+		if _, _, err := c.ReadResponse(2); err != nil {
+			return classify(ErrUnhealthy, fmt.Errorf("listing failed: %w", err))
+		}
+
+		_, _, _ = ftpCmd(c, "QUIT")
+
+		return nil
+	}
+}
+
+func ftpLogin(c *textproto.Conn, user, password string) error {
+	code, msg, err := ftpCmd(c, "USER %v", user)
+	if err != nil {
+		return err
+	}
+
+	if code == 331 || code == 332 {
+		if code, msg, err = ftpCmd(c, "PASS %v", password); err != nil {
+			return err
+		}
+	}
+
+	if code != 230 && code != 202 {
+		return classify(ErrUnauthorized, fmt.Errorf("ftp login failed: %v %v", code, msg))
+	}
+
+	return nil
+}
+
+// Sends a command and reads the reply. Only fails if the reply could not be read, so callers check the code.
+func ftpCmd(c *textproto.Conn, format string, args ...interface{}) (int, string, error) {
+	if err := c.PrintfLine(format, args...); err != nil {
+		return 0, "", classify(ErrUnreachable, fmt.Errorf("ftp connection failed: %w", err))
+	}
+
+	code, msg, err := c.ReadResponse(0)
+	if _, isReply := err.(*textproto.Error); err != nil && !isReply {
+		return 0, "", classify(ErrUnreachable, fmt.Errorf("ftp connection failed: %w", err))
+	}
+
+	return code, msg, nil
+}
+
+// Returns the address of the data connection, preferring EPSV and falling back to PASV. The data connection is opened
+// to host of the control connection, the address in a PASV reply is ignored as it may be internal to a NAT.
+func ftpPassive(c *textproto.Conn, host string) (string, error) {
+	code, msg, err := ftpCmd(c, "EPSV")
+	if err != nil {
+		return "", err
+	}
+
+	if m := ftpEPSVReply.FindStringSubmatch(msg); code == 229 && m != nil {
+		return net.JoinHostPort(host, m[1]), nil
+	}
+
+	code, msg, err = ftpCmd(c, "PASV")
+	if err != nil {
+		return "", err
+	}
+
+	m := ftpPASVReply.FindStringSubmatch(msg)
+	if code != 227 || m == nil {
+		return "", classify(ErrUnhealthy, fmt.Errorf("could not enter passive mode: %v %v", code, msg))
+	}
+
+	hi, _ := strconv.Atoi(m[5])
+	lo, _ := strconv.Atoi(m[6])
+
+	return net.JoinHostPort(host, strconv.Itoa(hi<<8|lo)), nil
+}
@@ -0,0 +1,126 @@
+package health
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/textproto"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Serves a minimal FTP session with the directory /inbox, accepting the password "secret".
+func ftpServer(t *testing.T) string {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	t.Cleanup(func() { _ = lis.Close() })
+
+	go func() {
+		for {
+			conn, err := lis.Accept()
+			if err != nil {
+				return
+			}
+
+			go serveFTP(conn)
+		}
+	}()
+
+	return lis.Addr().String()
+}
+
+func serveFTP(conn net.Conn) {
+	defer conn.Close()
+
+	c := textproto.NewConn(conn)
+	_ = c.PrintfLine("220 ready")
+
+	var data net.Listener
+	for {
+		line, err := c.ReadLine()
+		if err != nil {
+			return
+		}
+
+		var cmd, arg string
+		_, _ = fmt.Sscan(line, &cmd, &arg)
+
+		switch cmd {
+		case "USER":
+			_ = c.PrintfLine("331 password required")
+		case "PASS":
+			if arg != "secret" {
+				_ = c.PrintfLine("530 login incorrect")
+				continue
+			}
+			_ = c.PrintfLine("230 logged in")
+		case "CWD":
+			if arg != "/inbox" {
+				_ = c.PrintfLine("550 no such directory")
+				continue
+			}
+			_ = c.PrintfLine("250 ok")
+		case "EPSV":
+			data, _ = net.Listen("tcp", "127.0.0.1:0")
+			_ = c.PrintfLine("229 Entering Extended Passive Mode (|||%v|)", data.Addr().(*net.TCPAddr).Port)
+		case "NLST":
+			_ = c.PrintfLine("150 listing")
+			dc, err := data.Accept()
+			if err != nil {
+				return
+			}
+			_, _ = fmt.Fprint(dc, "orders.csv\r\n")
+			_ = dc.Close()
+			_ = data.Close()
+			_ = c.PrintfLine("226 done")
+		case "QUIT":
+			_ = c.PrintfLine("221 bye")
+			return
+		default:
+			_ = c.PrintfLine("502 not implemented")
+		}
+	}
+}
+
+func TestFTPProbe(t *testing.T) {
+	addr := ftpServer(t)
+
+	assert.NoError(t, FTPProbe(addr, "partner", "secret", "/inbox", time.Second)())
+
+	err := FTPProbe(addr, "partner", "wrong", "/inbox", time.Second)()
+	assert.EqualError(t, err, "ftp login failed: 530 login incorrect")
+	assert.True(t, errors.Is(err, ErrUnauthorized))
+
+	err = FTPProbe(addr, "partner", "secret", "/outbox", time.Second)()
+	assert.EqualError(t, err, "could not change to /outbox: 550 no such directory")
+	assert.True(t, errors.Is(err, ErrUnhealthy))
+}
+
+func TestFTPProbe_unreachable(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	addr := lis.Addr().String()
+	_ = lis.Close()
+
+	assert.True(t, errors.Is(FTPProbe(addr, "", "", "", time.Second)(), ErrUnreachable))
+}
+
+func TestFTPPassive_pasv(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	go func() {
+		defer server.Close()
+
+		s := textproto.NewConn(server)
+		_, _ = s.ReadLine()
+		_ = s.PrintfLine("502 not implemented")
+		_, _ = s.ReadLine()
+		_ = s.PrintfLine("227 Entering Passive Mode (10,0,0,1,4,1)")
+	}()
+
+	addr, err := ftpPassive(textproto.NewConn(client), "ftp.example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, "ftp.example.com:1025", addr, "the host of the control connection is used")
+}
@@ -0,0 +1,115 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+)
+
+// Resolves all A and AAAA records of the endpoint's host and requests each address, passing if at least minimum
+// of them respond healthy, see MinimumReady. Reflects the availability behind a headless Kubernetes service instead
+// of the single address picked by the client. The requests keep the host name for the Host header and TLS.
+// Accepts the same options as HTTPProbe; the transport of a client given by HTTPClient is cloned per address. As the
+// addresses are dialed directly, proxies given by HTTPProxy or the environment are not used.
+//
+// Example:
+//		checker.AddReadinessProbe("search", health.HTTPMultiTargetProbe("http://search-headless:9200/_cluster/health", 2,
+//			health.HTTPTimeout(2*time.Second)))
+func HTTPMultiTargetProbe(endpoint string, minimum int, opts ...HTTPOption) Probe {
+	c := &httpProbeConfig{client: &http.Client{Transport: SharedTransport}, header: http.Header{}}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return func() error {
+			return fmt.Errorf("invalid endpoint: %w", err)
+		}
+	}
+
+	port := u.Port()
+	if port == "" {
+		port = "80"
+		if u.Scheme == "https" {
+			port = "443"
+		}
+	}
+
+	// Probes and transports per address, kept between evaluations to reuse their connections. Addresses no longer
+	// resolved are evicted.
+	type target struct {
+		probe     Probe
+		transport *http.Transport
+	}
+
+	var mu sync.Mutex
+	targets := map[string]*target{}
+	probesFor := func(addrs []net.IPAddr) map[string]Probe {
+		mu.Lock()
+		defer mu.Unlock()
+
+		probes := make(map[string]Probe, len(addrs))
+		for _, a := range addrs {
+			ip := a.IP.String()
+			if t, ok := targets[ip]; ok {
+				probes[ip] = t.probe
+				continue
+			}
+
+			transport := http.DefaultTransport.(*http.Transport).Clone()
+			if t, ok := c.client.Transport.(*http.Transport); ok {
+				transport = t.Clone()
+			}
+			transport.Proxy = nil
+
+			addr := net.JoinHostPort(ip, port)
+			dialer := &net.Dialer{}
+			transport.DialContext = func(ctx context.Context, network, _ string) (net.Conn, error) {
+				return dialer.DialContext(ctx, network, addr)
+			}
+
+			client := *c.client
+			client.Transport = transport
+			ipOpts := append(append([]HTTPOption{}, opts...), HTTPClient(&client), withoutProxy())
+
+			targets[ip] = &target{probe: HTTPProbe(endpoint, ipOpts...), transport: transport}
+			probes[ip] = targets[ip].probe
+		}
+
+		for ip, t := range targets {
+			if _, ok := probes[ip]; !ok {
+				t.transport.CloseIdleConnections()
+				delete(targets, ip)
+			}
+		}
+
+		return probes
+	}
+
+	return func() error {
+		ctx := context.Background()
+		if c.timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, c.timeout)
+			defer cancel()
+		}
+
+		addrs, err := net.DefaultResolver.LookupIPAddr(ctx, u.Hostname())
+		if err != nil {
+			return classify(ErrUnreachable, fmt.Errorf("could not resolve %v: %w", u.Hostname(), err))
+		}
+
+		return MinimumReady(minimum, probesFor(addrs))()
+	}
+}
+
+// Drops a proxy given by HTTPProxy, so HTTPProbe uses the transport of its client as it is.
+func withoutProxy() HTTPOption {
+	return func(c *httpProbeConfig) {
+		c.proxy = nil
+	}
+}
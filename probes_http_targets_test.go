@@ -0,0 +1,37 @@
+package health
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTTPMultiTargetProbe(t *testing.T) {
+	var hosts []string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hosts = append(hosts, r.Host)
+		w.WriteHeader(200)
+	}))
+	defer s.Close()
+
+	u, _ := url.Parse(s.URL)
+	endpoint := "http://localhost:" + u.Port() + "/health"
+
+	assert.NoError(t, HTTPMultiTargetProbe(endpoint, 1)())
+	assert.Contains(t, hosts, "localhost:"+u.Port())
+
+	err := HTTPMultiTargetProbe(endpoint, 3)()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "3 required")
+
+	assert.Error(t, HTTPMultiTargetProbe("http://unknown.invalid/health", 1)())
+
+	// The addresses are dialed directly instead of through a proxy
+	unreachable, _ := url.Parse("http://127.0.0.1:1")
+	probe := HTTPMultiTargetProbe(endpoint, 1, HTTPProxy(unreachable))
+	assert.NoError(t, probe())
+	assert.NoError(t, probe())
+}
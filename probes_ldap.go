@@ -0,0 +1,59 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Interface matching a ldap connection's bind methods, e.g. `*ldap.Conn` of github.com/go-ldap/ldap.
+type LDAPBinder interface {
+	Bind(username, password string) error
+	UnauthenticatedBind(username string) error
+}
+
+// Checks a directory server for readiness. Opens a new connection using dial and performs
+// an anonymous bind, or a bind with the given service account if username is not empty.
+// Fails if connecting and binding takes longer than timeout.
+//
+// Example:
+//		dial := func() (health.LDAPBinder, error) {
+//			return ldap.DialURL("ldaps://ad.example.com:636")
+//		}
+//		checker.AddReadinessProbe("active-directory", health.LDAPProbe(dial, "cn=svc,dc=example,dc=com", "secret", 5*time.Second))
+func LDAPProbe(dial func() (LDAPBinder, error), username, password string, timeout time.Duration) Probe {
+	runner := &boundedRunner{}
+
+	return func() error {
+		return runner.runUntil(context.Background(), time.After(timeout), timeout, func() error {
+			conn, err := dial()
+			if err != nil {
+				return classify(ErrUnreachable, fmt.Errorf("directory server could not be reached: %w", err))
+			}
+			defer closeLDAPConn(conn)
+
+			if username == "" {
+				err = conn.UnauthenticatedBind("")
+			} else {
+				err = conn.Bind(username, password)
+			}
+
+			if err != nil {
+				return classify(ErrUnauthorized, fmt.Errorf("ldap bind failed: %w", err))
+			}
+
+			return nil
+		})
+	}
+}
+
+// Closes conn if supported. Different versions of go-ldap differ in the signature of Close.
+func closeLDAPConn(conn LDAPBinder) {
+	switch c := conn.(type) {
+	case io.Closer:
+		_ = c.Close()
+	case interface{ Close() }:
+		c.Close()
+	}
+}
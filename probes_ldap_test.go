@@ -0,0 +1,75 @@
+package health
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type MockLDAPBinder struct {
+	username string
+	err      error
+	delay    time.Duration
+	closed   bool
+}
+
+func (m *MockLDAPBinder) Bind(username, _ string) error {
+	time.Sleep(m.delay)
+	m.username = username
+	return m.err
+}
+
+func (m *MockLDAPBinder) UnauthenticatedBind(username string) error {
+	return m.Bind(username, "")
+}
+
+func (m *MockLDAPBinder) Close() {
+	m.closed = true
+}
+
+func TestLDAPProbe(t *testing.T) {
+	binder := &MockLDAPBinder{}
+	dial := func() (LDAPBinder, error) { return binder, nil }
+
+	probe := LDAPProbe(dial, "cn=svc", "secret", time.Second)
+
+	assert.NoError(t, probe())
+	assert.Equal(t, "cn=svc", binder.username)
+	assert.True(t, binder.closed)
+}
+
+func TestLDAPProbe_anonymous(t *testing.T) {
+	binder := &MockLDAPBinder{}
+	dial := func() (LDAPBinder, error) { return binder, nil }
+
+	probe := LDAPProbe(dial, "", "", time.Second)
+
+	assert.NoError(t, probe())
+	assert.Empty(t, binder.username)
+}
+
+func TestLDAPProbe_err_bind(t *testing.T) {
+	dial := func() (LDAPBinder, error) { return &MockLDAPBinder{err: errors.New("invalid credentials")}, nil }
+
+	probe := LDAPProbe(dial, "cn=svc", "wrong", time.Second)
+
+	assert.Error(t, probe())
+}
+
+func TestLDAPProbe_err_dial(t *testing.T) {
+	dial := func() (LDAPBinder, error) { return nil, errors.New("connection refused") }
+
+	probe := LDAPProbe(dial, "", "", time.Second)
+
+	assert.Error(t, probe())
+}
+
+func TestLDAPProbe_err_timeout(t *testing.T) {
+	dial := func() (LDAPBinder, error) { return &MockLDAPBinder{delay: time.Second}, nil }
+
+	probe := LDAPProbe(dial, "", "", 10*time.Millisecond)
+
+	assert.Error(t, probe())
+}
@@ -0,0 +1,222 @@
+package health
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	defaultLeaseTimeout = 5 * time.Second
+	serviceAccountDir   = "/var/run/secrets/kubernetes.io/serviceaccount/"
+)
+
+type leaseProbeConfig struct {
+	server    string
+	token     string
+	namespace string
+	identity  string
+	client    *http.Client
+	timeout   time.Duration
+	// Whether the token of the service account is used
+	readToken bool
+}
+
+// A LeaseOption configures a KubernetesLeaseProbe.
+type LeaseOption func(c *leaseProbeConfig)
+
+// Sets the URL of the Kubernetes API server and the bearer token used to read the lease.
+// Defaults to the in-cluster API server and the token of the service account of the pod.
+func LeaseAPIServer(server, token string) LeaseOption {
+	return func(c *leaseProbeConfig) {
+		c.server = server
+		c.token = token
+	}
+}
+
+// Sets the namespace of the lease. Defaults to the namespace of the pod.
+func LeaseNamespace(namespace string) LeaseOption {
+	return func(c *leaseProbeConfig) {
+		c.namespace = namespace
+	}
+}
+
+// Sets the identity the instance acquires the lease with. Defaults to the hostname, which is the name of the pod.
+func LeaseIdentity(identity string) LeaseOption {
+	return func(c *leaseProbeConfig) {
+		c.identity = identity
+	}
+}
+
+// Sets the client used to query the API server. Defaults to a client trusting the CA of the service account.
+func LeaseClient(client *http.Client) LeaseOption {
+	return func(c *leaseProbeConfig) {
+		c.client = client
+	}
+}
+
+// Sets the timeout for reading the lease. Defaults to 5 seconds.
+func LeaseTimeout(d time.Duration) LeaseOption {
+	return func(c *leaseProbeConfig) {
+		c.timeout = d
+	}
+}
+
+type lease struct {
+	Spec struct {
+		HolderIdentity       string     `json:"holderIdentity"`
+		LeaseDurationSeconds int        `json:"leaseDurationSeconds"`
+		RenewTime            *time.Time `json:"renewTime"`
+	} `json:"spec"`
+}
+
+// Checks the `coordination.k8s.io` Lease used for leader election. Passes on the leader holding the lease, while
+// followers report StatusStandby, which keeps them ready without showing them as degraded. Fails if no instance
+// holds a valid lease, as the leader stopped renewing it or no leader was elected.
+//
+// Example:
+//		checker.AddReadinessProbe("leader", health.KubernetesLeaseProbe("my-controller"))
+func KubernetesLeaseProbe(name string, opts ...LeaseOption) Probe {
+	cfg := &leaseProbeConfig{timeout: defaultLeaseTimeout}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	// Reported on every run, so the probe fails instead of the service outside of a cluster
+	setupErr := cfg.inCluster()
+
+	return func() error {
+		if setupErr != nil {
+			return setupErr
+		}
+
+		token := cfg.token
+		if cfg.readToken {
+			// The token of the service account is rotated, so it is read on every run
+			b, err := ioutil.ReadFile(serviceAccountDir + "token")
+			if err != nil {
+				return fmt.Errorf("could not read service account token: %w", err)
+			}
+			token = strings.TrimSpace(string(b))
+		}
+
+		l, err := cfg.get(name, token)
+		if err != nil {
+			return err
+		}
+
+		holder := l.Spec.HolderIdentity
+		if holder == "" {
+			return classify(ErrUnhealthy, fmt.Errorf("lease %v is not held by any instance", name))
+		}
+
+		if l.Spec.RenewTime != nil && l.Spec.LeaseDurationSeconds > 0 {
+			expiry := l.Spec.RenewTime.Add(time.Duration(l.Spec.LeaseDurationSeconds) * time.Second)
+			if time.Now().After(expiry) {
+				return classify(ErrUnhealthy, fmt.Errorf("lease %v held by %v expired at %v", name, holder, expiry.Format(time.RFC3339)))
+			}
+		}
+
+		if holder != cfg.identity {
+			return Standby(fmt.Errorf("lease %v is held by %v", name, holder))
+		}
+
+		return nil
+	}
+}
+
+// Fills the unset options from the environment of the pod.
+func (c *leaseProbeConfig) inCluster() error {
+	if c.identity == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return fmt.Errorf("could not determine identity: %w", err)
+		}
+		c.identity = hostname
+	}
+
+	if c.namespace == "" {
+		b, err := ioutil.ReadFile(serviceAccountDir + "namespace")
+		if err != nil {
+			return fmt.Errorf("could not determine namespace: %w", err)
+		}
+		c.namespace = strings.TrimSpace(string(b))
+	}
+
+	if c.server == "" {
+		host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+		if host == "" || port == "" {
+			return fmt.Errorf("not running in a kubernetes cluster: KUBERNETES_SERVICE_HOST and KUBERNETES_SERVICE_PORT are not set")
+		}
+		c.server = "https://" + net.JoinHostPort(host, port)
+		c.readToken = true
+
+		if c.client == nil {
+			ca, err := ioutil.ReadFile(serviceAccountDir + "ca.crt")
+			if err != nil {
+				return fmt.Errorf("could not read service account CA: %w", err)
+			}
+
+			roots := x509.NewCertPool()
+			roots.AppendCertsFromPEM(ca)
+			c.client = &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: roots}}}
+		}
+	}
+
+	if c.client == nil {
+		c.client = &http.Client{}
+	}
+
+	return nil
+}
+
+// Reads the lease from the API server.
+func (c *leaseProbeConfig) get(name, token string) (*lease, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	endpoint := fmt.Sprintf("%v/apis/coordination.k8s.io/v1/namespaces/%v/leases/%v",
+		strings.TrimSuffix(c.server, "/"), url.PathEscape(c.namespace), url.PathEscape(name))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, classify(ErrUnreachable, fmt.Errorf("kubernetes api could not be reached: %w", err))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		_, _ = io.Copy(ioutil.Discard, resp.Body)
+		err := fmt.Errorf("could not read lease %v: %v", name, resp.Status)
+		if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+			return nil, classify(ErrUnauthorized, err)
+		}
+
+		return nil, classify(ErrUnhealthy, err)
+	}
+
+	var l lease
+	if err := json.NewDecoder(resp.Body).Decode(&l); err != nil {
+		return nil, fmt.Errorf("invalid lease: %w", err)
+	}
+
+	return &l, nil
+}
@@ -0,0 +1,60 @@
+package health
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func leaseServer(t *testing.T, holder string, renewed time.Time) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/apis/coordination.k8s.io/v1/namespaces/default/leases/controller", r.URL.Path)
+
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		fmt.Fprintf(w, `{"spec":{"holderIdentity":%q,"leaseDurationSeconds":15,"renewTime":%q}}`, holder, renewed.Format(time.RFC3339Nano))
+	}))
+}
+
+func TestKubernetesLeaseProbe(t *testing.T) {
+	server := leaseServer(t, "pod-a", time.Now())
+	defer server.Close()
+
+	leader := KubernetesLeaseProbe("controller", LeaseAPIServer(server.URL, "secret"), LeaseNamespace("default"), LeaseIdentity("pod-a"))
+	assert.NoError(t, leader())
+
+	follower := KubernetesLeaseProbe("controller", LeaseAPIServer(server.URL, "secret"), LeaseNamespace("default"), LeaseIdentity("pod-b"))
+	err := follower()
+	assert.EqualError(t, err, "lease controller is held by pod-a")
+	assert.Equal(t, StatusStandby, probeStatusOf(err, true))
+	assert.Equal(t, KindStandby, reasonKindOf(err))
+
+	unauthorized := KubernetesLeaseProbe("controller", LeaseAPIServer(server.URL, "wrong"), LeaseNamespace("default"), LeaseIdentity("pod-a"))
+	assert.True(t, errors.Is(unauthorized(), ErrUnauthorized))
+}
+
+func TestKubernetesLeaseProbe_expired(t *testing.T) {
+	server := leaseServer(t, "pod-a", time.Now().Add(-time.Minute))
+	defer server.Close()
+
+	probe := KubernetesLeaseProbe("controller", LeaseAPIServer(server.URL, "secret"), LeaseNamespace("default"), LeaseIdentity("pod-b"))
+	err := probe()
+	assert.True(t, errors.Is(err, ErrUnhealthy))
+	assert.Equal(t, StatusFail, probeStatusOf(err, true))
+}
+
+func TestKubernetesLeaseProbe_noLeader(t *testing.T) {
+	server := leaseServer(t, "", time.Now())
+	defer server.Close()
+
+	probe := KubernetesLeaseProbe("controller", LeaseAPIServer(server.URL, "secret"), LeaseNamespace("default"), LeaseIdentity("pod-a"))
+	assert.EqualError(t, probe(), "lease controller is not held by any instance")
+}
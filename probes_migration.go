@@ -0,0 +1,72 @@
+package health
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// Interface reporting the current schema version of a database, matching `*migrate.Migrate` of golang-migrate.
+type MigrationVersionReporter interface {
+	Version() (version uint, dirty bool, err error)
+}
+
+// Checks the database schema has been migrated to at least the version the binary was built for.
+// Fails if the schema is older or a migration failed half-way (dirty).
+// Newer versions are accepted, so instances of a previous release stay ready during a rolling update.
+//
+// Example:
+//		m, _ := migrate.New("file://migrations", dsn)
+//		checker.AddReadinessProbe("schema", health.MigrationProbe(m, 42))
+func MigrationProbe(r MigrationVersionReporter, expected uint) Probe {
+	return func() error {
+		version, dirty, err := r.Version()
+		if err != nil {
+			return fmt.Errorf("could not get schema version: %w", err)
+		}
+
+		if dirty {
+			return fmt.Errorf("schema version %v is dirty", version)
+		}
+
+		if version < expected {
+			return fmt.Errorf("schema version %v is older than required version %v", version, expected)
+		}
+
+		return nil
+	}
+}
+
+type sqlMigrationTable struct {
+	db    *sql.DB
+	table string
+}
+
+// Reads the schema version from a migration table as written by golang-migrate, e.g. `schema_migrations`,
+// without requiring the migrate library at runtime.
+//
+// Example:
+//		checker.AddReadinessProbe("schema", health.MigrationProbe(health.SQLMigrationTable(db, "schema_migrations"), 42))
+func SQLMigrationTable(db *sql.DB, table string) MigrationVersionReporter {
+	return &sqlMigrationTable{db: db, table: table}
+}
+
+func (t *sqlMigrationTable) Version() (uint, bool, error) {
+	var version int64
+	var dirty bool
+
+	// #nosec G202
+	err := t.db.QueryRow("SELECT version, dirty FROM "+t.table+" LIMIT 1").Scan(&version, &dirty)
+	if err == sql.ErrNoRows {
+		return 0, false, fmt.Errorf("no migration applied")
+	}
+
+	if err != nil {
+		return 0, false, err
+	}
+
+	if version < 0 {
+		return 0, false, fmt.Errorf("invalid version %v", version)
+	}
+
+	return uint(version), dirty, nil
+}
@@ -0,0 +1,48 @@
+package health
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type MockMigrationVersionReporter struct {
+	version uint
+	dirty   bool
+	err     error
+}
+
+func (m MockMigrationVersionReporter) Version() (uint, bool, error) {
+	return m.version, m.dirty, m.err
+}
+
+func TestMigrationProbe(t *testing.T) {
+	probe := MigrationProbe(&MockMigrationVersionReporter{version: 42}, 42)
+
+	assert.NoError(t, probe())
+}
+
+func TestMigrationProbe_newerSchema(t *testing.T) {
+	probe := MigrationProbe(&MockMigrationVersionReporter{version: 43}, 42)
+
+	assert.NoError(t, probe())
+}
+
+func TestMigrationProbe_failsForOlderSchema(t *testing.T) {
+	probe := MigrationProbe(&MockMigrationVersionReporter{version: 41}, 42)
+
+	assert.Error(t, probe())
+}
+
+func TestMigrationProbe_failsForDirtySchema(t *testing.T) {
+	probe := MigrationProbe(&MockMigrationVersionReporter{version: 42, dirty: true}, 42)
+
+	assert.Error(t, probe())
+}
+
+func TestMigrationProbe_failsForErrorDuringLookup(t *testing.T) {
+	probe := MigrationProbe(&MockMigrationVersionReporter{err: fmt.Errorf("no migration")}, 42)
+
+	assert.Error(t, probe())
+}
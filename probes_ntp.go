@@ -0,0 +1,130 @@
+package health
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"time"
+)
+
+const (
+	defaultNTPTimeout = 5 * time.Second
+	defaultNTPPort    = "123"
+
+	ntpPacketSize = 48
+	// Seconds between the NTP epoch (1900) and the unix epoch (1970)
+	ntpEpochOffset = 2208988800
+)
+
+type ntpProbeConfig struct {
+	timeout time.Duration
+}
+
+// A NTPOption configures a NTPProbe.
+type NTPOption func(c *ntpProbeConfig)
+
+// Sets the time to wait for the server's response. Defaults to 5 seconds.
+func NTPTimeout(d time.Duration) NTPOption {
+	return func(c *ntpProbeConfig) {
+		c.timeout = d
+	}
+}
+
+// Queries a NTP server and fails if the local clock drifts more than maxDrift from the server's clock.
+// The port defaults to 123 if server contains no port.
+//
+// Example:
+//		checker.AddReadinessProbe("clock", health.NTPProbe("pool.ntp.org", 500*time.Millisecond))
+func NTPProbe(server string, maxDrift time.Duration, opts ...NTPOption) Probe {
+	c := &ntpProbeConfig{
+		timeout: defaultNTPTimeout,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	addr := server
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		addr = net.JoinHostPort(server, defaultNTPPort)
+	}
+
+	return func() error {
+		offset, err := queryNTPOffset(addr, c.timeout)
+		if err != nil {
+			return err
+		}
+
+		if offset > maxDrift || offset < -maxDrift {
+			return fmt.Errorf("clock drift of %v exceeds %v", offset, maxDrift)
+		}
+
+		return nil
+	}
+}
+
+// Sends a SNTP request to addr and returns the offset of the server's clock to the local clock.
+func queryNTPOffset(addr string, timeout time.Duration) (time.Duration, error) {
+	conn, err := net.DialTimeout("udp", addr, timeout)
+	if err != nil {
+		return 0, classify(ErrUnreachable, fmt.Errorf("ntp server could not be reached: %w", err))
+	}
+	defer conn.Close()
+
+	if err := conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return 0, err
+	}
+
+	req := make([]byte, ntpPacketSize)
+	// LI = 0 (no warning), VN = 4, Mode = 3 (client)
+	req[0] = 0<<6 | 4<<3 | 3
+
+	sent := time.Now()
+	binary.BigEndian.PutUint64(req[40:], toNTPTime(sent))
+
+	if _, err := conn.Write(req); err != nil {
+		return 0, fmt.Errorf("could not send ntp request: %w", err)
+	}
+
+	resp := make([]byte, ntpPacketSize)
+	n, err := conn.Read(resp)
+	if err != nil {
+		return 0, classify(ErrUnreachable, fmt.Errorf("no response from ntp server: %w", err))
+	}
+	received := time.Now()
+
+	if n < ntpPacketSize {
+		return 0, fmt.Errorf("invalid ntp response of %v bytes", n)
+	}
+
+	if leap := resp[0] >> 6; leap == 3 {
+		return 0, classify(ErrUnhealthy, fmt.Errorf("ntp server clock is not synchronized"))
+	}
+
+	if mode := resp[0] & 0x7; mode != 4 {
+		return 0, fmt.Errorf("invalid ntp response mode: %v", mode)
+	}
+
+	if stratum := resp[1]; stratum == 0 {
+		return 0, fmt.Errorf("ntp server sent kiss-of-death: %q", resp[12:16])
+	}
+
+	serverReceived := fromNTPTime(binary.BigEndian.Uint64(resp[32:]))
+	serverSent := fromNTPTime(binary.BigEndian.Uint64(resp[40:]))
+
+	return (serverReceived.Sub(sent) + serverSent.Sub(received)) / 2, nil
+}
+
+func toNTPTime(t time.Time) uint64 {
+	secs := uint64(t.Unix() + ntpEpochOffset)
+	frac := uint64(t.Nanosecond()) << 32 / uint64(time.Second)
+
+	return secs<<32 | frac
+}
+
+func fromNTPTime(ts uint64) time.Time {
+	secs := int64(ts>>32) - ntpEpochOffset
+	nanos := int64((ts & 0xffffffff) * uint64(time.Second) >> 32)
+
+	return time.Unix(secs, nanos)
+}
@@ -0,0 +1,81 @@
+package health
+
+import (
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Starts a NTP server answering with the local time shifted by offset.
+func newNTPTestServer(t *testing.T, offset time.Duration, stratum byte) net.PacketConn {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go func() {
+		buf := make([]byte, ntpPacketSize)
+		for {
+			_, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+
+			resp := make([]byte, ntpPacketSize)
+			resp[0] = 4<<3 | 4
+			resp[1] = stratum
+			now := toNTPTime(time.Now().Add(offset))
+			binary.BigEndian.PutUint64(resp[32:], now)
+			binary.BigEndian.PutUint64(resp[40:], now)
+
+			_, _ = conn.WriteTo(resp, addr)
+		}
+	}()
+
+	return conn
+}
+
+func TestNTPProbe(t *testing.T) {
+	s := newNTPTestServer(t, 0, 1)
+	defer s.Close()
+
+	probe := NTPProbe(s.LocalAddr().String(), 100*time.Millisecond)
+
+	assert.NoError(t, probe())
+}
+
+func TestNTPProbe_err_drift(t *testing.T) {
+	s := newNTPTestServer(t, time.Hour, 1)
+	defer s.Close()
+
+	probe := NTPProbe(s.LocalAddr().String(), time.Second)
+
+	assert.Error(t, probe())
+}
+
+func TestNTPProbe_err_kissOfDeath(t *testing.T) {
+	s := newNTPTestServer(t, 0, 0)
+	defer s.Close()
+
+	probe := NTPProbe(s.LocalAddr().String(), time.Second)
+
+	assert.Error(t, probe())
+}
+
+func TestNTPProbe_err_noResponse(t *testing.T) {
+	conn, _ := net.ListenPacket("udp", "127.0.0.1:0")
+	defer conn.Close()
+
+	probe := NTPProbe(conn.LocalAddr().String(), time.Second, NTPTimeout(50*time.Millisecond))
+
+	assert.Error(t, probe())
+}
+
+func TestNTPTime_roundTrip(t *testing.T) {
+	now := time.Now()
+
+	assert.WithinDuration(t, now, fromNTPTime(toNTPTime(now)), time.Microsecond)
+}
@@ -0,0 +1,164 @@
+package health
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const defaultOIDCTimeout = 5 * time.Second
+
+type oidcDiscovery struct {
+	Issuer  string `json:"issuer"`
+	JWKSURI string `json:"jwks_uri"`
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// Checks an OpenID Connect issuer for readiness. Fetches the issuer's discovery document and its JWKS
+// and verifies at least one signing key can be parsed, as tokens can not be validated otherwise.
+// If client is nil, a client using the SharedTransport with a timeout of 5 seconds is used.
+//
+// Example:
+//		checker.AddReadinessProbe("idp", health.OIDCProbe("https://login.example.com/realms/main", nil))
+func OIDCProbe(issuer string, client *http.Client) Probe {
+	if client == nil {
+		client = &http.Client{Transport: SharedTransport, Timeout: defaultOIDCTimeout}
+	}
+
+	discoveryURL := strings.TrimSuffix(issuer, "/") + "/.well-known/openid-configuration"
+
+	return func() error {
+		var discovery oidcDiscovery
+		if err := getJSON(client, discoveryURL, &discovery); err != nil {
+			return fmt.Errorf("could not fetch discovery document: %w", err)
+		}
+
+		if strings.TrimSuffix(discovery.Issuer, "/") != strings.TrimSuffix(issuer, "/") {
+			return fmt.Errorf("discovery document is for issuer %q", discovery.Issuer)
+		}
+
+		if discovery.JWKSURI == "" {
+			return fmt.Errorf("discovery document contains no jwks_uri")
+		}
+
+		var jwks struct {
+			Keys []jsonWebKey `json:"keys"`
+		}
+		if err := getJSON(client, discovery.JWKSURI, &jwks); err != nil {
+			return fmt.Errorf("could not fetch jwks: %w", err)
+		}
+
+		for _, key := range jwks.Keys {
+			if key.Use != "" && key.Use != "sig" {
+				continue
+			}
+
+			if err := key.parse(); err == nil {
+				return nil
+			}
+		}
+
+		return fmt.Errorf("jwks contains no valid signing key")
+	}
+}
+
+func getJSON(client *http.Client, url string, v interface{}) error {
+	resp, err := client.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return classify(ErrUnhealthy, fmt.Errorf("unexpected status: %v", resp.Status))
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// Parses the public key of the JWK.
+func (k jsonWebKey) parse() error {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBase64URLInt(k.N)
+		if err != nil {
+			return err
+		}
+
+		e, err := decodeBase64URLInt(k.E)
+		if err != nil {
+			return err
+		}
+
+		if !e.IsInt64() || e.Int64() < 3 || e.Int64() > 1<<31-1 {
+			return fmt.Errorf("invalid rsa exponent")
+		}
+
+		key := &rsa.PublicKey{N: n, E: int(e.Int64())}
+		if key.Size() == 0 {
+			return fmt.Errorf("invalid rsa modulus")
+		}
+
+		return nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+
+		x, err := decodeBase64URLInt(k.X)
+		if err != nil {
+			return err
+		}
+
+		y, err := decodeBase64URLInt(k.Y)
+		if err != nil {
+			return err
+		}
+
+		key := &ecdsa.PublicKey{Curve: curve, X: x, Y: y}
+		if !key.Curve.IsOnCurve(key.X, key.Y) {
+			return fmt.Errorf("invalid ec point")
+		}
+
+		return nil
+	default:
+		return fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+func decodeBase64URLInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(b) == 0 {
+		return nil, fmt.Errorf("empty value")
+	}
+
+	return new(big.Int).SetBytes(b), nil
+}
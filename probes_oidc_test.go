@@ -0,0 +1,82 @@
+package health
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Starts an OIDC issuer serving the given keys.
+func newOIDCTestServer(keys []jsonWebKey) *httptest.Server {
+	m := http.NewServeMux()
+	s := httptest.NewServer(m)
+
+	m.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(&oidcDiscovery{Issuer: s.URL, JWKSURI: s.URL + "/jwks"})
+	})
+
+	m.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"keys": keys})
+	})
+
+	return s
+}
+
+func newRSAWebKey(t *testing.T) jsonWebKey {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return jsonWebKey{
+		Kty: "RSA",
+		Use: "sig",
+		N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+func TestOIDCProbe(t *testing.T) {
+	s := newOIDCTestServer([]jsonWebKey{newRSAWebKey(t)})
+	defer s.Close()
+
+	probe := OIDCProbe(s.URL, nil)
+
+	assert.NoError(t, probe())
+}
+
+func TestOIDCProbe_err_noValidKey(t *testing.T) {
+	s := newOIDCTestServer([]jsonWebKey{{Kty: "RSA", N: "!", E: "AQAB"}, {Kty: "oct"}})
+	defer s.Close()
+
+	probe := OIDCProbe(s.URL, nil)
+
+	assert.Error(t, probe())
+}
+
+func TestOIDCProbe_err_onlyEncryptionKeys(t *testing.T) {
+	key := newRSAWebKey(t)
+	key.Use = "enc"
+	s := newOIDCTestServer([]jsonWebKey{key})
+	defer s.Close()
+
+	probe := OIDCProbe(s.URL, nil)
+
+	assert.Error(t, probe())
+}
+
+func TestOIDCProbe_err_issuerMismatch(t *testing.T) {
+	s := newOIDCTestServer([]jsonWebKey{newRSAWebKey(t)})
+	defer s.Close()
+
+	probe := OIDCProbe(s.URL+"/realms/other", nil)
+
+	assert.Error(t, probe())
+}
@@ -0,0 +1,128 @@
+package health
+
+import (
+	"fmt"
+	"math/rand"
+	"net"
+	"os"
+	"time"
+
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+)
+
+const (
+	defaultPingTimeout = 2 * time.Second
+
+	protocolICMP     = 1
+	protocolICMPv6   = 58
+	pingReadBuffSize = 1500
+)
+
+type pingProbeConfig struct {
+	timeout time.Duration
+}
+
+// A PingOption configures a PingProbe.
+type PingOption func(c *pingProbeConfig)
+
+// Sets the time to wait for an echo reply. Defaults to 2 seconds.
+func PingTimeout(d time.Duration) PingOption {
+	return func(c *pingProbeConfig) {
+		c.timeout = d
+	}
+}
+
+// Sends an ICMP echo request to host and waits for the reply.
+// Uses a raw socket if the process is privileged and falls back to unprivileged datagram ICMP sockets otherwise.
+// On linux the fallback requires the group of the process to be allowed by `net.ipv4.ping_group_range`.
+//
+// Example:
+//		checker.AddReadinessProbe("core-switch", health.PingProbe("10.0.0.1"))
+func PingProbe(host string, opts ...PingOption) Probe {
+	c := &pingProbeConfig{
+		timeout: defaultPingTimeout,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return func() error {
+		ip, err := net.ResolveIPAddr("ip", host)
+		if err != nil {
+			return classify(ErrUnreachable, fmt.Errorf("could not resolve %v: %w", host, err))
+		}
+
+		return ping(ip, c.timeout)
+	}
+}
+
+func ping(ip *net.IPAddr, timeout time.Duration) error {
+	network, address, proto, echoType, replyType := "ip4:icmp", "0.0.0.0", protocolICMP, icmp.Type(ipv4.ICMPTypeEcho), icmp.Type(ipv4.ICMPTypeEchoReply)
+	if ip.IP.To4() == nil {
+		network, address, proto, echoType, replyType = "ip6:ipv6-icmp", "::", protocolICMPv6, ipv6.ICMPTypeEchoRequest, ipv6.ICMPTypeEchoReply
+	}
+
+	var dst net.Addr = ip
+	conn, err := icmp.ListenPacket(network, address)
+	if err != nil {
+		// Fall back to unprivileged ICMP datagram sockets
+		privilegedErr := err
+		network = "udp4"
+		if proto == protocolICMPv6 {
+			network = "udp6"
+		}
+
+		conn, err = icmp.ListenPacket(network, address)
+		if err != nil {
+			return fmt.Errorf("could not open icmp socket: %v, %w", privilegedErr, err)
+		}
+
+		dst = &net.UDPAddr{IP: ip.IP, Zone: ip.Zone}
+	}
+	defer conn.Close()
+
+	// #nosec G404
+	seq := rand.Intn(0xffff)
+	msg := icmp.Message{
+		Type: echoType,
+		Body: &icmp.Echo{
+			ID:   os.Getpid() & 0xffff,
+			Seq:  seq,
+			Data: []byte("healthchecker"),
+		},
+	}
+
+	b, err := msg.Marshal(nil)
+	if err != nil {
+		return fmt.Errorf("could not create echo request: %w", err)
+	}
+
+	if err := conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return err
+	}
+
+	if _, err := conn.WriteTo(b, dst); err != nil {
+		return fmt.Errorf("could not send echo request: %w", err)
+	}
+
+	buf := make([]byte, pingReadBuffSize)
+	for {
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			return classify(ErrUnreachable, fmt.Errorf("no echo reply from %v: %w", ip, err))
+		}
+
+		reply, err := icmp.ParseMessage(proto, buf[:n])
+		if err != nil || reply.Type != replyType {
+			continue
+		}
+
+		// The ID is rewritten by the kernel for datagram sockets, thus only the sequence is compared.
+		if echo, ok := reply.Body.(*icmp.Echo); ok && echo.Seq == seq {
+			return nil
+		}
+	}
+}
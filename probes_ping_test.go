@@ -0,0 +1,22 @@
+package health
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPingProbe(t *testing.T) {
+	probe := PingProbe("127.0.0.1", PingTimeout(time.Second))
+
+	if err := probe(); err != nil {
+		t.Skipf("icmp not permitted in this environment: %v", err)
+	}
+}
+
+func TestPingProbe_err_unresolvable(t *testing.T) {
+	probe := PingProbe("not-valid-host.invalid")
+
+	assert.Error(t, probe())
+}
@@ -0,0 +1,59 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const defaultPubSubTimeout = 5 * time.Second
+
+// Interface matching a `*pubsub.Topic` or `*pubsub.Subscription` of cloud.google.com/go/pubsub.
+type PubSubResource interface {
+	Exists(ctx context.Context) (bool, error)
+	// Returns the name of the resource, e.g. `projects/my-project/topics/orders`
+	String() string
+}
+
+// Checks a Google Cloud Pub/Sub topic or subscription exists and is accessible with the current credentials, so
+// event-driven services stop taking traffic when they can not publish or receive their messages. Fails if the check
+// does not finish within timeout, which defaults to 5 seconds if 0.
+//
+// Example:
+//		client, _ := pubsub.NewClient(ctx, "my-project")
+//		checker.AddReadinessProbe("orders-topic", health.PubSubProbe(client.Topic("orders"), 2*time.Second))
+//		checker.AddReadinessProbe("orders-subscription", health.PubSubProbe(client.Subscription("billing"), 2*time.Second))
+func PubSubProbe(r PubSubResource, timeout time.Duration) Probe {
+	if timeout == 0 {
+		timeout = defaultPubSubTimeout
+	}
+
+	return func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		exists, err := r.Exists(ctx)
+		if err != nil {
+			if errors.Is(err, context.DeadlineExceeded) || status.Code(err) == codes.DeadlineExceeded {
+				return &timeoutError{timeout: timeout}
+			}
+
+			switch status.Code(err) {
+			case codes.Unauthenticated, codes.PermissionDenied:
+				return classify(ErrUnauthorized, fmt.Errorf("access to %v was denied: %w", r, err))
+			}
+
+			return classify(ErrUnreachable, fmt.Errorf("pub/sub could not be reached: %w", err))
+		}
+
+		if !exists {
+			return classify(ErrUnhealthy, fmt.Errorf("%v does not exist", r))
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,50 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type fakePubSubTopic struct {
+	exists bool
+	err    error
+}
+
+func (f *fakePubSubTopic) Exists(ctx context.Context) (bool, error) {
+	return f.exists, f.err
+}
+
+func (f *fakePubSubTopic) String() string {
+	return "projects/my-project/topics/orders"
+}
+
+func TestPubSubProbe(t *testing.T) {
+	topic := &fakePubSubTopic{exists: true}
+	probe := PubSubProbe(topic, time.Second)
+
+	assert.NoError(t, probe())
+
+	topic.exists = false
+	err := probe()
+	assert.EqualError(t, err, "projects/my-project/topics/orders does not exist")
+	assert.True(t, errors.Is(err, ErrUnhealthy))
+
+	topic.err = status.Error(codes.PermissionDenied, "User not authorized to perform this action.")
+	err = probe()
+	assert.True(t, errors.Is(err, ErrUnauthorized))
+
+	topic.err = status.Error(codes.DeadlineExceeded, "context deadline exceeded")
+	err = probe()
+	assert.EqualError(t, err, "timed out after 1s")
+
+	topic.err = fmt.Errorf("dial tcp: connection refused")
+	err = probe()
+	assert.True(t, errors.Is(err, ErrUnreachable))
+}
@@ -0,0 +1,78 @@
+package health
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+const defaultRedisPoolWarnAt = 0.8
+
+type redisPoolConfig struct {
+	warnAt  float64
+	maxWait time.Duration
+}
+
+// A RedisPoolOption configures a RedisPoolSaturationProbe.
+type RedisPoolOption func(c *redisPoolConfig)
+
+// Warns once the given fraction of MaxActive connections is in use. Defaults to 0.8.
+func RedisPoolWarnAt(fraction float64) RedisPoolOption {
+	return func(c *redisPoolConfig) {
+		c.warnAt = fraction
+	}
+}
+
+// Fails if getting a connection from the pool takes longer than d, e.g. because a pool with `Wait` enabled is
+// exhausted. Disabled by default. Combine it with a Timeout of the probe, as Get blocks until a connection is free.
+func RedisPoolMaxWait(d time.Duration) RedisPoolOption {
+	return func(c *redisPoolConfig) {
+		c.maxWait = d
+	}
+}
+
+// Checks a pool of redis connections for saturation, which is how redis problems usually show up in a service.
+// Fails if all MaxActive connections are in use and warns if the share of connections in use reaches the
+// threshold set by RedisPoolWarnAt. Gets a connection like RedisPoolProbe as well, failing if the pool is
+// exhausted or getting the connection takes too long.
+//
+// Example:
+//		checker.AddReadinessProbe("redis-pool", health.RedisPoolSaturationProbe(pool, health.RedisPoolMaxWait(100*time.Millisecond)), health.Timeout(time.Second))
+func RedisPoolSaturationProbe(pool *redis.Pool, opts ...RedisPoolOption) Probe {
+	cfg := &redisPoolConfig{warnAt: defaultRedisPoolWarnAt}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return func() error {
+		stats := pool.Stats()
+		inUse := stats.ActiveCount - stats.IdleCount
+
+		if pool.MaxActive > 0 && inUse >= pool.MaxActive {
+			return classify(ErrUnhealthy, fmt.Errorf("redis pool is exhausted: %d of %d connections in use", inUse, pool.MaxActive))
+		}
+
+		start := time.Now()
+		conn := pool.Get()
+		defer conn.Close()
+
+		if err := conn.Err(); err != nil {
+			if err == redis.ErrPoolExhausted {
+				return classify(ErrUnhealthy, fmt.Errorf("redis pool is exhausted: %w", err))
+			}
+
+			return classify(ErrUnreachable, fmt.Errorf("redis connection is not useable: %w", err))
+		}
+
+		if waited := time.Since(start); cfg.maxWait > 0 && waited > cfg.maxWait {
+			return classify(ErrUnhealthy, fmt.Errorf("waited %v for a redis connection", waited))
+		}
+
+		if pool.MaxActive > 0 && float64(inUse) >= cfg.warnAt*float64(pool.MaxActive) {
+			return Warning(classify(ErrUnhealthy, fmt.Errorf("redis pool is saturated: %d of %d connections in use", inUse, pool.MaxActive)))
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,66 @@
+package health
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/stretchr/testify/assert"
+)
+
+// Connection doing nothing
+type fakeRedisConn struct{}
+
+func (fakeRedisConn) Close() error { return nil }
+func (fakeRedisConn) Err() error   { return nil }
+func (fakeRedisConn) Do(string, ...interface{}) (interface{}, error) {
+	return nil, nil
+}
+func (fakeRedisConn) Send(string, ...interface{}) error { return nil }
+func (fakeRedisConn) Flush() error                      { return nil }
+func (fakeRedisConn) Receive() (interface{}, error)     { return nil, nil }
+
+func TestRedisPoolSaturationProbe(t *testing.T) {
+	pool := &redis.Pool{
+		MaxActive: 5,
+		Dial: func() (redis.Conn, error) {
+			return fakeRedisConn{}, nil
+		},
+	}
+	probe := RedisPoolSaturationProbe(pool)
+
+	assert.NoError(t, probe())
+
+	var conns []redis.Conn
+	for i := 0; i < 4; i++ {
+		conns = append(conns, pool.Get())
+	}
+
+	err := probe()
+	assert.Equal(t, StatusWarn, probeStatusOf(err, true))
+	assert.EqualError(t, err, "redis pool is saturated: 4 of 5 connections in use")
+
+	conns = append(conns, pool.Get())
+	err = probe()
+	assert.Equal(t, StatusFail, probeStatusOf(err, true))
+	assert.True(t, errors.Is(err, ErrUnhealthy))
+	assert.EqualError(t, err, "redis pool is exhausted: 5 of 5 connections in use")
+
+	for _, conn := range conns {
+		_ = conn.Close()
+	}
+	assert.NoError(t, probe())
+}
+
+func TestRedisPoolSaturationProbe_unreachable(t *testing.T) {
+	pool := &redis.Pool{
+		Dial: func() (redis.Conn, error) {
+			return nil, fmt.Errorf("connection refused")
+		},
+	}
+
+	err := RedisPoolSaturationProbe(pool)()
+
+	assert.True(t, errors.Is(err, ErrUnreachable))
+}
@@ -0,0 +1,173 @@
+package health
+
+import (
+	"fmt"
+	"math"
+	"runtime"
+	"runtime/metrics"
+	"sync"
+	"time"
+)
+
+const (
+	// Quantile of the latency samples compared against the thresholds of RuntimeLatencyProbe
+	runtimeLatencyQuantile = 0.99
+
+	metricGCPauses       = "/sched/pauses/total/gc:seconds"
+	metricGCPausesLegacy = "/gc/pauses:seconds"
+	metricSchedLatencies = "/sched/latencies:seconds"
+)
+
+// Checks the memory usage of the current process. Fails if the resident set size exceeds maxRSS
+// or the allocated Go heap exceeds maxHeap bytes. Pass 0 to disable a limit.
+// Register it as liveness probe to have leaking instances restarted before the OOM killer strikes.
+//
+// Example:
+//		checker.AddLivenessProbe("memory", health.MemoryProbe(900<<20, 0))
+func MemoryProbe(maxRSS, maxHeap uint64) Probe {
+	return func() error {
+		if maxRSS > 0 {
+			rss, err := residentSetSize()
+			if err != nil {
+				return fmt.Errorf("could not determine resident set size: %w", err)
+			}
+
+			if rss > maxRSS {
+				return fmt.Errorf("resident set size of %v bytes exceeds %v", rss, maxRSS)
+			}
+		}
+
+		if maxHeap > 0 {
+			var stats runtime.MemStats
+			runtime.ReadMemStats(&stats)
+
+			if stats.HeapAlloc > maxHeap {
+				return fmt.Errorf("heap size of %v bytes exceeds %v", stats.HeapAlloc, maxHeap)
+			}
+		}
+
+		return nil
+	}
+}
+
+// Fails if the number of goroutines exceeds max. Goroutine leaks are a common slow-burn failure,
+// thus registering this probe as liveness probe leads to an automatic restart of leaking instances.
+//
+// Example:
+//		checker.AddLivenessProbe("goroutines", health.GoroutineProbe(10000))
+func GoroutineProbe(max int) Probe {
+	return func() error {
+		if n := runtime.NumGoroutine(); n > max {
+			return fmt.Errorf("%v goroutines exceed the limit of %v", n, max)
+		}
+
+		return nil
+	}
+}
+
+// Fails if the 99th percentile of GC pauses or of the time goroutines spent waiting to be scheduled
+// exceeds the given limits. Only samples recorded since the previous check are taken into account.
+// Pass 0 to disable a limit. Use it to detect instances which are alive but unable to serve within their SLO.
+//
+// Example:
+//		checker.AddReadinessProbe("runtime-latency", health.RuntimeLatencyProbe(50*time.Millisecond, 100*time.Millisecond))
+func RuntimeLatencyProbe(maxGCPause, maxSchedLatency time.Duration) Probe {
+	gcPauses := &latencyHistogram{name: supportedMetric(metricGCPauses, metricGCPausesLegacy)}
+	schedLatencies := &latencyHistogram{name: supportedMetric(metricSchedLatencies)}
+
+	return func() error {
+		if maxGCPause > 0 {
+			if err := gcPauses.check("gc pause", maxGCPause); err != nil {
+				return err
+			}
+		}
+
+		if maxSchedLatency > 0 {
+			if err := schedLatencies.check("scheduling latency", maxSchedLatency); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+}
+
+// Returns the first of the given metric names supported by the runtime or an empty string.
+func supportedMetric(names ...string) string {
+	supported := map[string]bool{}
+	for _, d := range metrics.All() {
+		supported[d.Name] = true
+	}
+
+	for _, name := range names {
+		if supported[name] {
+			return name
+		}
+	}
+
+	return ""
+}
+
+// Tracks a runtime latency histogram to evaluate the samples recorded between two reads.
+type latencyHistogram struct {
+	name string
+
+	mu     sync.Mutex
+	counts []uint64
+}
+
+func (l *latencyHistogram) check(kind string, max time.Duration) error {
+	if l.name == "" {
+		return fmt.Errorf("%v metrics are not supported by %v", kind, runtime.Version())
+	}
+
+	q, ok := l.recentQuantile(runtimeLatencyQuantile)
+	if ok && q > max {
+		return fmt.Errorf("p%v %v of %v exceeds %v", runtimeLatencyQuantile*100, kind, q, max)
+	}
+
+	return nil
+}
+
+// Returns the given quantile of all samples recorded since the previous call.
+// Returns false if there are no new samples.
+func (l *latencyHistogram) recentQuantile(q float64) (time.Duration, bool) {
+	sample := []metrics.Sample{{Name: l.name}}
+	metrics.Read(sample)
+	h := sample[0].Value.Float64Histogram()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delta := make([]uint64, len(h.Counts))
+	var total uint64
+	for i, c := range h.Counts {
+		delta[i] = c
+		if i < len(l.counts) {
+			delta[i] -= l.counts[i]
+		}
+
+		total += delta[i]
+	}
+
+	l.counts = append(l.counts[:0], h.Counts...)
+
+	if total == 0 {
+		return 0, false
+	}
+
+	var cumulative uint64
+	for i, c := range delta {
+		cumulative += c
+		if float64(cumulative) >= q*float64(total) {
+			upper := h.Buckets[i+1]
+			if math.IsInf(upper, 1) {
+				upper = h.Buckets[i]
+			}
+
+			return time.Duration(upper * float64(time.Second)), true
+		}
+	}
+
+	return 0, false
+}
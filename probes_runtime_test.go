@@ -0,0 +1,63 @@
+package health
+
+import (
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryProbe(t *testing.T) {
+	probe := MemoryProbe(0, 1<<40)
+
+	assert.NoError(t, probe())
+}
+
+func TestMemoryProbe_rss(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("resident set size is only supported on linux")
+	}
+
+	assert.NoError(t, MemoryProbe(1<<40, 0)())
+	assert.Error(t, MemoryProbe(1, 0)())
+}
+
+func TestMemoryProbe_err_heap(t *testing.T) {
+	probe := MemoryProbe(0, 1)
+
+	assert.Error(t, probe())
+}
+
+func TestGoroutineProbe(t *testing.T) {
+	probe := GoroutineProbe(100000)
+
+	assert.NoError(t, probe())
+}
+
+func TestGoroutineProbe_err(t *testing.T) {
+	probe := GoroutineProbe(0)
+
+	assert.Error(t, probe())
+}
+
+func TestRuntimeLatencyProbe(t *testing.T) {
+	probe := RuntimeLatencyProbe(time.Hour, time.Hour)
+
+	assert.NoError(t, probe())
+}
+
+func TestRuntimeLatencyProbe_err_gcPause(t *testing.T) {
+	probe := RuntimeLatencyProbe(time.Nanosecond, 0)
+	runtime.GC()
+
+	assert.Error(t, probe())
+}
+
+func TestRuntimeLatencyProbe_onlyRecentSamples(t *testing.T) {
+	probe := RuntimeLatencyProbe(time.Nanosecond, 0)
+	runtime.GC()
+	_ = probe()
+
+	assert.NoError(t, probe())
+}
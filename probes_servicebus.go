@@ -0,0 +1,80 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"time"
+)
+
+const defaultServiceBusTimeout = 5 * time.Second
+
+// A ServiceBusLookup looks up a queue, topic or subscription of an Azure Service Bus namespace and returns whether
+// it exists, e.g. by `GetQueue` of the administration client of azservicebus, which returns no response for missing
+// entities. Lookups fail if the namespace can not be reached.
+type ServiceBusLookup func(ctx context.Context) (exists bool, err error)
+
+// Checks an Azure Service Bus namespace is reachable and the entity looked up by lookup exists, so services stop
+// taking traffic when they can not send or receive their messages. Fails if the credentials are rejected or the
+// lookup does not finish within timeout, which defaults to 5 seconds if 0.
+//
+// Example:
+//		client, _ := admin.NewClientFromConnectionString(connectionString, nil)
+//		lookup := func(ctx context.Context) (bool, error) {
+//			resp, err := client.GetQueue(ctx, "orders", nil)
+//			return resp != nil, err
+//		}
+//		checker.AddReadinessProbe("service-bus", health.ServiceBusProbe("orders", lookup, 2*time.Second))
+func ServiceBusProbe(entity string, lookup ServiceBusLookup, timeout time.Duration) Probe {
+	if timeout == 0 {
+		timeout = defaultServiceBusTimeout
+	}
+
+	return func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		exists, err := lookup(ctx)
+		if err != nil {
+			if errors.Is(err, context.DeadlineExceeded) {
+				return &timeoutError{timeout: timeout}
+			}
+
+			switch responseStatusCode(err) {
+			case http.StatusUnauthorized, http.StatusForbidden:
+				return classify(ErrUnauthorized, fmt.Errorf("access to service bus entity %v was denied: %w", entity, err))
+			}
+
+			return classify(ErrUnreachable, fmt.Errorf("service bus namespace could not be reached: %w", err))
+		}
+
+		if !exists {
+			return classify(ErrUnhealthy, fmt.Errorf("service bus entity %v does not exist", entity))
+		}
+
+		return nil
+	}
+}
+
+// Returns the HTTP status code of the first error in the chain of err with an int field StatusCode, e.g. the
+// `*azcore.ResponseError` of the Azure SDK, or 0 if there is none.
+func responseStatusCode(err error) int {
+	for ; err != nil; err = errors.Unwrap(err) {
+		v := reflect.ValueOf(err)
+		for v.Kind() == reflect.Ptr {
+			v = v.Elem()
+		}
+
+		if v.Kind() != reflect.Struct {
+			continue
+		}
+
+		if f := v.FieldByName("StatusCode"); f.IsValid() && f.Kind() == reflect.Int {
+			return int(f.Int())
+		}
+	}
+
+	return 0
+}
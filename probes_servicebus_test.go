@@ -0,0 +1,57 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Mirrors the fields of `*azcore.ResponseError` of the Azure SDK.
+type fakeResponseError struct {
+	ErrorCode  string
+	StatusCode int
+}
+
+func (e *fakeResponseError) Error() string {
+	return fmt.Sprintf("RESPONSE %v: %v", e.StatusCode, e.ErrorCode)
+}
+
+func TestServiceBusProbe(t *testing.T) {
+	exists := true
+	var lookupErr error
+	lookup := func(ctx context.Context) (bool, error) {
+		return exists, lookupErr
+	}
+	probe := ServiceBusProbe("orders", lookup, time.Second)
+
+	assert.NoError(t, probe())
+
+	exists = false
+	err := probe()
+	assert.EqualError(t, err, "service bus entity orders does not exist")
+	assert.True(t, errors.Is(err, ErrUnhealthy))
+
+	lookupErr = fmt.Errorf("GET failed: %w", &fakeResponseError{ErrorCode: "Unauthorized", StatusCode: 401})
+	err = probe()
+	assert.EqualError(t, err, "access to service bus entity orders was denied: GET failed: RESPONSE 401: Unauthorized")
+	assert.True(t, errors.Is(err, ErrUnauthorized))
+
+	lookupErr = fmt.Errorf("dial tcp: lookup my-namespace.servicebus.windows.net: no such host")
+	err = probe()
+	assert.True(t, errors.Is(err, ErrUnreachable))
+}
+
+func TestServiceBusProbe_timeout(t *testing.T) {
+	lookup := func(ctx context.Context) (bool, error) {
+		<-ctx.Done()
+		return false, ctx.Err()
+	}
+
+	err := ServiceBusProbe("orders", lookup, 10*time.Millisecond)()
+	assert.EqualError(t, err, "timed out after 10ms")
+	assert.True(t, errors.Is(err, ErrTimeout))
+}
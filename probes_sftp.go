@@ -0,0 +1,250 @@
+package health
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+const defaultSFTPTimeout = 10 * time.Second
+
+// Packet types and status codes of the SFTP protocol version 3 used by the probe
+const (
+	sshFxpInit    = 1
+	sshFxpVersion = 2
+	sshFxpClose   = 4
+	sshFxpOpendir = 11
+	sshFxpReaddir = 12
+	sshFxpStatus  = 101
+	sshFxpHandle  = 102
+	sshFxpName    = 104
+
+	sshFxEOF              = 1
+	sshFxPermissionDenied = 3
+)
+
+// Checks an SFTP server for readiness by opening a session and listing dir, for services exchanging files with
+// partners. Fails if the session is not completed within timeout, which defaults to 10 seconds if 0.
+//
+// Example:
+//		config := &ssh.ClientConfig{
+//			User:            "partner",
+//			Auth:            []ssh.AuthMethod{ssh.Password("secret")},
+//			HostKeyCallback: ssh.FixedHostKey(hostKey),
+//		}
+//		checker.AddReadinessProbe("partner-sftp", health.SFTPProbe("sftp.partner.example:22", config, "/inbox", 0))
+func SFTPProbe(addr string, config *ssh.ClientConfig, dir string, timeout time.Duration) Probe {
+	if timeout == 0 {
+		timeout = defaultSFTPTimeout
+	}
+
+	if dir == "" {
+		dir = "."
+	}
+
+	return func() error {
+		conn, err := net.DialTimeout("tcp", addr, timeout)
+		if err != nil {
+			return classify(ErrUnreachable, fmt.Errorf("sftp server could not be reached: %w", err))
+		}
+		defer conn.Close()
+		_ = conn.SetDeadline(time.Now().Add(timeout))
+
+		sshConn, chans, reqs, err := ssh.NewClientConn(conn, addr, config)
+		if err != nil {
+			err = fmt.Errorf("ssh handshake failed: %w", err)
+			if isSSHAuthError(err) {
+				return classify(ErrUnauthorized, err)
+			}
+
+			return classify(ErrUnreachable, err)
+		}
+		client := ssh.NewClient(sshConn, chans, reqs)
+		defer client.Close()
+
+		session, err := client.NewSession()
+		if err != nil {
+			return classify(ErrUnhealthy, fmt.Errorf("could not open ssh session: %w", err))
+		}
+		defer session.Close()
+
+		w, err := session.StdinPipe()
+		if err != nil {
+			return err
+		}
+		r, err := session.StdoutPipe()
+		if err != nil {
+			return err
+		}
+
+		if err := session.RequestSubsystem("sftp"); err != nil {
+			return classify(ErrUnhealthy, fmt.Errorf("sftp subsystem is not available: %w", err))
+		}
+
+		s := &sftpSession{w: w, r: r}
+		return s.list(dir)
+	}
+}
+
+// Returns true if the SSH handshake failed as the server rejected the credentials. The ssh package does not export
+// an error type for it.
+func isSSHAuthError(err error) bool {
+	return strings.Contains(err.Error(), "unable to authenticate")
+}
+
+type sftpSession struct {
+	w  io.Writer
+	r  io.Reader
+	id uint32
+}
+
+// Opens dir and reads the first batch of entries.
+func (s *sftpSession) list(dir string) error {
+	if err := s.send(sshFxpInit, uint32(3)); err != nil {
+		return err
+	}
+	if typ, _, err := s.recv(); err != nil {
+		return err
+	} else if typ != sshFxpVersion {
+		return classify(ErrUnhealthy, fmt.Errorf("unexpected sftp packet %v", typ))
+	}
+
+	handle, err := s.request(sshFxpHandle, sshFxpOpendir, dir)
+	if err != nil {
+		return fmt.Errorf("could not open %v: %w", dir, err)
+	}
+
+	// An empty directory reports EOF on the first read
+	if _, err := s.request(sshFxpName, sshFxpReaddir, handle); err != nil && !isSFTPEOF(err) {
+		return fmt.Errorf("could not list %v: %w", dir, err)
+	}
+
+	_, _ = s.request(sshFxpStatus, sshFxpClose, handle)
+
+	return nil
+}
+
+// Sends a request and returns the payload of the response following the request ID.
+func (s *sftpSession) request(expect, typ byte, arg string) (string, error) {
+	s.id++
+	if err := s.send(typ, s.id, arg); err != nil {
+		return "", err
+	}
+
+	got, payload, err := s.recv()
+	if err != nil {
+		return "", err
+	}
+
+	if len(payload) < 4 || binary.BigEndian.Uint32(payload) != s.id {
+		return "", classify(ErrUnhealthy, fmt.Errorf("invalid sftp response"))
+	}
+	payload = payload[4:]
+
+	if got == sshFxpStatus && expect != sshFxpStatus {
+		return "", sftpStatusError(payload)
+	}
+
+	if got != expect {
+		return "", classify(ErrUnhealthy, fmt.Errorf("unexpected sftp packet %v", got))
+	}
+
+	if expect == sshFxpHandle {
+		return readSFTPString(payload)
+	}
+
+	return "", nil
+}
+
+// Writes a packet of the given type with fields of type uint32 or string.
+func (s *sftpSession) send(typ byte, fields ...interface{}) error {
+	b := []byte{typ}
+	for _, f := range fields {
+		switch v := f.(type) {
+		case uint32:
+			b = appendUint32(b, v)
+		case string:
+			b = appendUint32(b, uint32(len(v)))
+			b = append(b, v...)
+		}
+	}
+
+	if _, err := s.w.Write(append(appendUint32(nil, uint32(len(b))), b...)); err != nil {
+		return classify(ErrUnreachable, fmt.Errorf("sftp session failed: %w", err))
+	}
+
+	return nil
+}
+
+// Reads a packet and returns its type and payload.
+func (s *sftpSession) recv() (byte, []byte, error) {
+	header := make([]byte, 5)
+	if _, err := io.ReadFull(s.r, header); err != nil {
+		return 0, nil, classify(ErrUnreachable, fmt.Errorf("sftp session failed: %w", err))
+	}
+
+	length := binary.BigEndian.Uint32(header)
+	if length < 1 || length > 1<<20 {
+		return 0, nil, classify(ErrUnhealthy, fmt.Errorf("invalid sftp packet length %v", length))
+	}
+
+	payload := make([]byte, length-1)
+	if _, err := io.ReadFull(s.r, payload); err != nil {
+		return 0, nil, classify(ErrUnreachable, fmt.Errorf("sftp session failed: %w", err))
+	}
+
+	return header[4], payload, nil
+}
+
+type sftpError struct {
+	code uint32
+	msg  string
+}
+
+func (e *sftpError) Error() string {
+	return fmt.Sprintf("sftp status %v: %v", e.code, e.msg)
+}
+
+func sftpStatusError(payload []byte) error {
+	if len(payload) < 4 {
+		return classify(ErrUnhealthy, fmt.Errorf("invalid sftp status"))
+	}
+
+	code := binary.BigEndian.Uint32(payload)
+	msg, _ := readSFTPString(payload[4:])
+	err := &sftpError{code: code, msg: msg}
+
+	if code == sshFxPermissionDenied {
+		return classify(ErrUnauthorized, err)
+	}
+
+	return classify(ErrUnhealthy, err)
+}
+
+func isSFTPEOF(err error) bool {
+	var e *sftpError
+	return errors.As(err, &e) && e.code == sshFxEOF
+}
+
+func appendUint32(b []byte, v uint32) []byte {
+	return append(b, byte(v>>24), byte(v>>16), byte(v>>8), byte(v))
+}
+
+func readSFTPString(b []byte) (string, error) {
+	if len(b) < 4 {
+		return "", classify(ErrUnhealthy, fmt.Errorf("invalid sftp string"))
+	}
+
+	n := binary.BigEndian.Uint32(b)
+	if uint32(len(b)-4) < n {
+		return "", classify(ErrUnhealthy, fmt.Errorf("invalid sftp string"))
+	}
+
+	return string(b[4 : 4+n]), nil
+}
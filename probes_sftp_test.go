@@ -0,0 +1,126 @@
+package health
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/ssh"
+)
+
+// Serves the sftp subsystem with the empty directory /inbox, accepting the password "secret".
+func sftpServer(t *testing.T) string {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	signer, err := ssh.NewSignerFromKey(key)
+	assert.NoError(t, err)
+
+	config := &ssh.ServerConfig{
+		PasswordCallback: func(conn ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+			if string(password) != "secret" {
+				return nil, fmt.Errorf("wrong password")
+			}
+			return nil, nil
+		},
+	}
+	config.AddHostKey(signer)
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	t.Cleanup(func() { _ = lis.Close() })
+
+	go func() {
+		for {
+			conn, err := lis.Accept()
+			if err != nil {
+				return
+			}
+
+			go serveSSH(conn, config)
+		}
+	}()
+
+	return lis.Addr().String()
+}
+
+func serveSSH(conn net.Conn, config *ssh.ServerConfig) {
+	defer conn.Close()
+
+	_, chans, reqs, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+
+	for newChannel := range chans {
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			return
+		}
+
+		go func() {
+			for req := range requests {
+				_ = req.Reply(req.Type == "subsystem", nil)
+			}
+		}()
+
+		go serveSFTP(channel)
+	}
+}
+
+func serveSFTP(rw io.ReadWriteCloser) {
+	defer rw.Close()
+
+	s := &sftpSession{w: rw, r: rw}
+	for {
+		typ, payload, err := s.recv()
+		if err != nil {
+			return
+		}
+
+		if typ == sshFxpInit {
+			_ = s.send(sshFxpVersion, uint32(3))
+			continue
+		}
+
+		id := binary.BigEndian.Uint32(payload)
+		arg, _ := readSFTPString(payload[4:])
+
+		switch {
+		case typ == sshFxpOpendir && arg == "/inbox":
+			_ = s.send(sshFxpHandle, id, "h1")
+		case typ == sshFxpOpendir:
+			_ = s.send(sshFxpStatus, id, uint32(2), "no such file", "")
+		case typ == sshFxpReaddir:
+			_ = s.send(sshFxpStatus, id, uint32(sshFxEOF), "eof", "")
+		default:
+			_ = s.send(sshFxpStatus, id, uint32(0), "ok", "")
+		}
+	}
+}
+
+func TestSFTPProbe(t *testing.T) {
+	addr := sftpServer(t)
+	config := &ssh.ClientConfig{
+		User:            "partner",
+		Auth:            []ssh.AuthMethod{ssh.Password("secret")},
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+	}
+
+	assert.NoError(t, SFTPProbe(addr, config, "/inbox", time.Second)())
+
+	err := SFTPProbe(addr, config, "/outbox", time.Second)()
+	assert.EqualError(t, err, "could not open /outbox: sftp status 2: no such file")
+	assert.True(t, errors.Is(err, ErrUnhealthy))
+
+	wrong := *config
+	wrong.Auth = []ssh.AuthMethod{ssh.Password("wrong")}
+	assert.True(t, errors.Is(SFTPProbe(addr, &wrong, "/inbox", time.Second)(), ErrUnauthorized))
+}
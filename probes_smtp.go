@@ -0,0 +1,107 @@
+package health
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/smtp"
+	"time"
+)
+
+const defaultSMTPTimeout = 5 * time.Second
+
+type smtpProbeConfig struct {
+	tlsConfig *tls.Config
+	auth      smtp.Auth
+	timeout   time.Duration
+}
+
+// A SMTPOption configures a SMTPProbe.
+type SMTPOption func(c *smtpProbeConfig)
+
+// Upgrades the connection using STARTTLS before issuing any further command.
+// If no server name is set, the host of the probed address is used.
+func SMTPStartTLS(cfg *tls.Config) SMTPOption {
+	return func(c *smtpProbeConfig) {
+		if cfg == nil {
+			cfg = &tls.Config{}
+		}
+
+		c.tlsConfig = cfg
+	}
+}
+
+// Verifies the given credentials are accepted by the server.
+func SMTPAuth(auth smtp.Auth) SMTPOption {
+	return func(c *smtpProbeConfig) {
+		c.auth = auth
+	}
+}
+
+// Sets the deadline for the whole SMTP conversation. Defaults to 5 seconds.
+func SMTPTimeout(d time.Duration) SMTPOption {
+	return func(c *smtpProbeConfig) {
+		c.timeout = d
+	}
+}
+
+// Checks a SMTP server for readiness. Connects to addr (host:port), reads the banner and issues NOOP and QUIT.
+//
+// Example:
+//		auth := smtp.PlainAuth("", "user", "password", "mail.example.com")
+//		checker.AddReadinessProbe("mail-relay", health.SMTPProbe("mail.example.com:587", health.SMTPStartTLS(nil), health.SMTPAuth(auth)))
+func SMTPProbe(addr string, opts ...SMTPOption) Probe {
+	c := &smtpProbeConfig{
+		timeout: defaultSMTPTimeout,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return func() error {
+		host, _, err := net.SplitHostPort(addr)
+		if err != nil {
+			return fmt.Errorf("invalid address %v: %w", addr, err)
+		}
+
+		conn, err := net.DialTimeout("tcp", addr, c.timeout)
+		if err != nil {
+			return classify(ErrUnreachable, fmt.Errorf("smtp server could not be reached: %w", err))
+		}
+		defer conn.Close()
+
+		if err := conn.SetDeadline(time.Now().Add(c.timeout)); err != nil {
+			return err
+		}
+
+		client, err := smtp.NewClient(conn, host)
+		if err != nil {
+			return classify(ErrUnhealthy, fmt.Errorf("smtp server rejected connection: %w", err))
+		}
+		defer client.Close()
+
+		if c.tlsConfig != nil {
+			cfg := c.tlsConfig.Clone()
+			if cfg.ServerName == "" {
+				cfg.ServerName = host
+			}
+
+			if err := client.StartTLS(cfg); err != nil {
+				return fmt.Errorf("starttls failed: %w", err)
+			}
+		}
+
+		if c.auth != nil {
+			if err := client.Auth(c.auth); err != nil {
+				return classify(ErrUnauthorized, fmt.Errorf("smtp authentication failed: %w", err))
+			}
+		}
+
+		if err := client.Noop(); err != nil {
+			return classify(ErrUnhealthy, fmt.Errorf("smtp server is not ready: %w", err))
+		}
+
+		return client.Quit()
+	}
+}
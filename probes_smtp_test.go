@@ -0,0 +1,84 @@
+package health
+
+import (
+	"net"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Starts a minimal SMTP server greeting with the given banner and accepting any command.
+func newSMTPTestServer(t *testing.T, banner string) net.Listener {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+
+			go func() {
+				defer conn.Close()
+
+				tp := textproto.NewConn(conn)
+				_ = tp.PrintfLine(banner)
+
+				for {
+					line, err := tp.ReadLine()
+					if err != nil {
+						return
+					}
+
+					if strings.HasPrefix(line, "QUIT") {
+						_ = tp.PrintfLine("221 bye")
+						return
+					}
+
+					_ = tp.PrintfLine("250 OK")
+				}
+			}()
+		}
+	}()
+
+	return l
+}
+
+func TestSMTPProbe(t *testing.T) {
+	l := newSMTPTestServer(t, "220 localhost ESMTP")
+	defer l.Close()
+
+	probe := SMTPProbe(l.Addr().String())
+
+	assert.NoError(t, probe())
+}
+
+func TestSMTPProbe_err_rejected(t *testing.T) {
+	l := newSMTPTestServer(t, "554 no service")
+	defer l.Close()
+
+	probe := SMTPProbe(l.Addr().String())
+
+	assert.Error(t, probe())
+}
+
+func TestSMTPProbe_err_startTLSNotSupported(t *testing.T) {
+	l := newSMTPTestServer(t, "220 localhost ESMTP")
+	defer l.Close()
+
+	probe := SMTPProbe(l.Addr().String(), SMTPStartTLS(nil))
+
+	assert.Error(t, probe())
+}
+
+func TestSMTPProbe_err_unreachable(t *testing.T) {
+	probe := SMTPProbe("127.0.0.1:1", SMTPTimeout(time.Second))
+
+	assert.Error(t, probe())
+}
@@ -0,0 +1,290 @@
+package health
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+const defaultSQLPoolWarnAt = 0.8
+
+// Drivers with a validation query and read-only detection of their own, see SQLDriver
+const (
+	SQLDriverPostgres  = "postgres"
+	SQLDriverMySQL     = "mysql"
+	SQLDriverSQLServer = "sqlserver"
+	SQLDriverOracle    = "oracle"
+)
+
+// Validation queries and queries returning whether the database is read-only, by driver
+var (
+	sqlValidationQueries = map[string]string{
+		SQLDriverOracle:    "SELECT 1 FROM dual",
+		SQLDriverSQLServer: "SELECT @@version",
+	}
+	sqlReadOnlyQueries = map[string]string{
+		SQLDriverPostgres:  "SELECT pg_is_in_recovery() OR current_setting('transaction_read_only') = 'on'",
+		SQLDriverMySQL:     "SELECT @@global.read_only OR @@global.super_read_only",
+		SQLDriverSQLServer: "SELECT CASE WHEN DATABASEPROPERTYEX(DB_NAME(), 'Updateability') = 'READ_ONLY' THEN 1 ELSE 0 END",
+		SQLDriverOracle:    "SELECT CASE WHEN open_mode = 'READ WRITE' THEN 0 ELSE 1 END FROM v$database",
+	}
+	// Substrings of the package paths of drivers, used to detect the driver of a *sql.DB
+	sqlDriverPackages = []struct{ substr, driver string }{
+		{"godror", SQLDriverOracle},
+		{"go-ora", SQLDriverOracle},
+		{"oci8", SQLDriverOracle},
+		{"mssql", SQLDriverSQLServer},
+		{"mysql", SQLDriverMySQL},
+		{"lib/pq", SQLDriverPostgres},
+		{"jackc/pgx", SQLDriverPostgres},
+	}
+)
+
+type sqlProbeConfig struct {
+	driver   string
+	query    string
+	timeout  time.Duration
+	writable bool
+}
+
+// A SQLOption configures a SQLProbe.
+type SQLOption func(c *sqlProbeConfig)
+
+// Sets the driver used to choose the validation query, one of SQLDriverPostgres, SQLDriverMySQL, SQLDriverSQLServer
+// or SQLDriverOracle. Defaults to the driver detected from the package of the driver of the database.
+func SQLDriver(driver string) SQLOption {
+	return func(c *sqlProbeConfig) {
+		c.driver = driver
+	}
+}
+
+// Sets the query validating the connection. Defaults to `SELECT 1`, `SELECT 1 FROM dual` for Oracle and
+// `SELECT @@version` for SQL Server.
+func SQLValidationQuery(query string) SQLOption {
+	return func(c *sqlProbeConfig) {
+		c.query = query
+	}
+}
+
+// Sets the timeout of the validation. Disabled by default.
+func SQLTimeout(d time.Duration) SQLOption {
+	return func(c *sqlProbeConfig) {
+		c.timeout = d
+	}
+}
+
+// Fails if the database is read-only, e.g. a replica after a failover, for services writing to the database.
+// Requires a driver with read-only detection, see SQLDriver.
+func SQLExpectWritable() SQLOption {
+	return func(c *sqlProbeConfig) {
+		c.writable = true
+	}
+}
+
+// Checks a SQL connection for readiness by running a lightweight validation query suited to the driver, which
+// catches broken sessions a Ping of some drivers does not.
+//
+// Example:
+//		checker.AddReadinessProbe("database", health.SQLProbe(db, health.SQLTimeout(time.Second), health.SQLExpectWritable()))
+func SQLProbe(db *sql.DB, opts ...SQLOption) Probe {
+	cfg := &sqlProbeConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	if cfg.driver == "" {
+		cfg.driver = detectSQLDriver(db.Driver())
+	}
+
+	if cfg.query == "" {
+		cfg.query = "SELECT 1"
+		if query, ok := sqlValidationQueries[cfg.driver]; ok {
+			cfg.query = query
+		}
+	}
+
+	return func() error {
+		ctx := context.Background()
+		if cfg.timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, cfg.timeout)
+			defer cancel()
+		}
+
+		var result interface{}
+		if err := db.QueryRowContext(ctx, cfg.query).Scan(&result); err != nil {
+			return classify(ErrUnreachable, fmt.Errorf("validation query failed: %w", err))
+		}
+
+		if !cfg.writable {
+			return nil
+		}
+
+		query, ok := sqlReadOnlyQueries[cfg.driver]
+		if !ok {
+			return fmt.Errorf("read-only detection is not supported for driver %q", cfg.driver)
+		}
+
+		var readOnly bool
+		if err := db.QueryRowContext(ctx, query).Scan(&readOnly); err != nil {
+			return fmt.Errorf("could not detect read-only mode: %w", err)
+		}
+
+		if readOnly {
+			return classify(ErrUnhealthy, fmt.Errorf("database is read-only"))
+		}
+
+		return nil
+	}
+}
+
+// Returns the driver matching the package of d or an empty string.
+func detectSQLDriver(d driver.Driver) string {
+	t := reflect.TypeOf(d)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	pkg := strings.ToLower(t.PkgPath())
+	for _, p := range sqlDriverPackages {
+		if strings.Contains(pkg, p.substr) {
+			return p.driver
+		}
+	}
+
+	return ""
+}
+
+// Interface matching the stats method of a *sql.DB.
+type SQLStatsReporter interface {
+	Stats() sql.DBStats
+}
+
+type sqlPoolConfig struct {
+	warnAt  float64
+	maxWait time.Duration
+}
+
+// A SQLPoolOption configures a SQLPoolSaturationProbe.
+type SQLPoolOption func(c *sqlPoolConfig)
+
+// Warns once the given fraction of MaxOpenConnections is in use. Defaults to 0.8.
+func SQLPoolWarnAt(fraction float64) SQLPoolOption {
+	return func(c *sqlPoolConfig) {
+		c.warnAt = fraction
+	}
+}
+
+// Fails if queries waited longer than d for a connection on average since the previous run of the probe.
+// Disabled by default.
+func SQLPoolMaxWait(d time.Duration) SQLPoolOption {
+	return func(c *sqlPoolConfig) {
+		c.maxWait = d
+	}
+}
+
+// Checks the connection pool of a database for saturation, catching a pool starved by slow queries or leaked
+// connections while the database itself still answers pings. Fails if all MaxOpenConnections are in use and queries
+// had to wait for a connection since the previous run of the probe, or if they waited longer than SQLPoolMaxWait on
+// average. Warns if all connections are in use without waits or the share of connections in use reaches the
+// threshold set by SQLPoolWarnAt.
+//
+// Example:
+//		checker.AddReadinessProbe("db-pool", health.SQLPoolSaturationProbe(db, health.SQLPoolMaxWait(50*time.Millisecond)))
+func SQLPoolSaturationProbe(db SQLStatsReporter, opts ...SQLPoolOption) Probe {
+	cfg := &sqlPoolConfig{warnAt: defaultSQLPoolWarnAt}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	mu := sync.Mutex{}
+	previous := db.Stats()
+
+	return func() error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		stats := db.Stats()
+		waits := stats.WaitCount - previous.WaitCount
+		waited := stats.WaitDuration - previous.WaitDuration
+		previous = stats
+
+		if cfg.maxWait > 0 && waits > 0 {
+			if avg := waited / time.Duration(waits); avg > cfg.maxWait {
+				return classify(ErrUnhealthy, fmt.Errorf("%d queries waited %v on average for a connection", waits, avg))
+			}
+		}
+
+		max := stats.MaxOpenConnections
+		if max <= 0 {
+			return nil
+		}
+
+		if stats.InUse >= max {
+			err := classify(ErrUnhealthy, fmt.Errorf("connection pool is exhausted: %d of %d connections in use, %d queries waited", stats.InUse, max, waits))
+			if waits > 0 {
+				return err
+			}
+
+			return Warning(err)
+		}
+
+		if float64(stats.InUse) >= cfg.warnAt*float64(max) {
+			return Warning(classify(ErrUnhealthy, fmt.Errorf("connection pool is saturated: %d of %d connections in use", stats.InUse, max)))
+		}
+
+		return nil
+	}
+}
+
+// A SQLTokenSource returns a fresh authentication token, e.g. built by `rdsutils.BuildAuthToken` of the AWS SDK for
+// RDS IAM authentication.
+type SQLTokenSource func(ctx context.Context) (string, error)
+
+// Checks a database authenticating by short-lived tokens like RDS IAM authentication, whose tokens expire after 15
+// minutes. Each run requests a token from tokens and validates a new connection opened with the data source name
+// returned by dsn for it, so the probe does not fail only because the token baked into a long-lived pool expired.
+// Accepts the options of SQLProbe, SQLTimeout limits fetching the token as well.
+//
+// Example:
+//		dsn := func(token string) string {
+//			return fmt.Sprintf("app:%v@tcp(%v)/orders?tls=true&allowCleartextPasswords=true", token, endpoint)
+//		}
+//		tokens := func(ctx context.Context) (string, error) {
+//			return rdsutils.BuildAuthToken(endpoint, region, "app", sess.Config.Credentials)
+//		}
+//		checker.AddReadinessProbe("database", health.SQLTokenAuthProbe("mysql", dsn, tokens, health.SQLTimeout(time.Second)))
+func SQLTokenAuthProbe(driverName string, dsn func(token string) string, tokens SQLTokenSource, opts ...SQLOption) Probe {
+	cfg := &sqlProbeConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return func() error {
+		ctx := context.Background()
+		if cfg.timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, cfg.timeout)
+			defer cancel()
+		}
+
+		token, err := tokens(ctx)
+		if err != nil {
+			return classify(ErrUnauthorized, fmt.Errorf("could not get authentication token: %w", err))
+		}
+
+		db, err := sql.Open(driverName, dsn(token))
+		if err != nil {
+			return fmt.Errorf("could not open database: %w", err)
+		}
+		defer db.Close()
+		db.SetMaxOpenConns(1)
+
+		return SQLProbe(db, opts...)()
+	}
+}
@@ -0,0 +1,203 @@
+package health
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeSQLStats struct {
+	stats sql.DBStats
+}
+
+func (f *fakeSQLStats) Stats() sql.DBStats {
+	return f.stats
+}
+
+func TestSQLPoolSaturationProbe(t *testing.T) {
+	db := &fakeSQLStats{stats: sql.DBStats{MaxOpenConnections: 10, InUse: 2}}
+	probe := SQLPoolSaturationProbe(db, SQLPoolMaxWait(10*time.Millisecond))
+
+	assert.NoError(t, probe())
+
+	db.stats.InUse = 8
+	err := probe()
+	assert.Equal(t, StatusWarn, probeStatusOf(err, true))
+	assert.EqualError(t, err, "connection pool is saturated: 8 of 10 connections in use")
+
+	db.stats.InUse = 10
+	err = probe()
+	assert.Equal(t, StatusWarn, probeStatusOf(err, true), "busy without waits")
+
+	db.stats.WaitCount = 4
+	db.stats.WaitDuration = 20 * time.Millisecond
+	err = probe()
+	assert.Equal(t, StatusFail, probeStatusOf(err, true))
+	assert.EqualError(t, err, "connection pool is exhausted: 10 of 10 connections in use, 4 queries waited")
+
+	db.stats.InUse = 5
+	db.stats.WaitCount = 6
+	db.stats.WaitDuration = 100 * time.Millisecond
+	err = probe()
+	assert.Equal(t, StatusFail, probeStatusOf(err, true))
+	assert.EqualError(t, err, "2 queries waited 40ms on average for a connection")
+
+	assert.NoError(t, probe(), "no new waits")
+}
+
+// A driver answering every query with the value configured for it, recording the queries run.
+type fakeSQLDriver struct {
+	mu      sync.Mutex
+	results map[string]driver.Value
+	queries []string
+}
+
+func (d *fakeSQLDriver) Open(name string) (driver.Conn, error) {
+	return &fakeSQLConn{d: d}, nil
+}
+
+type fakeSQLConn struct {
+	d *fakeSQLDriver
+}
+
+func (c *fakeSQLConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeSQLStmt{d: c.d, query: query}, nil
+}
+
+func (c *fakeSQLConn) Close() error              { return nil }
+func (c *fakeSQLConn) Begin() (driver.Tx, error) { return nil, fmt.Errorf("not supported") }
+
+type fakeSQLStmt struct {
+	d     *fakeSQLDriver
+	query string
+}
+
+func (s *fakeSQLStmt) Close() error  { return nil }
+func (s *fakeSQLStmt) NumInput() int { return 0 }
+func (s *fakeSQLStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return nil, fmt.Errorf("not supported")
+}
+
+func (s *fakeSQLStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.d.mu.Lock()
+	defer s.d.mu.Unlock()
+
+	s.d.queries = append(s.d.queries, s.query)
+	value, ok := s.d.results[s.query]
+	if !ok {
+		return nil, fmt.Errorf("unknown query %q", s.query)
+	}
+
+	return &fakeSQLRows{value: value}, nil
+}
+
+type fakeSQLRows struct {
+	value driver.Value
+	done  bool
+}
+
+func (r *fakeSQLRows) Columns() []string { return []string{"result"} }
+func (r *fakeSQLRows) Close() error      { return nil }
+
+func (r *fakeSQLRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0] = r.value
+	return nil
+}
+
+var fakeSQLDrivers uint64
+
+// Registers d under a new name, as database/sql panics if a name is registered twice, e.g. by `go test -count=2`.
+func registerFakeSQLDriver(d driver.Driver) string {
+	name := fmt.Sprintf("health-fake-%d", atomic.AddUint64(&fakeSQLDrivers, 1))
+	sql.Register(name, d)
+	return name
+}
+
+func TestSQLProbe(t *testing.T) {
+	fake := &fakeSQLDriver{results: map[string]driver.Value{
+		"SELECT 1":                            int64(1),
+		"SELECT 1 FROM dual":                  int64(1),
+		sqlReadOnlyQueries[SQLDriverPostgres]: false,
+		sqlReadOnlyQueries[SQLDriverMySQL]:    true,
+	}}
+	db, err := sql.Open(registerFakeSQLDriver(fake), "")
+	assert.NoError(t, err)
+	defer db.Close()
+
+	assert.NoError(t, SQLProbe(db)())
+	assert.NoError(t, SQLProbe(db, SQLDriver(SQLDriverOracle), SQLTimeout(time.Second))())
+	assert.Equal(t, []string{"SELECT 1", "SELECT 1 FROM dual"}, fake.queries)
+
+	assert.NoError(t, SQLProbe(db, SQLDriver(SQLDriverPostgres), SQLExpectWritable())())
+
+	err = SQLProbe(db, SQLDriver(SQLDriverMySQL), SQLExpectWritable())()
+	assert.EqualError(t, err, "database is read-only")
+	assert.True(t, errors.Is(err, ErrUnhealthy))
+
+	err = SQLProbe(db, SQLValidationQuery("SELECT 2"))()
+	assert.True(t, errors.Is(err, ErrUnreachable))
+}
+
+func TestDetectSQLDriver(t *testing.T) {
+	assert.Equal(t, "", detectSQLDriver(&fakeSQLDriver{}))
+}
+
+// A fakeSQLDriver recording the data source names of the opened connections.
+type fakeTokenSQLDriver struct {
+	fakeSQLDriver
+	names []string
+}
+
+func (d *fakeTokenSQLDriver) Open(name string) (driver.Conn, error) {
+	d.mu.Lock()
+	d.names = append(d.names, name)
+	d.mu.Unlock()
+
+	if name == "token=expired" {
+		return nil, fmt.Errorf("access denied")
+	}
+
+	return d.fakeSQLDriver.Open(name)
+}
+
+func TestSQLTokenAuthProbe(t *testing.T) {
+	fake := &fakeTokenSQLDriver{fakeSQLDriver: fakeSQLDriver{results: map[string]driver.Value{"SELECT 1": int64(1)}}}
+
+	tokens := []string{"first", "second", "expired"}
+	i := 0
+	source := func(ctx context.Context) (string, error) {
+		if i == len(tokens) {
+			return "", fmt.Errorf("no credentials")
+		}
+		i++
+		return tokens[i-1], nil
+	}
+	dsn := func(token string) string {
+		return "token=" + token
+	}
+
+	probe := SQLTokenAuthProbe(registerFakeSQLDriver(fake), dsn, source, SQLTimeout(time.Second))
+	assert.NoError(t, probe())
+	assert.NoError(t, probe())
+	assert.Equal(t, []string{"token=first", "token=second"}, fake.names)
+
+	err := probe()
+	assert.True(t, errors.Is(err, ErrUnreachable))
+
+	err = probe()
+	assert.EqualError(t, err, "could not get authentication token: no credentials")
+	assert.True(t, errors.Is(err, ErrUnauthorized))
+}
@@ -0,0 +1,116 @@
+package health
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	defaultSSHTimeout = 10 * time.Second
+	defaultSSHUser    = "healthcheck"
+)
+
+type sshProbeConfig struct {
+	timeout time.Duration
+	hostKey ssh.HostKeyCallback
+	user    string
+	auth    []ssh.AuthMethod
+}
+
+// A SSHOption configures a SSHProbe.
+type SSHOption func(c *sshProbeConfig)
+
+// Sets the timeout of the handshake. Defaults to 10 seconds.
+func SSHTimeout(d time.Duration) SSHOption {
+	return func(c *sshProbeConfig) {
+		c.timeout = d
+	}
+}
+
+// Verifies the host key of the server by callback, e.g. `ssh.FixedHostKey(key)` or a callback of the knownhosts
+// package. The host key is not checked by default.
+func SSHHostKey(callback ssh.HostKeyCallback) SSHOption {
+	return func(c *sshProbeConfig) {
+		c.hostKey = callback
+	}
+}
+
+// Authenticates as user with the given methods, so the probe fails if the credentials are rejected. Without it, the
+// probe passes once the server asks for authentication.
+func SSHAuth(user string, methods ...ssh.AuthMethod) SSHOption {
+	return func(c *sshProbeConfig) {
+		c.user = user
+		c.auth = methods
+	}
+}
+
+// Checks a SSH server for reachability by performing a handshake, for automation services executing tasks on remote
+// machines. No session is opened and no command is executed. Fails if the host key is rejected by SSHHostKey or the
+// handshake is not completed within the timeout.
+//
+// Example:
+//		checker.AddReadinessProbe("build-host", health.SSHProbe("build-01.example:22",
+//			health.SSHHostKey(ssh.FixedHostKey(hostKey)),
+//			health.SSHAuth("deploy", ssh.PublicKeys(signer)),
+//		))
+func SSHProbe(addr string, opts ...SSHOption) Probe {
+	cfg := &sshProbeConfig{timeout: defaultSSHTimeout, user: defaultSSHUser}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return func() error {
+		var hostKeyErr error
+		config := &ssh.ClientConfig{
+			User: cfg.user,
+			Auth: cfg.auth,
+			HostKeyCallback: func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+				if cfg.hostKey == nil {
+					return nil
+				}
+
+				hostKeyErr = cfg.hostKey(hostname, remote, key)
+				return hostKeyErr
+			},
+			Timeout: cfg.timeout,
+		}
+
+		conn, err := net.DialTimeout("tcp", addr, cfg.timeout)
+		if err != nil {
+			return classify(ErrUnreachable, fmt.Errorf("ssh server could not be reached: %w", err))
+		}
+		defer conn.Close()
+		_ = conn.SetDeadline(time.Now().Add(cfg.timeout))
+
+		sshConn, _, _, err := ssh.NewClientConn(conn, addr, config)
+		if err != nil {
+			switch {
+			case hostKeyErr != nil:
+				return classify(ErrUnhealthy, fmt.Errorf("host key verification failed: %w", hostKeyErr))
+			case isSSHAuthError(err):
+				if cfg.auth == nil {
+					return nil
+				}
+
+				return classify(ErrUnauthorized, fmt.Errorf("ssh handshake failed: %w", err))
+			}
+
+			return classify(ErrUnreachable, fmt.Errorf("ssh handshake failed: %w", err))
+		}
+
+		return sshConn.Close()
+	}
+}
+
+func sshProbeFactory(u *url.URL) (Probe, error) {
+	timeout, err := queryDuration(u, "timeout", defaultSSHTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	return SSHProbe(u.Host, SSHTimeout(timeout)), nil
+}
@@ -0,0 +1,43 @@
+package health
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/ssh"
+)
+
+func TestSSHProbe(t *testing.T) {
+	addr := sftpServer(t)
+
+	assert.NoError(t, SSHProbe(addr)(), "handshake without credentials")
+	assert.NoError(t, SSHProbe(addr, SSHAuth("partner", ssh.Password("secret")), SSHTimeout(time.Second))())
+
+	err := SSHProbe(addr, SSHAuth("partner", ssh.Password("wrong")))()
+	assert.True(t, errors.Is(err, ErrUnauthorized))
+
+	other, _, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	key, err := ssh.NewPublicKey(other)
+	assert.NoError(t, err)
+
+	err = SSHProbe(addr, SSHHostKey(ssh.FixedHostKey(key)))()
+	assert.EqualError(t, err, "host key verification failed: ssh: host key mismatch")
+	assert.True(t, errors.Is(err, ErrUnhealthy))
+
+	err = SSHProbe("127.0.0.1:1", SSHTimeout(time.Second))()
+	assert.True(t, errors.Is(err, ErrUnreachable))
+}
+
+func TestProbeFromURI_ssh(t *testing.T) {
+	probe, err := ProbeFromURI("ssh://" + sftpServer(t) + "?timeout=1s")
+	assert.NoError(t, err)
+	assert.NoError(t, probe())
+
+	_, err = ProbeFromURI("ssh://localhost:22?timeout=soon")
+	assert.Error(t, err)
+}
@@ -0,0 +1,56 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultTemporalTimeout = 5 * time.Second
+	// Service the Temporal frontend reports its health for, as checked by `CheckHealth` of the SDK
+	temporalWorkflowService = "temporal.api.workflowservice.v1.WorkflowService"
+)
+
+// Checks the Temporal frontend for readiness, so workflow-driven services stop taking traffic when they can not
+// start or signal workflows. Performs the same gRPC health check as `CheckHealth` of the Temporal SDK on a
+// connection to the frontend. Fails if the check does not finish within timeout, which defaults to 5 seconds if 0.
+//
+// Example:
+//		cc, _ := grpc.Dial("temporal-frontend:7233", grpc.WithInsecure())
+//		checker.AddReadinessProbe("temporal", health.TemporalProbe(cc, time.Second))
+func TemporalProbe(cc grpc.ClientConnInterface, timeout time.Duration) Probe {
+	if timeout == 0 {
+		timeout = defaultTemporalTimeout
+	}
+
+	client := grpc_health_v1.NewHealthClient(cc)
+
+	return func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		resp, err := client.Check(ctx, &grpc_health_v1.HealthCheckRequest{Service: temporalWorkflowService})
+		if err != nil {
+			switch status.Code(err) {
+			case codes.DeadlineExceeded:
+				return &timeoutError{timeout: timeout}
+			case codes.Unauthenticated, codes.PermissionDenied:
+				return classify(ErrUnauthorized, fmt.Errorf("temporal health check failed: %w", err))
+			}
+
+			return classify(ErrUnreachable, fmt.Errorf("temporal frontend could not be reached: %w", err))
+		}
+
+		if resp.Status != grpc_health_v1.HealthCheckResponse_SERVING {
+			return classify(ErrUnhealthy, fmt.Errorf("temporal frontend is %v", resp.Status))
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,47 @@
+package health
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	grpchealth "google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestTemporalProbe(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+
+	grpcServer := grpc.NewServer()
+	healthServer := grpchealth.NewServer()
+	healthServer.SetServingStatus(temporalWorkflowService, grpc_health_v1.HealthCheckResponse_SERVING)
+	grpc_health_v1.RegisterHealthServer(grpcServer, healthServer)
+	go func() { _ = grpcServer.Serve(lis) }()
+	defer grpcServer.Stop()
+
+	cc, err := grpc.Dial(lis.Addr().String(), grpc.WithInsecure())
+	assert.NoError(t, err)
+	defer cc.Close()
+
+	probe := TemporalProbe(cc, time.Second)
+	assert.NoError(t, probe())
+
+	healthServer.SetServingStatus(temporalWorkflowService, grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+	err = probe()
+	assert.EqualError(t, err, "temporal frontend is NOT_SERVING")
+	assert.True(t, errors.Is(err, ErrUnhealthy))
+}
+
+func TestTemporalProbe_unreachable(t *testing.T) {
+	cc, err := grpc.Dial("127.0.0.1:1", grpc.WithInsecure())
+	assert.NoError(t, err)
+	defer cc.Close()
+
+	err = TemporalProbe(cc, time.Second)()
+	assert.Error(t, err)
+	assert.NotEqual(t, KindError, reasonKindOf(err))
+}
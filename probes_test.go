@@ -2,15 +2,25 @@ package health
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io/ioutil"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"regexp"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	vault "github.com/hashicorp/vault/api"
 	"github.com/nats-io/go-nats"
 	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.mongodb.org/mongo-driver/mongo/readpref"
 	"google.golang.org/grpc/connectivity"
 )
@@ -52,6 +62,70 @@ func TestGrpcProbe_err(t *testing.T) {
 	assert.Error(t, probe())
 }
 
+// Mock of a connection which becomes ready after Connect has been called.
+type MockReconnectingGrpcConn struct {
+	mu        sync.Mutex
+	state     connectivity.State
+	connected bool
+}
+
+func (m *MockReconnectingGrpcConn) GetState() connectivity.State {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.state
+}
+
+func (m *MockReconnectingGrpcConn) Connect() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.connected = true
+	m.state = connectivity.Connecting
+}
+
+func (m *MockReconnectingGrpcConn) WaitForStateChange(ctx context.Context, source connectivity.State) bool {
+	m.mu.Lock()
+	connected := m.connected
+	m.mu.Unlock()
+
+	if !connected {
+		<-ctx.Done()
+		return false
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.state = connectivity.Ready
+	return true
+}
+
+func TestGrpcProbe_reconnect(t *testing.T) {
+	conn := &MockReconnectingGrpcConn{state: connectivity.Idle}
+
+	probe := GrpcProbe(conn, GrpcReconnect(time.Second))
+
+	assert.NoError(t, probe())
+	assert.True(t, conn.connected)
+}
+
+func TestGrpcProbe_err_idleWithoutReconnect(t *testing.T) {
+	conn := &MockReconnectingGrpcConn{state: connectivity.Idle}
+
+	probe := GrpcProbe(conn)
+
+	assert.Error(t, probe())
+	assert.False(t, conn.connected)
+}
+
+func TestGrpcProbe_err_reconnectTimeout(t *testing.T) {
+	reporter := &MockGrpcReporter{
+		state: connectivity.Connecting,
+	}
+
+	probe := GrpcProbe(reporter, GrpcReconnect(10*time.Millisecond))
+
+	assert.Error(t, probe())
+}
+
 func TestHTTPProbe(t *testing.T) {
 	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(200)
@@ -72,16 +146,149 @@ func TestHTTPProbe_err(t *testing.T) {
 	assert.Error(t, probe())
 }
 
+func TestHTTPProbe_options(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if r.Method != http.MethodPost || r.Header.Get("Authorization") != "Bearer token" || r.Host != "my-service" || string(body) != "ping" {
+			w.WriteHeader(400)
+			return
+		}
+
+		w.WriteHeader(401)
+	}))
+	defer s.Close()
+
+	probe := HTTPProbe(s.URL,
+		HTTPMethod(http.MethodPost),
+		HTTPHeader("Authorization", "Bearer token"),
+		HTTPHeader("Host", "my-service"),
+		HTTPBody([]byte("ping")),
+		HTTPExpectStatus(401),
+	)
+
+	assert.NoError(t, probe())
+}
+
+func TestHTTPProbe_err_unexpectedStatus(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(204)
+	}))
+	defer s.Close()
+
+	probe := HTTPProbe(s.URL, HTTPExpectStatus(200), HTTPExpectStatusRange(300, 399))
+	assert.Error(t, probe())
+}
+
+func TestHTTPProbe_client(t *testing.T) {
+	s := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(200)
+	}))
+	defer s.Close()
+
+	assert.Error(t, HTTPProbe(s.URL)())
+	assert.NoError(t, HTTPProbe(s.URL, HTTPClient(s.Client()))())
+}
+
+func TestHTTPProbe_sharedTransport(t *testing.T) {
+	var mu sync.Mutex
+	conns := 0
+	s := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(200)
+	}))
+	s.Config.ConnState = func(c net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			mu.Lock()
+			conns++
+			mu.Unlock()
+		}
+	}
+	s.Start()
+	defer s.Close()
+
+	probe := HTTPProbe(s.URL)
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, probe())
+	}
+
+	mu.Lock()
+	assert.Equal(t, 1, conns)
+	mu.Unlock()
+}
+
+type countingTransport struct {
+	requests int
+}
+
+func (t *countingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	t.requests++
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func TestHTTPProbe_transport(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(200)
+	}))
+	defer s.Close()
+
+	transport := &countingTransport{}
+	assert.NoError(t, HTTPProbe(s.URL, HTTPTransport(transport))())
+	assert.Equal(t, 1, transport.requests)
+}
+
+func TestHTTPProbe_err_timeout(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer s.Close()
+
+	probe := HTTPProbe(s.URL, HTTPTimeout(10*time.Millisecond))
+	assert.Error(t, probe())
+}
+
+func TestHTTPProbe_bodyAssertions(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"status":"ok","checks":{"db":{"up":true,"latency":3}}}`))
+	}))
+	defer s.Close()
+
+	probe := HTTPProbe(s.URL,
+		HTTPExpectBody(`"status":"ok"`),
+		HTTPExpectBodyMatch(regexp.MustCompile(`"latency":\d+`)),
+		HTTPExpectJSON("status", "ok"),
+		HTTPExpectJSON("checks.db.up", true),
+		HTTPExpectJSON("checks.db.latency", 3),
+	)
+
+	assert.NoError(t, probe())
+}
+
+func TestHTTPProbe_err_bodyAssertions(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"status":"error","checks":{"db":{"up":false}}}`))
+	}))
+	defer s.Close()
+
+	assert.Error(t, HTTPProbe(s.URL, HTTPExpectBody(`"status":"ok"`))())
+	assert.Error(t, HTTPProbe(s.URL, HTTPExpectBodyMatch(regexp.MustCompile(`ok`)))())
+	assert.Error(t, HTTPProbe(s.URL, HTTPExpectJSON("status", "ok"))())
+	assert.Error(t, HTTPProbe(s.URL, HTTPExpectJSON("checks.db.up", true))())
+	assert.Error(t, HTTPProbe(s.URL, HTTPExpectJSON("checks.cache.up", true))())
+}
+
 func TestHTTPProbe_err_invalidUrl(t *testing.T) {
 	probe := HTTPProbe("http://not-valid-endpoint.localhost/not-healthy")
 	assert.Error(t, probe())
 }
 
 type MockMongoReporter struct {
-	err error
+	err         error
+	readPref    *readpref.ReadPref
+	hasDeadline bool
 }
 
-func (m MockMongoReporter) Ping(_ context.Context, _ *readpref.ReadPref) error {
+func (m *MockMongoReporter) Ping(ctx context.Context, rp *readpref.ReadPref) error {
+	m.readPref = rp
+	_, m.hasDeadline = ctx.Deadline()
 	return m.err
 }
 
@@ -105,6 +312,62 @@ func TestMongoProbe_err(t *testing.T) {
 	assert.Error(t, probe())
 }
 
+func TestMongoProbe_options(t *testing.T) {
+	reporter := &MockMongoReporter{}
+
+	probe := MongoProbe(reporter, MongoReadPref(readpref.PrimaryPreferred()), MongoTimeout(time.Second))
+
+	assert.NoError(t, probe())
+	assert.Equal(t, readpref.PrimaryPreferredMode, reporter.readPref.Mode())
+	assert.True(t, reporter.hasDeadline)
+}
+
+type MockMongoCommandRunner struct {
+	command interface{}
+}
+
+func (m *MockMongoCommandRunner) RunCommand(_ context.Context, cmd interface{}, _ ...*options.RunCmdOptions) *mongo.SingleResult {
+	m.command = cmd
+	return &mongo.SingleResult{}
+}
+
+func TestMongoReplicaSetProbe_err(t *testing.T) {
+	runner := &MockMongoCommandRunner{}
+
+	probe := MongoReplicaSetProbe(runner, 1)
+
+	assert.Error(t, probe())
+	assert.Equal(t, bson.D{{Key: "replSetGetStatus", Value: 1}}, runner.command)
+}
+
+func newReplicaSetStatus(states ...int) *mongoReplicaSetStatus {
+	status := &mongoReplicaSetStatus{}
+	for i, state := range states {
+		status.Members = append(status.Members, mongoReplicaSetMember{Name: fmt.Sprintf("mongo-%v", i), Health: 1, State: state})
+	}
+
+	return status
+}
+
+func TestCheckReplicaSetStatus(t *testing.T) {
+	status := newReplicaSetStatus(mongoStatePrimary, mongoStateSecondary, mongoStateSecondary)
+
+	assert.NoError(t, checkReplicaSetStatus(status, 2))
+}
+
+func TestCheckReplicaSetStatus_failsWithoutPrimary(t *testing.T) {
+	status := newReplicaSetStatus(mongoStateSecondary, mongoStateSecondary)
+
+	assert.Error(t, checkReplicaSetStatus(status, 0))
+}
+
+func TestCheckReplicaSetStatus_failsForUnhealthySecondaries(t *testing.T) {
+	status := newReplicaSetStatus(mongoStatePrimary, mongoStateSecondary, mongoStateSecondary)
+	status.Members[2].Health = 0
+
+	assert.Error(t, checkReplicaSetStatus(status, 2))
+}
+
 type MockNatsReporter struct {
 	state nats.Status
 }
@@ -133,6 +396,21 @@ func TestNatsProbe_err(t *testing.T) {
 	assert.Error(t, probe())
 }
 
+func TestTCPProbe(t *testing.T) {
+	l, _ := net.Listen("tcp", "127.0.0.1:0")
+	defer l.Close()
+
+	probe := TCPProbe(l.Addr().String(), time.Second)
+
+	assert.NoError(t, probe())
+}
+
+func TestTCPProbe_err(t *testing.T) {
+	probe := TCPProbe("127.0.0.1:1", time.Second)
+
+	assert.Error(t, probe())
+}
+
 func TestVaultProbe(t *testing.T) {
 	reporter := &MockVaultHealthReporter{
 		health: &vault.HealthResponse{
@@ -189,6 +467,34 @@ func TestVaultProbe_failsForVaultInStandby(t *testing.T) {
 	assert.Error(t, probe())
 }
 
+func TestVaultProbe_allowStandby(t *testing.T) {
+	reporter := &MockVaultHealthReporter{
+		health: &vault.HealthResponse{
+			Initialized: true,
+			Sealed:      false,
+			Standby:     true,
+		},
+	}
+
+	probe := VaultProbe(reporter, AllowStandby())
+
+	assert.NoError(t, probe())
+}
+
+func TestVaultProbe_allowStandby_failsForSealedVault(t *testing.T) {
+	reporter := &MockVaultHealthReporter{
+		health: &vault.HealthResponse{
+			Initialized: true,
+			Sealed:      true,
+			Standby:     true,
+		},
+	}
+
+	probe := VaultProbe(reporter, AllowStandby())
+
+	assert.Error(t, probe())
+}
+
 func TestVaultProbe_failsForErrorDuringHealthCheck(t *testing.T) {
 	reporter := &MockVaultHealthReporter{
 		health: nil,
@@ -199,3 +505,85 @@ func TestVaultProbe_failsForErrorDuringHealthCheck(t *testing.T) {
 
 	assert.Error(t, probe())
 }
+
+type MockVaultTokenLookuper struct {
+	secret *vault.Secret
+	err    error
+}
+
+func (m MockVaultTokenLookuper) LookupSelf() (*vault.Secret, error) {
+	return m.secret, m.err
+}
+
+func TestVaultTokenProbe(t *testing.T) {
+	lookuper := &MockVaultTokenLookuper{
+		secret: &vault.Secret{Data: map[string]interface{}{"ttl": json.Number("3600")}},
+	}
+
+	probe := VaultTokenProbe(lookuper, 5*time.Minute)
+
+	assert.NoError(t, probe())
+}
+
+func TestVaultTokenProbe_nonExpiringToken(t *testing.T) {
+	lookuper := &MockVaultTokenLookuper{
+		secret: &vault.Secret{Data: map[string]interface{}{"ttl": json.Number("0")}},
+	}
+
+	probe := VaultTokenProbe(lookuper, 5*time.Minute)
+
+	assert.NoError(t, probe())
+}
+
+func TestVaultTokenProbe_failsForTokenAboutToExpire(t *testing.T) {
+	lookuper := &MockVaultTokenLookuper{
+		secret: &vault.Secret{Data: map[string]interface{}{"ttl": json.Number("60")}},
+	}
+
+	probe := VaultTokenProbe(lookuper, 5*time.Minute)
+
+	assert.True(t, errors.Is(probe(), ErrUnauthorized))
+}
+
+func TestVaultTokenProbe_failsForErrorDuringLookup(t *testing.T) {
+	lookuper := &MockVaultTokenLookuper{
+		err: fmt.Errorf("connection refused"),
+	}
+
+	probe := VaultTokenProbe(lookuper, 5*time.Minute)
+
+	assert.True(t, errors.Is(probe(), ErrUnreachable))
+}
+
+func TestVaultTokenProbe_failsForForbiddenLookup(t *testing.T) {
+	lookuper := &MockVaultTokenLookuper{
+		err: &vault.ResponseError{StatusCode: http.StatusForbidden, Errors: []string{"permission denied"}},
+	}
+
+	probe := VaultTokenProbe(lookuper, 5*time.Minute)
+
+	assert.True(t, errors.Is(probe(), ErrUnauthorized))
+}
+
+func TestBoundedRunner(t *testing.T) {
+	release := make(chan struct{})
+	runs := int32(0)
+	hanging := func() error {
+		atomic.AddInt32(&runs, 1)
+		<-release
+		return fmt.Errorf("connection reset")
+	}
+
+	runner := &boundedRunner{}
+	for i := 0; i < 3; i++ {
+		err := runner.runUntil(context.Background(), time.After(10*time.Millisecond), 10*time.Millisecond, hanging)
+		assert.EqualError(t, err, "timed out after 10ms")
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&runs), "no new run while the first one hangs")
+
+	close(release)
+	assert.EqualError(t, runner.runUntil(context.Background(), time.After(time.Second), time.Second, hanging), "connection reset")
+	assert.Eventually(t, func() bool {
+		return runner.runUntil(context.Background(), time.After(time.Second), time.Second, func() error { return nil }) == nil
+	}, time.Second, time.Millisecond)
+}
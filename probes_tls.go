@@ -0,0 +1,153 @@
+package health
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"time"
+)
+
+const defaultTLSDialTimeout = 5 * time.Second
+
+type tlsProbeConfig struct {
+	tlsConfig   *tls.Config
+	dialTimeout time.Duration
+}
+
+// A TLSOption configures a TLSProbe.
+type TLSOption func(c *tlsProbeConfig)
+
+// Sets the tls config used for the handshake, e.g. to provide custom root CAs.
+// If no server name is set, the host of the probed address is used.
+func TLSConfig(cfg *tls.Config) TLSOption {
+	return func(c *tlsProbeConfig) {
+		c.tlsConfig = cfg
+	}
+}
+
+// Sets the timeout for establishing the connection and completing the handshake. Defaults to 5 seconds.
+func TLSDialTimeout(d time.Duration) TLSOption {
+	return func(c *tlsProbeConfig) {
+		c.dialTimeout = d
+	}
+}
+
+// Performs a TLS handshake against addr (host:port) and verifies the presented certificate chain.
+// Fails if the leaf certificate expires within the given window, so a missed certificate rotation
+// is reported before clients start to reject the connection.
+//
+// Example:
+//		checker.AddReadinessProbe("api-tls", health.TLSProbe("api.example.com:443", 14*24*time.Hour))
+func TLSProbe(addr string, expiresWithin time.Duration, opts ...TLSOption) Probe {
+	c := &tlsProbeConfig{
+		dialTimeout: defaultTLSDialTimeout,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return func() error {
+		cfg := &tls.Config{}
+		if c.tlsConfig != nil {
+			cfg = c.tlsConfig.Clone()
+		}
+
+		if cfg.ServerName == "" {
+			host, _, err := net.SplitHostPort(addr)
+			if err != nil {
+				return fmt.Errorf("invalid address %v: %w", addr, err)
+			}
+
+			cfg.ServerName = host
+		}
+
+		conn, err := tls.DialWithDialer(&net.Dialer{Timeout: c.dialTimeout}, "tcp", addr, cfg)
+		if err != nil {
+			return fmt.Errorf("tls handshake failed: %w", err)
+		}
+		defer conn.Close()
+
+		certs := conn.ConnectionState().PeerCertificates
+		if len(certs) == 0 {
+			return fmt.Errorf("no certificate presented by %v", addr)
+		}
+
+		return checkCertificateExpiry(certs[0].NotAfter, expiresWithin)
+	}
+}
+
+// Parses the first PEM encoded certificate of the file at path and fails if it is expired or expires within the given window.
+// The file is read on each check, thus rotated certificates of mounted secrets are picked up.
+//
+// Example:
+//		checker.AddReadinessProbe("server-cert", health.CertificateFileProbe("/etc/tls/tls.crt", 7*24*time.Hour))
+func CertificateFileProbe(path string, expiresWithin time.Duration) Probe {
+	return func() error {
+		// #nosec G304
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("could not read certificate: %w", err)
+		}
+
+		for {
+			var block *pem.Block
+			block, b = pem.Decode(b)
+			if block == nil {
+				return fmt.Errorf("no certificate found in %v", path)
+			}
+
+			if block.Type != "CERTIFICATE" {
+				continue
+			}
+
+			cert, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				return fmt.Errorf("could not parse certificate: %w", err)
+			}
+
+			return checkCertificateExpiry(cert.NotAfter, expiresWithin)
+		}
+	}
+}
+
+// Checks the leaf of an already loaded certificate and fails if it is expired or expires within the given window.
+//
+// Example:
+//		cert, _ := tls.LoadX509KeyPair("tls.crt", "tls.key")
+//		checker.AddReadinessProbe("server-cert", health.CertificateProbe(&cert, 7*24*time.Hour))
+func CertificateProbe(cert *tls.Certificate, expiresWithin time.Duration) Probe {
+	return func() error {
+		leaf := cert.Leaf
+		if leaf == nil {
+			if len(cert.Certificate) == 0 {
+				return fmt.Errorf("certificate is empty")
+			}
+
+			var err error
+			leaf, err = x509.ParseCertificate(cert.Certificate[0])
+			if err != nil {
+				return fmt.Errorf("could not parse certificate: %w", err)
+			}
+		}
+
+		return checkCertificateExpiry(leaf.NotAfter, expiresWithin)
+	}
+}
+
+// Returns an error if notAfter is in the past or within the given window from now.
+func checkCertificateExpiry(notAfter time.Time, expiresWithin time.Duration) error {
+	remaining := time.Until(notAfter)
+	if remaining <= 0 {
+		return fmt.Errorf("certificate expired at %v", notAfter.Format(time.RFC3339))
+	}
+
+	if remaining < expiresWithin {
+		return fmt.Errorf("certificate expires at %v", notAfter.Format(time.RFC3339))
+	}
+
+	return nil
+}
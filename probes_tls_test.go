@@ -0,0 +1,136 @@
+package health
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTLSTestServer() (*httptest.Server, *tls.Config) {
+	s := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	pool := x509.NewCertPool()
+	pool.AddCert(s.Certificate())
+
+	return s, &tls.Config{RootCAs: pool}
+}
+
+func TestTLSProbe(t *testing.T) {
+	s, cfg := newTLSTestServer()
+	defer s.Close()
+
+	probe := TLSProbe(strings.TrimPrefix(s.URL, "https://"), 24*time.Hour, TLSConfig(cfg))
+
+	assert.NoError(t, probe())
+}
+
+func TestTLSProbe_err_expiresWithinWindow(t *testing.T) {
+	s, cfg := newTLSTestServer()
+	defer s.Close()
+
+	probe := TLSProbe(strings.TrimPrefix(s.URL, "https://"), 200*365*24*time.Hour, TLSConfig(cfg))
+
+	assert.Error(t, probe())
+}
+
+func TestTLSProbe_err_untrustedChain(t *testing.T) {
+	s, _ := newTLSTestServer()
+	defer s.Close()
+
+	probe := TLSProbe(strings.TrimPrefix(s.URL, "https://"), 24*time.Hour)
+
+	assert.Error(t, probe())
+}
+
+func TestTLSProbe_err_unreachable(t *testing.T) {
+	probe := TLSProbe("127.0.0.1:1", 24*time.Hour, TLSDialTimeout(time.Second))
+
+	assert.Error(t, probe())
+}
+
+// Creates a self-signed certificate valid until notAfter
+func newTestCertificate(t *testing.T, notAfter time.Time) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     notAfter,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+func writeTestCertificate(t *testing.T, cert tls.Certificate) string {
+	f, err := ioutil.TempFile("", "healthchecker-cert")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	_ = pem.Encode(f, &pem.Block{Type: "EC PRIVATE KEY", Bytes: []byte{}})
+	_ = pem.Encode(f, &pem.Block{Type: "CERTIFICATE", Bytes: cert.Certificate[0]})
+
+	return f.Name()
+}
+
+func TestCertificateFileProbe(t *testing.T) {
+	path := writeTestCertificate(t, newTestCertificate(t, time.Now().Add(30*24*time.Hour)))
+	defer os.Remove(path)
+
+	probe := CertificateFileProbe(path, 7*24*time.Hour)
+
+	assert.NoError(t, probe())
+}
+
+func TestCertificateFileProbe_err_expiresWithinWindow(t *testing.T) {
+	path := writeTestCertificate(t, newTestCertificate(t, time.Now().Add(24*time.Hour)))
+	defer os.Remove(path)
+
+	probe := CertificateFileProbe(path, 7*24*time.Hour)
+
+	assert.Error(t, probe())
+}
+
+func TestCertificateFileProbe_err_missingFile(t *testing.T) {
+	probe := CertificateFileProbe("/not/existing/tls.crt", time.Hour)
+
+	assert.Error(t, probe())
+}
+
+func TestCertificateProbe(t *testing.T) {
+	cert := newTestCertificate(t, time.Now().Add(30*24*time.Hour))
+
+	probe := CertificateProbe(&cert, 7*24*time.Hour)
+
+	assert.NoError(t, probe())
+}
+
+func TestCertificateProbe_err_expired(t *testing.T) {
+	cert := newTestCertificate(t, time.Now().Add(-time.Minute))
+
+	probe := CertificateProbe(&cert, 0)
+
+	assert.Error(t, probe())
+}
@@ -0,0 +1,256 @@
+package health
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	defaultWebSocketTimeout = 5 * time.Second
+	// Appended to the key of the handshake to compute Sec-WebSocket-Accept, see RFC 6455
+	webSocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+	// Opcodes of the frames used by the probe
+	wsOpClose = 0x8
+	wsOpPing  = 0x9
+	wsOpPong  = 0xA
+)
+
+type webSocketProbeConfig struct {
+	timeout   time.Duration
+	ping      bool
+	header    http.Header
+	tlsConfig *tls.Config
+}
+
+// A WebSocketOption configures a WebSocketProbe.
+type WebSocketOption func(c *webSocketProbeConfig)
+
+// Sets the timeout for connecting, the handshake and the ping. Defaults to 5 seconds.
+func WebSocketTimeout(d time.Duration) WebSocketOption {
+	return func(c *webSocketProbeConfig) {
+		c.timeout = d
+	}
+}
+
+// Sends a ping frame after the handshake and fails if the server does not answer with a pong.
+func WebSocketPing() WebSocketOption {
+	return func(c *webSocketProbeConfig) {
+		c.ping = true
+	}
+}
+
+// Adds a header to the upgrade request, e.g. `Origin` or `Authorization`.
+func WebSocketHeader(key, value string) WebSocketOption {
+	return func(c *webSocketProbeConfig) {
+		c.header.Add(key, value)
+	}
+}
+
+// Sets the tls config used for `wss://` URLs, e.g. to provide custom root CAs.
+func WebSocketTLSConfig(cfg *tls.Config) WebSocketOption {
+	return func(c *webSocketProbeConfig) {
+		c.tlsConfig = cfg
+	}
+}
+
+// Performs a WebSocket upgrade against a `ws://` or `wss://` URL, for realtime gateways whose plain HTTP endpoints
+// work while the upgrade path is broken, e.g. by a proxy stripping the Upgrade header. Fails if the server does not
+// switch protocols with a valid Sec-WebSocket-Accept header.
+//
+// Example:
+//		checker.AddReadinessProbe("gateway", health.WebSocketProbe("wss://gateway:8443/ws", health.WebSocketPing()))
+func WebSocketProbe(rawURL string, opts ...WebSocketOption) Probe {
+	c := &webSocketProbeConfig{timeout: defaultWebSocketTimeout, header: http.Header{}}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return func() error {
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			return fmt.Errorf("invalid url: %w", err)
+		}
+
+		conn, err := c.dial(u)
+		if err != nil {
+			return classify(ErrUnreachable, fmt.Errorf("endpoint could not be reached: %w", err))
+		}
+		defer conn.Close()
+
+		_ = conn.SetDeadline(time.Now().Add(c.timeout))
+
+		br := bufio.NewReader(conn)
+		if err := c.handshake(conn, br, u); err != nil {
+			return err
+		}
+
+		if c.ping {
+			if err := writeFrame(conn, wsOpPing, []byte("health")); err != nil {
+				return fmt.Errorf("could not send ping: %w", err)
+			}
+
+			if err := awaitPong(br); err != nil {
+				return classify(ErrUnhealthy, err)
+			}
+		}
+
+		// Close normally, the connection is closed anyway if the server does not answer
+		_ = writeFrame(conn, wsOpClose, []byte{0x03, 0xe8})
+
+		return nil
+	}
+}
+
+func (c *webSocketProbeConfig) dial(u *url.URL) (net.Conn, error) {
+	host := u.Host
+	dialer := &net.Dialer{Timeout: c.timeout}
+
+	switch u.Scheme {
+	case "ws":
+		if u.Port() == "" {
+			host = net.JoinHostPort(u.Hostname(), "80")
+		}
+		return dialer.Dial("tcp", host)
+	case "wss":
+		if u.Port() == "" {
+			host = net.JoinHostPort(u.Hostname(), "443")
+		}
+		cfg := &tls.Config{}
+		if c.tlsConfig != nil {
+			cfg = c.tlsConfig.Clone()
+		}
+		if cfg.ServerName == "" {
+			cfg.ServerName = u.Hostname()
+		}
+		return tls.DialWithDialer(dialer, "tcp", host, cfg)
+	}
+
+	return nil, fmt.Errorf("unsupported scheme %q", u.Scheme)
+}
+
+// Sends the upgrade request and verifies the server switched protocols.
+func (c *webSocketProbeConfig) handshake(conn net.Conn, br *bufio.Reader, u *url.URL) error {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("could not generate key: %w", err)
+	}
+	key := base64.StdEncoding.EncodeToString(nonce)
+
+	req := &http.Request{
+		Method:     http.MethodGet,
+		URL:        &url.URL{Path: u.Path, RawPath: u.RawPath, RawQuery: u.RawQuery},
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     c.header.Clone(),
+		Host:       u.Host,
+	}
+	if req.URL.Path == "" {
+		req.URL.Path = "/"
+	}
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Sec-WebSocket-Key", key)
+	req.Header.Set("Sec-WebSocket-Version", "13")
+
+	if err := req.Write(conn); err != nil {
+		return classify(ErrUnreachable, fmt.Errorf("could not send upgrade request: %w", err))
+	}
+
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		return classify(ErrUnhealthy, fmt.Errorf("invalid upgrade response: %w", err))
+	}
+
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		_ = resp.Body.Close()
+		err := fmt.Errorf("upgrade was rejected: %v", resp.Status)
+		if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+			return classify(ErrUnauthorized, err)
+		}
+
+		return classify(ErrUnhealthy, err)
+	}
+
+	if !strings.EqualFold(resp.Header.Get("Upgrade"), "websocket") {
+		return classify(ErrUnhealthy, fmt.Errorf("server switched to protocol %q", resp.Header.Get("Upgrade")))
+	}
+
+	sum := sha1.Sum([]byte(key + webSocketGUID))
+	if resp.Header.Get("Sec-WebSocket-Accept") != base64.StdEncoding.EncodeToString(sum[:]) {
+		return classify(ErrUnhealthy, fmt.Errorf("invalid Sec-WebSocket-Accept header"))
+	}
+
+	return nil
+}
+
+// Writes a masked frame as required for clients. Only small control frames are sent by the probe.
+func writeFrame(w io.Writer, opcode byte, payload []byte) error {
+	mask := make([]byte, 4)
+	if _, err := rand.Read(mask); err != nil {
+		return err
+	}
+
+	frame := append([]byte{0x80 | opcode, 0x80 | byte(len(payload))}, mask...)
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+
+	_, err := w.Write(frame)
+	return err
+}
+
+// Reads frames until a pong is received, skipping messages sent by the server in the meantime.
+func awaitPong(r *bufio.Reader) error {
+	for {
+		header := make([]byte, 2)
+		if _, err := io.ReadFull(r, header); err != nil {
+			return fmt.Errorf("no pong received: %w", err)
+		}
+
+		opcode := header[0] & 0x0f
+		length := uint64(header[1] & 0x7f)
+
+		switch length {
+		case 126:
+			b := make([]byte, 2)
+			if _, err := io.ReadFull(r, b); err != nil {
+				return fmt.Errorf("no pong received: %w", err)
+			}
+			length = uint64(binary.BigEndian.Uint16(b))
+		case 127:
+			b := make([]byte, 8)
+			if _, err := io.ReadFull(r, b); err != nil {
+				return fmt.Errorf("no pong received: %w", err)
+			}
+			length = binary.BigEndian.Uint64(b)
+		}
+
+		if header[1]&0x80 != 0 {
+			length += 4
+		}
+
+		if _, err := io.CopyN(ioutil.Discard, r, int64(length)); err != nil {
+			return fmt.Errorf("no pong received: %w", err)
+		}
+
+		switch opcode {
+		case wsOpPong:
+			return nil
+		case wsOpClose:
+			return fmt.Errorf("server closed the connection instead of answering the ping")
+		}
+	}
+}
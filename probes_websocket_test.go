@@ -0,0 +1,64 @@
+package health
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/websocket"
+)
+
+func TestWebSocketProbe(t *testing.T) {
+	server := httptest.NewServer(websocket.Handler(func(ws *websocket.Conn) {
+		// Reading answers pings with pongs
+		_, _ = io.Copy(ioutil.Discard, ws)
+	}))
+	defer server.Close()
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http")
+	assert.NoError(t, WebSocketProbe(url, WebSocketHeader("Origin", server.URL))())
+	assert.NoError(t, WebSocketProbe(url, WebSocketPing(), WebSocketTimeout(time.Second), WebSocketHeader("Origin", server.URL))())
+
+	// The handler rejects requests without origin
+	assert.True(t, errors.Is(WebSocketProbe(url)(), ErrUnauthorized))
+}
+
+func TestWebSocketProbe_noUpgrade(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	err := WebSocketProbe("ws" + strings.TrimPrefix(server.URL, "http"))()
+	assert.EqualError(t, err, "upgrade was rejected: 200 OK")
+	assert.True(t, errors.Is(err, ErrUnhealthy))
+}
+
+func TestWebSocketProbe_noPong(t *testing.T) {
+	server := httptest.NewServer(websocket.Handler(func(ws *websocket.Conn) {
+		// Not reading, so pings are never answered
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	err := WebSocketProbe("ws"+strings.TrimPrefix(server.URL, "http"), WebSocketPing(), WebSocketTimeout(50*time.Millisecond), WebSocketHeader("Origin", server.URL))()
+	assert.Error(t, err)
+	assert.Equal(t, KindTimeout, reasonKindOf(err))
+}
+
+func TestWebSocketProbe_unreachable(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	addr := lis.Addr().String()
+	_ = lis.Close()
+
+	err = WebSocketProbe("ws://" + addr)()
+	assert.True(t, errors.Is(err, ErrUnreachable))
+}
@@ -0,0 +1,211 @@
+package health
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var statuses = []Status{StatusPass, StatusWarn, StatusFail, StatusStarting, StatusStandby}
+
+// Writes the result in the Prometheus text exposition format. The following metrics are written:
+//
+//		health_ready                            1 if the service is ready, 0 otherwise
+//		health_status{status}                   1 for the status of the service, 0 for the others
+//		health_probe_up{probe,critical}         1 if the probe passed, 0 otherwise
+//		health_probe_status{probe,status}       1 for the status of the probe, 0 for the others
+//		health_probe_duration_seconds{probe}    time the probe took
+//		health_check_timestamp_seconds          time of the evaluation
+//
+// The health_probe_up and health_probe_status series carry the labels of the probe as well, see Labels.
+func WritePrometheus(w io.Writer, r Result) error {
+	return writeMetrics(w, r, nil, false)
+}
+
+// Failures of a probe since the start of the service and the number of runs shared with other callers.
+type probeFailures struct {
+	name   string
+	count  uint64
+	last   ProbeResult
+	shared uint64
+}
+
+// Writes the result and the failure counters in the Prometheus text format or in the OpenMetrics format, which
+// includes the trace IDs of the latest failures as exemplars.
+func writeMetrics(w io.Writer, r Result, failures []probeFailures, openMetrics bool) error {
+	bw := bufio.NewWriter(w)
+
+	writeMetricHeader(bw, "health_ready", "gauge", "Whether the service is ready.")
+	fmt.Fprintf(bw, "health_ready %v\n", boolValue(r.Ready))
+
+	writeMetricHeader(bw, "health_status", "gauge", "Status of the service.")
+	for _, s := range statuses {
+		fmt.Fprintf(bw, "health_status{status=%q} %v\n", s, boolValue(r.Status == s))
+	}
+
+	writeMetricHeader(bw, "health_probe_up", "gauge", "Whether the probe passed.")
+	for _, p := range r.Probes {
+		fmt.Fprintf(bw, "health_probe_up{probe=\"%v\",critical=\"%v\"%v} %v\n", escapeLabel(p.Name), p.Critical, formatMetricLabels(p.Labels), boolValue(p.Err == nil))
+	}
+
+	writeMetricHeader(bw, "health_probe_status", "gauge", "Status of the probe.")
+	for _, p := range r.Probes {
+		for _, s := range statuses {
+			fmt.Fprintf(bw, "health_probe_status{probe=\"%v\",status=%q%v} %v\n", escapeLabel(p.Name), s, formatMetricLabels(p.Labels), boolValue(p.Status == s))
+		}
+	}
+
+	writeMetricHeader(bw, "health_probe_duration_seconds", "gauge", "Time the probe took.")
+	for _, p := range r.Probes {
+		fmt.Fprintf(bw, "health_probe_duration_seconds{probe=\"%v\"} %v\n", escapeLabel(p.Name), p.Duration.Seconds())
+	}
+
+	writeMetricHeader(bw, "health_check_timestamp_seconds", "gauge", "Time of the evaluation.")
+	fmt.Fprintf(bw, "health_check_timestamp_seconds %v\n", formatTimestamp(r.CheckedAt))
+
+	if len(failures) > 0 {
+		// OpenMetrics names the counter family without the _total suffix
+		if openMetrics {
+			writeMetricHeader(bw, "health_probe_failures", "counter", "Number of failures of the probe.")
+		} else {
+			writeMetricHeader(bw, "health_probe_failures_total", "counter", "Number of failures of the probe.")
+		}
+
+		for _, f := range failures {
+			fmt.Fprintf(bw, "health_probe_failures_total{probe=\"%v\"} %v", escapeLabel(f.name), f.count)
+			if traceID := traceIDOf(f.last.Err); openMetrics && traceID != "" {
+				fmt.Fprintf(bw, " # {trace_id=\"%v\"} 1 %v", escapeLabel(traceID), formatTimestamp(f.last.CheckedAt))
+			}
+			fmt.Fprintln(bw)
+		}
+
+		if openMetrics {
+			writeMetricHeader(bw, "health_probe_shared_runs", "counter", "Number of requests of the probe served by a run of another request.")
+		} else {
+			writeMetricHeader(bw, "health_probe_shared_runs_total", "counter", "Number of requests of the probe served by a run of another request.")
+		}
+
+		for _, f := range failures {
+			fmt.Fprintf(bw, "health_probe_shared_runs_total{probe=\"%v\"} %v\n", escapeLabel(f.name), f.shared)
+		}
+	}
+
+	if openMetrics {
+		fmt.Fprintln(bw, "# EOF")
+	}
+
+	return bw.Flush()
+}
+
+// Returns a handler serving the results of the readiness probes and the number of their failures in the Prometheus
+// text format. Clients accepting `application/openmetrics-text` get the OpenMetrics format, in which the failure
+// counters carry the trace ID of the latest failure as exemplar, see WithTraceID. Mount it at the path scraped by
+// Prometheus and enable exemplar storage in Prometheus to use them.
+//
+// Example:
+//		http.Handle("/metrics", checker.MetricsHandler())
+func (h *Checker) MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		result := h.readiness(r.Context(), nil)
+
+		failures := make([]probeFailures, 0, len(result.Probes))
+		for _, p := range result.Probes {
+			// A stored result of another process may contain probes not registered here
+			probe, ok := h.readinessProbes[p.Name]
+			if !ok {
+				continue
+			}
+
+			count, last := probe.failureCount()
+			shared := probe.sharedRuns()
+			failures = append(failures, probeFailures{name: p.Name, count: count, last: last, shared: shared})
+		}
+
+		openMetrics := strings.Contains(r.Header.Get("Accept"), "application/openmetrics-text")
+		if openMetrics {
+			w.Header().Set("Content-Type", "application/openmetrics-text; version=1.0.0; charset=utf-8")
+		} else {
+			w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		}
+
+		if err := writeMetrics(w, result, failures, openMetrics); err != nil {
+			h.logger().Printf("failed to write metrics: %v", err)
+		}
+	})
+}
+
+// Returns a listener writing each result to a file read by the textfile collector of the Prometheus node_exporter,
+// e.g. for services on bare-metal hosts without a scrape target. The file is replaced atomically and should be
+// named `*.prom` and placed in the directory given by `--collector.textfile.directory`. Failures are logged.
+//
+// Example:
+//		checker.AddListener(health.PrometheusTextfileListener("/var/lib/node_exporter/textfile/my-service.prom"))
+//		defer checker.EvaluateInBackground(time.Minute)()
+func PrometheusTextfileListener(path string) Listener {
+	mu := sync.Mutex{}
+
+	return func(r Result) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if err := writePrometheusFile(path, r); err != nil {
+			r.log().Printf("failed to write metrics to %v: %v", path, err)
+		}
+	}
+}
+
+// Writes the result to a temporary file next to path and renames it, so the collector never reads a partial file.
+func writePrometheusFile(path string, r Result) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := WritePrometheus(tmp, r); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+
+	if err := tmp.Chmod(0644); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+func writeMetricHeader(w io.Writer, name, kind, help string) {
+	fmt.Fprintf(w, "# HELP %v %v\n# TYPE %v %v\n", name, help, name, kind)
+}
+
+// Formats t as seconds since the epoch.
+func formatTimestamp(t time.Time) string {
+	return strconv.FormatFloat(float64(t.UnixNano())/1e9, 'f', -1, 64)
+}
+
+func boolValue(b bool) int {
+	if b {
+		return 1
+	}
+
+	return 0
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(v string) string {
+	return labelEscaper.Replace(v)
+}
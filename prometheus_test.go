@@ -0,0 +1,83 @@
+package health
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWritePrometheus(t *testing.T) {
+	r := Result{
+		Ready:  true,
+		Status: StatusWarn,
+		Probes: []ProbeResult{
+			{Name: "cache", Status: StatusWarn, Err: fmt.Errorf("connection refused"), Duration: 1500 * time.Millisecond},
+			{Name: `db"main`, Critical: true, Status: StatusPass, Duration: time.Millisecond},
+		},
+		CheckedAt: time.Unix(1614600000, 0),
+	}
+
+	buf := &bytes.Buffer{}
+	err := WritePrometheus(buf, r)
+
+	assert.NoError(t, err)
+	assert.Contains(t, buf.String(), "# TYPE health_ready gauge\nhealth_ready 1\n")
+	assert.Contains(t, buf.String(), "health_status{status=\"warn\"} 1\n")
+	assert.Contains(t, buf.String(), "health_status{status=\"pass\"} 0\n")
+	assert.Contains(t, buf.String(), "health_probe_up{probe=\"cache\",critical=\"false\"} 0\n")
+	assert.Contains(t, buf.String(), "health_probe_up{probe=\"db\\\"main\",critical=\"true\"} 1\n")
+	assert.Contains(t, buf.String(), "health_probe_status{probe=\"cache\",status=\"warn\"} 1\n")
+	assert.Contains(t, buf.String(), "health_probe_duration_seconds{probe=\"cache\"} 1.5\n")
+	assert.Contains(t, buf.String(), "health_check_timestamp_seconds 1614600000\n")
+}
+
+func TestPrometheusTextfileListener(t *testing.T) {
+	dir, err := ioutil.TempDir(os.TempDir(), "textfile")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "service.prom")
+	PrometheusTextfileListener(path)(Result{Ready: false, Status: StatusFail})
+
+	content, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), "health_ready 0\n")
+
+	files, _ := ioutil.ReadDir(dir)
+	assert.Len(t, files, 1)
+}
+
+func TestChecker_MetricsHandler(t *testing.T) {
+	checker := &Checker{}
+	checker.AddReadinessProbe("api", func() error {
+		return WithTraceID(fmt.Errorf("connection refused"), "4bf92f3577b34da6a3ce929d0e0e4736")
+	})
+
+	server := httptest.NewServer(checker.MetricsHandler())
+	defer server.Close()
+
+	_, err := http.Get(server.URL)
+	assert.NoError(t, err)
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	req.Header.Set("Accept", "application/openmetrics-text; version=1.0.0")
+	resp, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, _ := ioutil.ReadAll(resp.Body)
+
+	assert.Equal(t, "application/openmetrics-text; version=1.0.0; charset=utf-8", resp.Header.Get("Content-Type"))
+	assert.Contains(t, string(body), "# TYPE health_probe_failures counter\n")
+	assert.Regexp(t, `health_probe_failures_total\{probe="api"\} 2 # \{trace_id="4bf92f3577b34da6a3ce929d0e0e4736"\} 1 \d+(\.\d+)?\n`, string(body))
+	assert.True(t, strings.HasSuffix(string(body), "# EOF\n"))
+}
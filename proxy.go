@@ -0,0 +1,161 @@
+package health
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"golang.org/x/net/proxy"
+)
+
+// Proxy used by the HTTP and TCP probes for outbound connections, e.g. `socks5://egress:1080` or
+// `http://egress:3128`. If nil, the HTTP probes use the proxy given by the environment (HTTPS_PROXY, HTTP_PROXY and
+// NO_PROXY) and the TCP probes connect directly. Single probes can use another proxy, see HTTPProxy and
+// TCPProxyProbe. Set it before the probes run.
+var DefaultProxy *url.URL
+
+// Returns the proxy of HTTP requests sent via the SharedTransport.
+func defaultProxyOf(r *http.Request) (*url.URL, error) {
+	if DefaultProxy != nil {
+		return DefaultProxy, nil
+	}
+
+	return http.ProxyFromEnvironment(r)
+}
+
+// Sends the request via the given proxy instead of the DefaultProxy. Supports `http`, `https` and `socks5` proxies.
+func HTTPProxy(proxyURL *url.URL) HTTPOption {
+	return func(c *httpProbeConfig) {
+		c.proxy = proxyURL
+	}
+}
+
+// Returns a dial function connecting through the given proxy, e.g. for gRPC connections of a GrpcProbe. Supports
+// `socks5` proxies and `http` proxies supporting CONNECT. Connects directly if proxyURL is nil.
+//
+// Example:
+//		dial := health.ProxyDialer(proxyURL)
+//		conn, err := grpc.Dial("payments.example.com:443", grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+//			return dial(ctx, "tcp", addr)
+//		}))
+func ProxyDialer(proxyURL *url.URL) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	direct := &net.Dialer{}
+	if proxyURL == nil {
+		return direct.DialContext
+	}
+
+	switch proxyURL.Scheme {
+	case "socks5", "socks5h":
+		var auth *proxy.Auth
+		if proxyURL.User != nil {
+			password, _ := proxyURL.User.Password()
+			auth = &proxy.Auth{User: proxyURL.User.Username(), Password: password}
+		}
+
+		dialer, err := proxy.SOCKS5("tcp", proxyURL.Host, auth, direct)
+		if err != nil {
+			return failingDial(err)
+		}
+
+		return dialer.(proxy.ContextDialer).DialContext
+	case "http":
+		return func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return dialConnect(ctx, direct, proxyURL, addr)
+		}
+	default:
+		return failingDial(fmt.Errorf("unsupported proxy scheme %q", proxyURL.Scheme))
+	}
+}
+
+func failingDial(err error) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return nil, err
+	}
+}
+
+// Opens a tunnel to addr via the CONNECT method of a http proxy.
+func dialConnect(ctx context.Context, dialer *net.Dialer, proxyURL *url.URL, addr string) (net.Conn, error) {
+	conn, err := dialer.DialContext(ctx, "tcp", proxyURL.Host)
+	if err != nil {
+		return nil, fmt.Errorf("could not connect to proxy: %w", err)
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: addr},
+		Host:   addr,
+		Header: http.Header{},
+	}
+	if proxyURL.User != nil {
+		password, _ := proxyURL.User.Password()
+		credentials := base64.StdEncoding.EncodeToString([]byte(proxyURL.User.Username() + ":" + password))
+		req.Header.Set("Proxy-Authorization", "Basic "+credentials)
+	}
+
+	if err := req.Write(conn); err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("could not send CONNECT to proxy: %w", err)
+	}
+
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, req)
+	if err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("invalid response of proxy: %w", err)
+	}
+	_ = resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		_ = conn.Close()
+		return nil, fmt.Errorf("proxy refused CONNECT to %v: %v", addr, resp.Status)
+	}
+
+	_ = conn.SetDeadline(time.Time{})
+
+	// The proxy may have sent data of the tunnel along with its response
+	return &bufferedConn{Conn: conn, reader: reader}, nil
+}
+
+// Connection reading the data buffered while reading the response of the proxy first.
+type bufferedConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+func (c *bufferedConn) Read(b []byte) (int, error) {
+	return c.reader.Read(b)
+}
+
+// Checks if a TCP connection to addr can be opened through the given proxy. Connects directly if proxyURL is nil.
+//
+// Example:
+//		proxyURL, _ := url.Parse("socks5://egress:1080")
+//		checker.AddReadinessProbe("partner-sftp", health.TCPProxyProbe("sftp.partner.com:22", proxyURL, 5*time.Second))
+func TCPProxyProbe(addr string, proxyURL *url.URL, timeout time.Duration) Probe {
+	dial := ProxyDialer(proxyURL)
+
+	return func() error {
+		ctx := context.Background()
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+
+		conn, err := dial(ctx, "tcp", addr)
+		if err != nil {
+			return classify(ErrUnreachable, fmt.Errorf("endpoint could not be reached: %w", err))
+		}
+
+		return conn.Close()
+	}
+}
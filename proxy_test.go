@@ -0,0 +1,103 @@
+package health
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Returns a http proxy supporting CONNECT and recording the requested hosts.
+func newTestProxy(t *testing.T) (*url.URL, *[]string) {
+	var hosts []string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hosts = append(hosts, r.Host)
+		if r.Method != http.MethodConnect {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		upstream, err := net.Dial("tcp", r.Host)
+		if err != nil {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		defer upstream.Close()
+
+		w.WriteHeader(http.StatusOK)
+		conn, _, _ := w.(http.Hijacker).Hijack()
+		defer conn.Close()
+
+		go func() { _, _ = io.Copy(upstream, conn) }()
+		_, _ = io.Copy(conn, upstream)
+	}))
+	t.Cleanup(s.Close)
+
+	u, _ := url.Parse(s.URL)
+	return u, &hosts
+}
+
+func TestHTTPProxy(t *testing.T) {
+	proxyURL, hosts := newTestProxy(t)
+
+	assert.NoError(t, HTTPProbe("http://payments.example.com/health", HTTPProxy(proxyURL))())
+	assert.Equal(t, []string{"payments.example.com"}, *hosts)
+}
+
+func TestTCPProxyProbe(t *testing.T) {
+	proxyURL, hosts := newTestProxy(t)
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer l.Close()
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			_ = conn.Close()
+		}
+	}()
+
+	assert.NoError(t, TCPProxyProbe(l.Addr().String(), proxyURL, time.Second)())
+	assert.Equal(t, []string{l.Addr().String()}, *hosts)
+
+	err = TCPProxyProbe("127.0.0.1:1", proxyURL, time.Second)()
+	assert.EqualError(t, err, "endpoint could not be reached: proxy refused CONNECT to 127.0.0.1:1: 502 Bad Gateway")
+
+	unsupported, _ := url.Parse("ftp://proxy:21")
+	assert.Error(t, TCPProxyProbe(l.Addr().String(), unsupported, time.Second)())
+}
+
+func TestDialConnect_bufferedData(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer l.Close()
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		_, _ = http.ReadRequest(bufio.NewReader(conn))
+		// The banner of the upstream arrives with the response of the proxy
+		_, _ = io.WriteString(conn, "HTTP/1.1 200 Connection established\r\n\r\nSSH-2.0-OpenSSH\r\n")
+	}()
+
+	conn, err := dialConnect(context.Background(), &net.Dialer{}, &url.URL{Scheme: "http", Host: l.Addr().String()}, "sftp:22")
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	banner, err := bufio.NewReader(conn).ReadString('\n')
+	assert.NoError(t, err)
+	assert.Equal(t, "SSH-2.0-OpenSSH\r\n", banner)
+}
@@ -0,0 +1,123 @@
+package health
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+const defaultPushTimeout = 10 * time.Second
+
+type pushConfig struct {
+	client   *http.Client
+	grouping map[string]string
+}
+
+// A PushOption configures PushPrometheus.
+type PushOption func(c *pushConfig)
+
+// Sets the client used to push the metrics, e.g. configured for authentication. Defaults to a client with a
+// timeout of 10 seconds.
+func PushClient(client *http.Client) PushOption {
+	return func(c *pushConfig) {
+		c.client = client
+	}
+}
+
+// Adds a grouping label besides the job, e.g. `instance`. Pushes with the same job and grouping labels replace
+// each other.
+func PushGrouping(name, value string) PushOption {
+	return func(c *pushConfig) {
+		c.grouping[name] = value
+	}
+}
+
+// Pushes the result as Prometheus gauges (see WritePrometheus) to a Pushgateway, replacing the metrics previously
+// pushed for the job. Use it in short-lived jobs, which can not be scraped, to leave a record of their dependency
+// health behind on shutdown.
+//
+// Example:
+//		defer func() {
+//			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+//			defer cancel()
+//
+//			if err := health.PushPrometheus(ctx, "http://pushgateway:9091", "nightly-import", checker.Check(ctx)); err != nil {
+//				log.Printf("could not push health: %v", err)
+//			}
+//		}()
+func PushPrometheus(ctx context.Context, gatewayURL, job string, r Result, opts ...PushOption) error {
+	cfg := &pushConfig{grouping: map[string]string{}}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	if cfg.client == nil {
+		cfg.client = &http.Client{Timeout: defaultPushTimeout}
+	}
+
+	var body bytes.Buffer
+	if err := WritePrometheus(&body, r); err != nil {
+		return fmt.Errorf("could not write metrics: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, pushURL(gatewayURL, job, cfg.grouping), &body)
+	if err != nil {
+		return fmt.Errorf("invalid pushgateway url: %w", err)
+	}
+	req.Header.Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+
+	resp, err := cfg.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("could not push metrics: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("pushgateway responded with %v", resp.Status)
+	}
+
+	return nil
+}
+
+// Returns the url of the metrics group, e.g. `http://pushgateway:9091/metrics/job/import/instance/a`.
+func pushURL(gatewayURL, job string, grouping map[string]string) string {
+	var b strings.Builder
+	b.WriteString(strings.TrimSuffix(gatewayURL, "/"))
+	b.WriteString("/metrics")
+	writePushLabel(&b, "job", job)
+
+	names := make([]string, 0, len(grouping))
+	for name := range grouping {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		writePushLabel(&b, name, grouping[name])
+	}
+
+	return b.String()
+}
+
+// Writes a label as path segments. Values containing slashes or being empty are base64 encoded, as required by the
+// Pushgateway.
+func writePushLabel(b *strings.Builder, name, value string) {
+	if value == "" || strings.Contains(value, "/") {
+		b.WriteString("/" + name + "@base64/" + base64.RawURLEncoding.EncodeToString([]byte(value)))
+		if value == "" {
+			b.WriteString("=")
+		}
+		return
+	}
+
+	b.WriteString("/" + name + "/" + url.PathEscape(value))
+}
@@ -0,0 +1,41 @@
+package health
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPushPrometheus(t *testing.T) {
+	var path, method, body string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		path, method, body = r.URL.EscapedPath(), r.Method, string(b)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer s.Close()
+
+	r := Result{Ready: true, Status: StatusPass, Probes: []ProbeResult{{Name: "database", Critical: true, Status: StatusPass}}}
+	assert.NoError(t, PushPrometheus(context.Background(), s.URL+"/", "nightly-import", r, PushGrouping("instance", "host-1")))
+	assert.Equal(t, http.MethodPut, method)
+	assert.Equal(t, "/metrics/job/nightly-import/instance/host-1", path)
+	assert.Contains(t, body, "health_ready 1\n")
+	assert.Contains(t, body, `health_probe_up{probe="database",critical="true"} 1`)
+}
+
+func TestPushPrometheus_err(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer s.Close()
+
+	assert.EqualError(t, PushPrometheus(context.Background(), s.URL, "import", Result{}), "pushgateway responded with 400 Bad Request")
+}
+
+func TestPushURL(t *testing.T) {
+	assert.Equal(t, "http://gw/metrics/job@base64/YS9i/zone@base64/=", pushURL("http://gw", "a/b", map[string]string{"zone": ""}))
+}
@@ -0,0 +1,43 @@
+package health
+
+import "sync"
+
+// Runs tasks one after another in the order they were added, without blocking the caller. Used by listeners whose
+// requests must not overtake each other, e.g. closing an alert before it was created. The zero value is ready to use.
+type orderedQueue struct {
+	mu      sync.Mutex
+	tasks   []func()
+	running bool
+}
+
+// Adds the task to the queue and starts running the queue in background if it is idle.
+func (q *orderedQueue) add(task func()) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.tasks = append(q.tasks, task)
+	if q.running {
+		return
+	}
+
+	q.running = true
+	go q.run()
+}
+
+// Runs the queued tasks until the queue is empty.
+func (q *orderedQueue) run() {
+	for {
+		q.mu.Lock()
+		if len(q.tasks) == 0 {
+			q.running = false
+			q.mu.Unlock()
+			return
+		}
+
+		task := q.tasks[0]
+		q.tasks = q.tasks[1:]
+		q.mu.Unlock()
+
+		task()
+	}
+}
@@ -0,0 +1,47 @@
+package health
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrderedQueue(t *testing.T) {
+	q := &orderedQueue{}
+	release := make(chan struct{})
+	wg := sync.WaitGroup{}
+	var order []int
+
+	wg.Add(3)
+	q.add(func() {
+		defer wg.Done()
+		<-release
+		order = append(order, 1)
+	})
+	q.add(func() {
+		defer wg.Done()
+		order = append(order, 2)
+	})
+
+	// A blocked task does not block the caller
+	added := make(chan struct{})
+	go func() {
+		q.add(func() {
+			defer wg.Done()
+			order = append(order, 3)
+		})
+		close(added)
+	}()
+
+	select {
+	case <-added:
+	case <-time.After(time.Second):
+		t.Fatal("add blocked")
+	}
+
+	close(release)
+	wg.Wait()
+	assert.Equal(t, []int{1, 2, 3}, order)
+}
@@ -0,0 +1,91 @@
+package health
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Combines probes of redundant backends, e.g. the shards of a cache, into a single probe passing if at least
+// minimum of them pass. The probes run concurrently. If some fail but the quorum is reached, the failures are
+// reported as Warning, so the service shows as degraded instead of unready. Below the quorum the probe fails with
+// the errors of the failing backends. Backends reporting a Warning or Standby count towards the quorum.
+//
+// Example:
+//		checker.AddReadinessProbe("redis", health.MinimumReady(2, map[string]health.Probe{
+//			"shard-a": health.TCPProbe("redis-a:6379", time.Second),
+//			"shard-b": health.TCPProbe("redis-b:6379", time.Second),
+//			"shard-c": health.TCPProbe("redis-c:6379", time.Second),
+//		}))
+func MinimumReady(minimum int, probes map[string]Probe) Probe {
+	names := make([]string, 0, len(probes))
+	for name := range probes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return func() error {
+		errs := make([]error, len(names))
+		done := make(chan struct{}, len(names))
+		for i, name := range names {
+			i, probe := i, probes[name]
+			go func() {
+				errs[i] = probe()
+				done <- struct{}{}
+			}()
+		}
+
+		for range names {
+			<-done
+		}
+
+		var messages []string
+		var failed int
+		var firstFailure error
+		for i, err := range errs {
+			if err == nil {
+				continue
+			}
+
+			messages = append(messages, fmt.Sprintf("%v: %v", names[i], err))
+			// Backends with warnings or in standby count towards the quorum
+			if probeStatusOf(err, true) == StatusFail {
+				failed++
+				if firstFailure == nil {
+					firstFailure = err
+				}
+			}
+		}
+
+		passed := len(names) - failed
+		msg := fmt.Sprintf("%v of %v passed, %v required", passed, len(names), minimum)
+		if len(messages) > 0 {
+			msg += ": " + strings.Join(messages, "; ")
+		}
+
+		if passed < minimum {
+			return &quorumError{msg: msg, err: firstFailure}
+		}
+
+		if len(messages) > 0 {
+			return Warning(errors.New(msg))
+		}
+
+		return nil
+	}
+}
+
+// Returned by MinimumReady below the quorum. Wraps the first failure, so the reason is classified by it.
+type quorumError struct {
+	msg string
+	err error
+}
+
+func (e *quorumError) Error() string {
+	return e.msg
+}
+
+func (e *quorumError) Unwrap() error {
+	return e.err
+}
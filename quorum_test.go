@@ -0,0 +1,29 @@
+package health
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMinimumReady(t *testing.T) {
+	pass := func() error { return nil }
+	fail := func() error { return fmt.Errorf("dial tcp: %w", ErrUnreachable) }
+	warn := func() error { return Warning(fmt.Errorf("high latency")) }
+
+	assert.NoError(t, MinimumReady(2, map[string]Probe{"a": pass, "b": pass, "c": pass})())
+
+	err := MinimumReady(2, map[string]Probe{"a": pass, "b": fail, "c": pass})()
+	assert.EqualError(t, err, "2 of 3 passed, 2 required: b: dial tcp: unreachable")
+	assert.Equal(t, StatusWarn, probeStatusOf(err, true))
+
+	err = MinimumReady(2, map[string]Probe{"a": warn, "b": fail, "c": fail})()
+	assert.EqualError(t, err, "1 of 3 passed, 2 required: a: high latency; b: dial tcp: unreachable; c: dial tcp: unreachable")
+	assert.Equal(t, StatusFail, probeStatusOf(err, true))
+	assert.True(t, errors.Is(err, ErrUnreachable))
+	assert.Equal(t, KindUnreachable, reasonKindOf(err))
+
+	assert.EqualError(t, MinimumReady(2, map[string]Probe{"a": pass})(), "1 of 1 passed, 2 required")
+}
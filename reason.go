@@ -0,0 +1,100 @@
+package health
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ReasonKind classifies why a probe did not pass.
+type ReasonKind string
+
+const (
+	// The probe returned an error
+	KindError ReasonKind = "error"
+	// The probe did not finish in time, see ErrTimeout
+	KindTimeout ReasonKind = "timeout"
+	// The service could not be connected to, see ErrUnreachable
+	KindUnreachable ReasonKind = "unreachable"
+	// The service rejected the credentials of the probe, see ErrUnauthorized
+	KindUnauthorized ReasonKind = "unauthorized"
+	// The service reports to be unhealthy, see ErrUnhealthy
+	KindUnhealthy ReasonKind = "unhealthy"
+	// The probe reports a standby instance, see Standby
+	KindStandby ReasonKind = "standby"
+)
+
+// Reason describes a probe that did not pass.
+type Reason struct {
+	// Name the probe was registered with
+	Service string `json:"service"`
+	// Error returned by the probe
+	Error string `json:"error"`
+	// Classification of the error
+	Kind ReasonKind `json:"kind"`
+	// Labels attached to the probe, see Labels
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// Returns the reason as `service: error`.
+func (r Reason) String() string {
+	return fmt.Sprintf("%v: %v", r.Service, r.Error)
+}
+
+// Returned by probes not finishing in time
+type timeoutError struct {
+	timeout time.Duration
+}
+
+func (e *timeoutError) Error() string {
+	return fmt.Sprintf("timed out after %v", e.timeout)
+}
+
+func (e *timeoutError) Timeout() bool {
+	return true
+}
+
+func (e *timeoutError) Is(target error) bool {
+	return target == ErrTimeout
+}
+
+// Returns the reason for the failed probe result.
+func newReason(r ProbeResult) Reason {
+	return Reason{Service: r.Name, Error: r.Err.Error(), Kind: reasonKindOf(r.Err), Labels: r.Labels}
+}
+
+// Classifies err by the sentinel errors it wraps.
+func reasonKindOf(err error) ReasonKind {
+	// Matches context.DeadlineExceeded and net errors as well
+	var timeout interface{ Timeout() bool }
+	if errors.Is(err, ErrTimeout) || errors.As(err, &timeout) && timeout.Timeout() {
+		return KindTimeout
+	}
+
+	var sb *standby
+	switch {
+	case errors.As(err, &sb):
+		return KindStandby
+	case errors.Is(err, ErrUnauthorized):
+		return KindUnauthorized
+	case errors.Is(err, ErrUnreachable):
+		return KindUnreachable
+	case errors.Is(err, ErrUnhealthy):
+		return KindUnhealthy
+	}
+
+	return KindError
+}
+
+func reasonStrings(reasons []Reason) []string {
+	if reasons == nil {
+		return nil
+	}
+
+	s := make([]string, 0, len(reasons))
+	for _, r := range reasons {
+		s = append(s, r.String())
+	}
+
+	return s
+}
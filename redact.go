@@ -0,0 +1,85 @@
+package health
+
+import (
+	"errors"
+	"regexp"
+)
+
+// Replacement of redacted secrets
+const redacted = "[REDACTED]"
+
+// Patterns of secrets always removed from probe errors: credentials in URLs, e.g. `redis://:secret@redis:6379` or
+// `https://token@host`, and key/value pairs in connection strings, e.g. `password=secret`.
+var defaultRedactPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)([a-z][a-z0-9+.-]*://)[^\s/?#]+(@)`),
+	regexp.MustCompile(`(?i)(\b(?:password|passwd|pwd|secret|token|api_?key)=)[^\s;&,"']+()`),
+}
+
+// Error of a probe with secrets removed from its message. Unwraps to the sentinel error classifying the original
+// error only, so the secrets can not be retrieved by errors.As.
+type redactedError struct {
+	msg   string
+	class error
+}
+
+func (e *redactedError) Error() string {
+	return e.msg
+}
+
+func (e *redactedError) Unwrap() error {
+	return e.class
+}
+
+// Returns the sentinel error err is classified as, nil if it is of KindError or KindStandby.
+func classOf(err error) error {
+	switch reasonKindOf(err) {
+	case KindTimeout:
+		return ErrTimeout
+	case KindUnreachable:
+		return ErrUnreachable
+	case KindUnauthorized:
+		return ErrUnauthorized
+	case KindUnhealthy:
+		return ErrUnhealthy
+	default:
+		return nil
+	}
+}
+
+// Removes secrets from the message of err before it is served, logged or passed to listeners. Credentials in URLs
+// and connection strings are always removed, matches of RedactPatterns are replaced.
+func (h *Checker) redact(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	msg := err.Error()
+	for _, re := range defaultRedactPatterns {
+		msg = re.ReplaceAllString(msg, "${1}"+redacted+"${2}")
+	}
+
+	for _, re := range h.RedactPatterns {
+		msg = re.ReplaceAllString(msg, redacted)
+	}
+
+	if msg == err.Error() {
+		return err
+	}
+
+	// Keep the markers and the trace ID of the original error
+	redactedErr := error(&redactedError{msg: msg, class: classOf(err)})
+	var sb *standby
+	var w *warning
+	switch {
+	case errors.As(err, &sb):
+		redactedErr = Standby(redactedErr)
+	case errors.As(err, &w):
+		redactedErr = Warning(redactedErr)
+	}
+
+	if traceID := traceIDOf(err); traceID != "" {
+		redactedErr = WithTraceID(redactedErr, traceID)
+	}
+
+	return redactedErr
+}
@@ -0,0 +1,198 @@
+package health
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const defaultReloadInterval = 5 * time.Second
+
+type reloadConfig struct {
+	background time.Duration
+	onReload   func(h *Checker)
+}
+
+// A ReloadOption configures a Reloader.
+type ReloadOption func(c *reloadConfig)
+
+// Evaluates the readiness probes of each loaded checker in background, see Checker.EvaluateInBackground.
+// The evaluation of the replaced checker is stopped.
+func ReloadEvaluateInBackground(interval time.Duration) ReloadOption {
+	return func(c *reloadConfig) {
+		c.background = interval
+	}
+}
+
+// Calls fn with each loaded checker before it is swapped in, e.g. to add listeners or probes not covered by the
+// config file.
+func ReloadHook(fn func(h *Checker)) ReloadOption {
+	return func(c *reloadConfig) {
+		c.onReload = fn
+	}
+}
+
+// A Reloader serves a Checker created from a config file and replaces it whenever the file changes, so probes,
+// endpoint paths and thresholds can be changed without restarting the health server. An invalid config is logged
+// and the previous checker is kept.
+type Reloader struct {
+	path string
+	cfg  *reloadConfig
+
+	mu       sync.RWMutex
+	checker  *Checker
+	handler  http.Handler
+	stop     func()
+	checksum [sha256.Size]byte
+}
+
+// Creates a Reloader serving the checker described by the config file at path, see LoadConfig.
+// Fails if the initial config is invalid.
+//
+// Example:
+//		reloader, err := health.NewReloader("/etc/app/health.yaml", health.ReloadEvaluateInBackground(10*time.Second))
+//		if err != nil {
+//			log.Fatal(err)
+//		}
+//		defer reloader.Watch(5 * time.Second)()
+//
+//		_ = http.ListenAndServe(":8080", reloader)
+func NewReloader(path string, opts ...ReloadOption) (*Reloader, error) {
+	cfg := &reloadConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	r := &Reloader{path: path, cfg: cfg}
+	if _, err := r.Reload(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// Returns the checker currently served.
+func (r *Reloader) Checker() *Checker {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.checker
+}
+
+// Serves the health endpoints of the current checker.
+func (r *Reloader) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mu.RLock()
+	handler := r.handler
+	r.mu.RUnlock()
+
+	handler.ServeHTTP(w, req)
+}
+
+// Reads the config file and swaps in a new checker if the file changed. Returns whether the checker was replaced.
+// Keeps the current checker if the config is invalid.
+func (r *Reloader) Reload() (bool, error) {
+	// #nosec G304
+	b, err := ioutil.ReadFile(r.path)
+	if err != nil {
+		return false, fmt.Errorf("could not read config: %w", err)
+	}
+
+	checksum := sha256.Sum256(b)
+	r.mu.RLock()
+	unchanged := r.checker != nil && checksum == r.checksum
+	r.mu.RUnlock()
+
+	if unchanged {
+		return false, nil
+	}
+
+	cfg, err := LoadConfig(r.path)
+	if err != nil {
+		return false, err
+	}
+
+	checker, err := NewCheckerFromConfig(cfg)
+	if err != nil {
+		return false, err
+	}
+
+	if r.cfg.onReload != nil {
+		r.cfg.onReload(checker)
+	}
+
+	stop := func() {}
+	if r.cfg.background > 0 {
+		stop = checker.EvaluateInBackground(r.cfg.background)
+	}
+
+	r.mu.Lock()
+	previousStop := r.stop
+	r.checker = checker
+	r.handler = checker.serverMux()
+	r.stop = stop
+	r.checksum = checksum
+	r.mu.Unlock()
+
+	if previousStop != nil {
+		previousStop()
+	}
+
+	return true, nil
+}
+
+// Reloads the config file every interval and whenever the process receives SIGHUP. Defaults to 5 seconds if
+// interval is 0. Returns a function stopping the watch and the background evaluation of the current checker.
+func (r *Reloader) Watch(interval time.Duration) func() {
+	if interval <= 0 {
+		interval = defaultReloadInterval
+	}
+
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+
+	go func() {
+		defer close(stopped)
+
+		for {
+			select {
+			case <-ticker.C:
+			case <-hup:
+			case <-done:
+				return
+			}
+
+			changed, err := r.Reload()
+			if err != nil {
+				r.Checker().logger().Printf("failed to reload health config: %v", err)
+			} else if changed {
+				r.Checker().logger().Printf("reloaded health config from %v", r.path)
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(hup)
+		ticker.Stop()
+		close(done)
+		<-stopped
+
+		r.mu.Lock()
+		stop := r.stop
+		r.stop = nil
+		r.mu.Unlock()
+
+		if stop != nil {
+			stop()
+		}
+	}
+}
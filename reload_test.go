@@ -0,0 +1,80 @@
+package health
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReloader(t *testing.T) {
+	dir, err := ioutil.TempDir("", "health")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "health.yaml")
+	assert.NoError(t, ioutil.WriteFile(path, []byte("readyPath: /readyz\n"), 0600))
+
+	var hooked int
+	reloader, err := NewReloader(path, ReloadHook(func(h *Checker) { hooked++ }))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, hooked)
+
+	server := httptest.NewServer(reloader)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/readyz")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	changed, err := reloader.Reload()
+	assert.NoError(t, err)
+	assert.False(t, changed, "unchanged file")
+
+	assert.NoError(t, ioutil.WriteFile(path, []byte("readyPath: /ready\nprobes:\n  - name: backend\n    uri: tcp://127.0.0.1:1\n"), 0600))
+	changed, err = reloader.Reload()
+	assert.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, 2, hooked)
+
+	resp, err = http.Get(server.URL + "/ready")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+
+	resp, err = http.Get(server.URL + "/readyz")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	current := reloader.Checker()
+	assert.NoError(t, ioutil.WriteFile(path, []byte("probes:\n  - name: backend\n"), 0600))
+	_, err = reloader.Reload()
+	assert.Error(t, err)
+	assert.Same(t, current, reloader.Checker(), "invalid config keeps the checker")
+}
+
+func TestReloader_Watch(t *testing.T) {
+	dir, err := ioutil.TempDir("", "health")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "health.yaml")
+	assert.NoError(t, ioutil.WriteFile(path, []byte("readyPath: /readyz\n"), 0600))
+
+	reloader, err := NewReloader(path, ReloadEvaluateInBackground(time.Hour))
+	assert.NoError(t, err)
+	stop := reloader.Watch(10 * time.Millisecond)
+	defer stop()
+
+	first := reloader.Checker()
+	assert.NoError(t, ioutil.WriteFile(path, []byte("readyPath: /ready\n"), 0600))
+
+	assert.Eventually(t, func() bool {
+		return reloader.Checker() != first
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, "/ready", reloader.Checker().ReadyPath)
+}
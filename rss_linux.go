@@ -0,0 +1,25 @@
+//go:build linux
+// +build linux
+
+package health
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+)
+
+// Returns the resident set size of the current process in bytes.
+func residentSetSize() (uint64, error) {
+	b, err := ioutil.ReadFile("/proc/self/statm")
+	if err != nil {
+		return 0, err
+	}
+
+	var size, resident uint64
+	if _, err := fmt.Sscan(string(b), &size, &resident); err != nil {
+		return 0, fmt.Errorf("could not parse statm: %w", err)
+	}
+
+	return resident * uint64(os.Getpagesize()), nil
+}
@@ -0,0 +1,13 @@
+//go:build !linux
+// +build !linux
+
+package health
+
+import (
+	"fmt"
+	"runtime"
+)
+
+func residentSetSize() (uint64, error) {
+	return 0, fmt.Errorf("resident set size is not supported on %v", runtime.GOOS)
+}
@@ -0,0 +1,105 @@
+package health
+
+import (
+	"errors"
+	"math/rand"
+	"time"
+)
+
+const defaultJitter = 0.1
+
+// Reported for probes with an initial delay until they run the first time
+var errNotRunYet = errors.New("probe did not run yet")
+
+// Runs the probe at most every d while evaluating in background, e.g. to check expensive dependencies like Vault
+// less often than cheap TCP dials. Between the runs the latest result of the probe is reported. The interval is
+// varied randomly by 10%, see Jitter, so instances of a service don't hit a dependency at the same time.
+// Has no effect on probes evaluated on request.
+//
+// Example:
+//		checker.AddReadinessProbe("vault", health.VaultProbe(client), health.Interval(5*time.Minute))
+//		defer checker.EvaluateInBackground(10 * time.Second)()
+func Interval(d time.Duration) ProbeOption {
+	return func(p *registeredProbe) {
+		p.interval = d
+	}
+}
+
+// Delays the first run of a probe with an Interval by d after the background evaluation started. Until then the
+// probe is reported as failing.
+func InitialDelay(d time.Duration) ProbeOption {
+	return func(p *registeredProbe) {
+		p.initialDelay = d
+	}
+}
+
+// Varies the Interval and InitialDelay of the probe randomly by up to the given fraction, e.g. 0.1 for 10%.
+// Defaults to 0.1.
+func Jitter(fraction float64) ProbeOption {
+	return func(p *registeredProbe) {
+		p.jitter = &fraction
+	}
+}
+
+// Returns the latest result if the probe runs at its own interval and is not due at now.
+func (p *registeredProbe) scheduledResult(now time.Time) (ProbeResult, bool) {
+	if p.interval <= 0 {
+		return ProbeResult{}, false
+	}
+
+	p.scheduleMu.Lock()
+	defer p.scheduleMu.Unlock()
+
+	if p.nextRun.IsZero() {
+		if p.initialDelay <= 0 {
+			return ProbeResult{}, false
+		}
+
+		p.nextRun = now.Add(p.jittered(p.initialDelay))
+		critical := p.settings().critical
+		p.scheduled = ProbeResult{
+			Critical:  critical,
+			Err:       errNotRunYet,
+			Status:    probeStatusOf(errNotRunYet, critical),
+			CheckedAt: now,
+		}
+	}
+
+	if now.Before(p.nextRun) {
+		return p.scheduled, true
+	}
+
+	return ProbeResult{}, false
+}
+
+// Keeps the result of a probe running at its own interval and schedules the next run.
+func (p *registeredProbe) schedule(r ProbeResult) {
+	if p.interval <= 0 {
+		return
+	}
+
+	p.scheduleMu.Lock()
+	defer p.scheduleMu.Unlock()
+
+	p.scheduled = r
+	p.nextRun = r.CheckedAt.Add(p.jittered(p.interval))
+}
+
+// Returns d varied randomly by the jitter of the probe.
+func (p *registeredProbe) jittered(d time.Duration) time.Duration {
+	fraction := defaultJitter
+	if p.jitter != nil {
+		fraction = *p.jitter
+	}
+
+	return jitter(d, fraction)
+}
+
+// Returns d varied randomly by up to the given fraction.
+func jitter(d time.Duration, fraction float64) time.Duration {
+	if fraction <= 0 {
+		return d
+	}
+
+	return d + time.Duration((rand.Float64()*2-1)*fraction*float64(d))
+}
@@ -0,0 +1,76 @@
+package health
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInterval(t *testing.T) {
+	var cheap, expensive int32
+	evaluated := make(chan Result, 10)
+
+	checker := &Checker{}
+	checker.AddReadinessProbe("tcp", func() error {
+		atomic.AddInt32(&cheap, 1)
+		return nil
+	})
+	checker.AddReadinessProbe("vault", func() error {
+		atomic.AddInt32(&expensive, 1)
+		return nil
+	}, Interval(time.Hour))
+	checker.AddListener(func(r Result) {
+		select {
+		case evaluated <- r:
+		default:
+		}
+	})
+
+	defer checker.EvaluateInBackground(10 * time.Millisecond)()
+	for i := 0; i < 3; i++ {
+		r := <-evaluated
+		assert.True(t, r.Ready)
+	}
+
+	assert.GreaterOrEqual(t, atomic.LoadInt32(&cheap), int32(3))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&expensive))
+}
+
+func TestInitialDelay(t *testing.T) {
+	evaluated := make(chan Result, 10)
+
+	checker := &Checker{}
+	checker.AddReadinessProbe("vault", func() error {
+		return nil
+	}, Interval(time.Hour), InitialDelay(50*time.Millisecond), Jitter(0))
+	checker.AddListener(func(r Result) {
+		select {
+		case evaluated <- r:
+		default:
+		}
+	})
+
+	defer checker.EvaluateInBackground(10 * time.Millisecond)()
+
+	first := <-evaluated
+	assert.False(t, first.Ready)
+	assert.Equal(t, "vault: probe did not run yet", first.Reasons[0].String())
+
+	for r := range evaluated {
+		if r.Ready {
+			assert.True(t, r.CheckedAt.Sub(first.CheckedAt) >= 50*time.Millisecond)
+			break
+		}
+	}
+}
+
+func TestJitter(t *testing.T) {
+	p := newRegisteredProbe(nil, []ProbeOption{Jitter(0.5)})
+
+	for i := 0; i < 100; i++ {
+		d := p.jittered(time.Minute)
+		assert.True(t, d >= 30*time.Second && d <= 90*time.Second, d)
+	}
+}
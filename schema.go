@@ -0,0 +1,64 @@
+package health
+
+import (
+	"net/http"
+	"strings"
+)
+
+const (
+	schemaPath = "/.well-known/schema.json"
+
+	componentsPrefix = "#/components/schemas/"
+	defsPrefix       = "#/$defs/"
+)
+
+// Returns a JSON Schema (draft 2020-12) of the readiness response. The schemas of the other responses are defined
+// in `$defs`, e.g. `#/$defs/AliveResponse`, so consumers can generate parsers and validate payloads. The schemas are
+// the ones of the OpenAPI document, they change with HealthAPIVersion. See healthtest.AssertValidResponse to check
+// responses against it.
+//
+// Example:
+//		b, _ := json.MarshalIndent(health.ResponseSchema(), "", "  ")
+//		_ = ioutil.WriteFile("health.schema.json", b, 0644)
+func ResponseSchema() map[string]interface{} {
+	return jsonObject{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"$id":     "https://github.com/regiocom/healthchecker/schema/" + HealthAPIVersion + "/ready.json",
+		"title":   "Health API readiness response",
+		"$ref":    defsPrefix + "ReadyResponse",
+		"$defs":   rebaseRefs(responseSchemas()),
+	}
+}
+
+// Replaces references to the OpenAPI components by references to the definitions of the JSON Schema.
+func rebaseRefs(v interface{}) interface{} {
+	switch v := v.(type) {
+	case jsonObject:
+		rebased := make(jsonObject, len(v))
+		for key, value := range v {
+			if s, ok := value.(string); ok && key == "$ref" {
+				value = defsPrefix + strings.TrimPrefix(s, componentsPrefix)
+			}
+
+			rebased[key] = rebaseRefs(value)
+		}
+
+		return rebased
+	case []interface{}:
+		rebased := make([]interface{}, len(v))
+		for i, value := range v {
+			rebased[i] = rebaseRefs(value)
+		}
+
+		return rebased
+	default:
+		return v
+	}
+}
+
+// Serves the JSON Schema of the responses.
+func (h *Checker) schemaHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/schema+json")
+	w.Header().Set("Cache-Control", "no-cache")
+	_ = JSONEncoder{}.Encode(w, ResponseSchema())
+}
@@ -0,0 +1,34 @@
+package health
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChecker_schema(t *testing.T) {
+	server := httptest.NewServer((&Checker{OpenAPI: true}).serverMux())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/.well-known/schema.json")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/schema+json", resp.Header.Get("Content-Type"))
+
+	var schema struct {
+		Ref  string                 `json:"$ref"`
+		Defs map[string]interface{} `json:"$defs"`
+	}
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&schema))
+	assert.Equal(t, "#/$defs/ReadyResponse", schema.Ref)
+
+	b, _ := json.Marshal(ResponseSchema())
+	assert.NotContains(t, string(b), "#/components/")
+	for _, m := range regexp.MustCompile(`#/\$defs/(\w+)`).FindAllStringSubmatch(string(b), -1) {
+		assert.Contains(t, schema.Defs, m[1], "referenced schema is defined")
+	}
+}
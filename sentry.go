@@ -0,0 +1,212 @@
+package health
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+const defaultSentryTimeout = 10 * time.Second
+
+type sentryConfig struct {
+	client      *http.Client
+	environment string
+	release     string
+}
+
+// A SentryOption configures a SentryListener.
+type SentryOption func(c *sentryConfig)
+
+// Sets the http client used to send events. Defaults to a client with a timeout of 10s.
+func SentryClient(client *http.Client) SentryOption {
+	return func(c *sentryConfig) {
+		c.client = client
+	}
+}
+
+// Sets the environment attached to the events, e.g. `production`.
+func SentryEnvironment(environment string) SentryOption {
+	return func(c *sentryConfig) {
+		c.environment = environment
+	}
+}
+
+// Sets the release attached to the events.
+func SentryRelease(release string) SentryOption {
+	return func(c *sentryConfig) {
+		c.release = release
+	}
+}
+
+// Returns a listener capturing a Sentry event when a readiness probe starts failing, so outages of dependencies show
+// up alongside application errors. Only the transition to failing is reported, further failures of the same probe
+// are not captured until it passed again. Events of a probe are grouped, carry the error and the duration of the
+// probe and are sent asynchronously.
+//
+// Example:
+//		listener, err := health.SentryListener(os.Getenv("SENTRY_DSN"), health.SentryEnvironment("production"))
+//		if err != nil {
+//			log.Fatal(err)
+//		}
+//
+//		checker.AddListener(listener)
+func SentryListener(dsn string, opts ...SentryOption) (Listener, error) {
+	cfg := &sentryConfig{
+		client: &http.Client{Timeout: defaultSentryTimeout},
+	}
+
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	endpoint, key, err := parseSentryDSN(dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	serverName, _ := os.Hostname()
+	m := sync.Mutex{}
+	failing := map[string]bool{}
+
+	return func(r Result) {
+		for _, p := range r.Probes {
+			// Planned downtime is not reported
+			if p.Maintenance {
+				continue
+			}
+
+			// Standby instances are not failing
+			failed := p.Err != nil && p.Status != StatusStandby
+
+			m.Lock()
+			transition := failed && !failing[p.Name]
+			failing[p.Name] = failed
+			m.Unlock()
+
+			if !transition {
+				continue
+			}
+
+			level := "error"
+			if p.Status == StatusWarn {
+				level = "warning"
+			}
+
+			tags := map[string]string{}
+			for key, value := range p.Labels {
+				tags[key] = value
+			}
+			tags["probe"] = p.Name
+			tags["critical"] = fmt.Sprint(p.Critical)
+
+			event := map[string]interface{}{
+				"event_id":    newSentryEventID(),
+				"timestamp":   time.Now().UTC().Format(time.RFC3339),
+				"platform":    "go",
+				"logger":      "healthchecker",
+				"level":       level,
+				"server_name": serverName,
+				"message":     fmt.Sprintf("probe %v failed: %v", p.Name, p.Err),
+				"fingerprint": []string{"healthchecker", p.Name},
+				"tags":        tags,
+				"extra": map[string]string{
+					"error":    p.Err.Error(),
+					"duration": p.Duration.String(),
+				},
+			}
+
+			if cfg.environment != "" {
+				event["environment"] = cfg.environment
+			}
+
+			if cfg.release != "" {
+				event["release"] = cfg.release
+			}
+
+			go func() {
+				if err := cfg.send(endpoint, key, event); err != nil {
+					r.log().Printf("failed to send sentry event: %v", err)
+				}
+			}()
+		}
+	}, nil
+}
+
+// Returns the envelope endpoint and the public key of a DSN like `https://<key>@<host>/<project>`.
+func parseSentryDSN(dsn string) (string, string, error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid sentry dsn: %w", err)
+	}
+
+	if u.User == nil || u.User.Username() == "" {
+		return "", "", fmt.Errorf("invalid sentry dsn: public key is missing")
+	}
+
+	dir, project := path.Split(strings.TrimSuffix(u.Path, "/"))
+	if project == "" {
+		return "", "", fmt.Errorf("invalid sentry dsn: project id is missing")
+	}
+
+	endpoint := url.URL{Scheme: u.Scheme, Host: u.Host, Path: path.Join(dir, "api", project, "envelope") + "/"}
+
+	return endpoint.String(), u.User.Username(), nil
+}
+
+// Sends the event as envelope to sentry.
+func (c *sentryConfig) send(endpoint, key string, event map[string]interface{}) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	header, _ := json.Marshal(map[string]interface{}{
+		"event_id": event["event_id"],
+		"sent_at":  time.Now().UTC().Format(time.RFC3339),
+	})
+	itemHeader, _ := json.Marshal(map[string]interface{}{
+		"type":   "event",
+		"length": len(payload),
+	})
+
+	body := bytes.Join([][]byte{header, itemHeader, payload}, []byte("\n"))
+	req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/x-sentry-envelope")
+	req.Header.Set("X-Sentry-Auth", "Sentry sentry_version=7, sentry_client=healthchecker/1.0, sentry_key="+key)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+func newSentryEventID() string {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+
+	return hex.EncodeToString(id)
+}
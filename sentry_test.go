@@ -0,0 +1,75 @@
+package health
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSentryListener(t *testing.T) {
+	events := make(chan map[string]interface{}, 10)
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/42/envelope/", r.URL.Path)
+		assert.Contains(t, r.Header.Get("X-Sentry-Auth"), "sentry_key=public")
+
+		body, _ := ioutil.ReadAll(r.Body)
+		lines := bytes.Split(body, []byte("\n"))
+		event := map[string]interface{}{}
+		_ = json.Unmarshal(lines[len(lines)-1], &event)
+		events <- event
+	}))
+	defer s.Close()
+
+	listener, err := SentryListener(strings.Replace(s.URL, "http://", "http://public@", 1) + "/42")
+	assert.NoError(t, err)
+
+	failing := Result{Probes: []ProbeResult{{Name: "redis", Critical: true, Err: fmt.Errorf("connection refused")}}}
+	listener(failing)
+	listener(failing)
+
+	select {
+	case event := <-events:
+		assert.Equal(t, "probe redis failed: connection refused", event["message"])
+		assert.Equal(t, "error", event["level"])
+	case <-time.After(time.Second):
+		t.Fatal("no event received")
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	assert.Len(t, events, 0, "failures of a failing probe must not be captured again")
+
+	listener(Result{Ready: true, Probes: []ProbeResult{{Name: "redis", Critical: true}}})
+	listener(failing)
+
+	select {
+	case <-events:
+	case <-time.After(time.Second):
+		t.Fatal("no event received after recovery")
+	}
+
+	listener(Result{Probes: []ProbeResult{{Name: "vault", Maintenance: true, Status: StatusWarn, Err: fmt.Errorf("sealed")}}})
+	time.Sleep(50 * time.Millisecond)
+	assert.Len(t, events, 0, "probes in maintenance must not be captured")
+}
+
+func TestParseSentryDSN(t *testing.T) {
+	endpoint, key, err := parseSentryDSN("https://abc@o1.ingest.sentry.io/prefix/1234")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "https://o1.ingest.sentry.io/prefix/api/1234/envelope/", endpoint)
+	assert.Equal(t, "abc", key)
+}
+
+func TestParseSentryDSN_err_missingKey(t *testing.T) {
+	_, _, err := parseSentryDSN("https://o1.ingest.sentry.io/1234")
+
+	assert.Error(t, err)
+}
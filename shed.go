@@ -0,0 +1,42 @@
+package health
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const defaultRetryAfter = 5 * time.Second
+
+// Returns a handler rejecting requests with `503 Service Unavailable` and a Retry-After header while the latest
+// evaluation of the readiness probes reports the service as unready, so services behind L4 load balancers, which
+// don't query the readiness endpoint, shed traffic consistently with their health. Requests to the health endpoints
+// are never rejected, e.g. if next serves them by AppendHealthEndpoints. Requests pass until the probes were
+// evaluated, use it together with EvaluateInBackground. Retry-After defaults to 5 seconds if retryAfter is 0.
+//
+// Example:
+//		defer checker.EvaluateInBackground(5 * time.Second)()
+//		_ = http.ListenAndServe(":8080", checker.Middleware(checker.ShedLoad(api, 10*time.Second)))
+func (h *Checker) ShedLoad(next http.Handler, retryAfter time.Duration) http.Handler {
+	if retryAfter <= 0 {
+		retryAfter = defaultRetryAfter
+	}
+	retryAfterSeconds := strconv.Itoa(int(math.Ceil(retryAfter.Seconds())))
+
+	m := h.serverMux()
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.mu.Lock()
+		last := h.lastResult
+		h.mu.Unlock()
+
+		if _, pattern := m.Handler(r); pattern != "" || last == nil || last.Ready {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Set("Retry-After", retryAfterSeconds)
+		http.Error(w, "service is not ready", http.StatusServiceUnavailable)
+	})
+}
@@ -0,0 +1,45 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChecker_ShedLoad(t *testing.T) {
+	var err error
+	checker := &Checker{}
+	checker.AddReadinessProbe("database", func() error { return err })
+
+	api := http.NewServeMux()
+	api.HandleFunc("/orders", func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+	checker.AppendHealthEndpoints(api)
+	handler := checker.ShedLoad(api, 1500*time.Millisecond)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	// Not evaluated yet
+	assert.Equal(t, http.StatusOK, get("/orders").Code)
+
+	err = errors.New("connection refused")
+	checker.evaluateReadiness(context.Background(), true)
+
+	rec := get("/orders")
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Equal(t, "2", rec.Header().Get("Retry-After"))
+	assert.Equal(t, http.StatusServiceUnavailable, get("/.well-known/ready").Code)
+	assert.Equal(t, http.StatusOK, get("/.well-known/alive").Code)
+
+	err = nil
+	checker.evaluateReadiness(context.Background(), true)
+	assert.Equal(t, http.StatusOK, get("/orders").Code)
+}
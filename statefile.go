@@ -0,0 +1,126 @@
+package health
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// StateFile is the state of the service mirrored into a file by StateFileListener.
+type StateFile struct {
+	Ready     bool      `json:"ready"`
+	Status    Status    `json:"status"`
+	Reasons   []Reason  `json:"reasons,omitempty"`
+	CheckedAt time.Time `json:"checkedAt"`
+	// Time the file was written, used to detect a hanging or dead process
+	WrittenAt time.Time `json:"writtenAt"`
+}
+
+// Returns a listener writing the state of the service to the file at path after each evaluation of the readiness
+// probes, so sidecars, exec probes and node agents can read the readiness without a network call. The file is
+// replaced atomically, readers never see a partial write. Failures are logged. Read it by StateFileProbe or
+// ReadStateFile, or by `healthcheck -probe app=statefile:///path?max_age=30s`.
+//
+// Example:
+//		checker.AddListener(health.StateFileListener("/run/health/state.json"))
+//		defer checker.EvaluateInBackground(10 * time.Second)()
+func StateFileListener(path string) Listener {
+	return func(r Result) {
+		state := StateFile{
+			Ready:     r.Ready,
+			Status:    r.Status,
+			Reasons:   r.Reasons,
+			CheckedAt: r.CheckedAt,
+			WrittenAt: time.Now(),
+		}
+
+		if err := writeStateFile(path, &state); err != nil {
+			r.log().Printf("failed to write state file %v: %v", path, err)
+		}
+	}
+}
+
+// Writes the state to a temporary file in the same directory and renames it to path.
+func writeStateFile(path string, state *StateFile) error {
+	b, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	f, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(b); err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	if err := f.Chmod(0644); err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), path)
+}
+
+// Reads a file written by StateFileListener. Fails if the file is missing or invalid, or if it was written more than
+// maxAge ago, as the process writing it hangs or died. A maxAge of 0 accepts files of any age.
+func ReadStateFile(path string, maxAge time.Duration) (*StateFile, error) {
+	// #nosec G304
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read state file: %w", err)
+	}
+
+	state := &StateFile{}
+	if err := json.Unmarshal(b, state); err != nil {
+		return nil, fmt.Errorf("invalid state file: %w", err)
+	}
+
+	if age := time.Since(state.WrittenAt); maxAge > 0 && age > maxAge {
+		return nil, fmt.Errorf("state file is stale, written %v ago", age.Truncate(time.Second))
+	}
+
+	return state, nil
+}
+
+// Checks the service writing the file at path by StateFileListener is ready. Fails as well if the file is missing or
+// was written more than maxAge ago.
+//
+// Example:
+//		checker.AddReadinessProbe("app", health.StateFileProbe("/run/health/state.json", 30*time.Second))
+func StateFileProbe(path string, maxAge time.Duration) Probe {
+	return func() error {
+		state, err := ReadStateFile(path, maxAge)
+		if err != nil {
+			return classify(ErrUnreachable, err)
+		}
+
+		if !state.Ready {
+			return classify(ErrUnhealthy, fmt.Errorf("service is not ready: %v", strings.Join(reasonStrings(state.Reasons), "; ")))
+		}
+
+		return nil
+	}
+}
+
+func stateFileProbeFactory(u *url.URL) (Probe, error) {
+	maxAge, err := queryDuration(u, "max_age", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return StateFileProbe(u.Path, maxAge), nil
+}
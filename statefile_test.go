@@ -0,0 +1,75 @@
+package health
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStateFileListener(t *testing.T) {
+	dir, err := ioutil.TempDir("", "state")
+	assert.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+	path := filepath.Join(dir, "state.json")
+
+	var probeErr error
+	checker := &Checker{}
+	checker.AddReadinessProbe("database", func() error { return probeErr })
+	checker.AddListener(StateFileListener(path))
+
+	checker.evaluateReadiness(context.Background(), true)
+	state, err := ReadStateFile(path, time.Minute)
+	assert.NoError(t, err)
+	assert.True(t, state.Ready)
+	assert.Equal(t, StatusPass, state.Status)
+	assert.WithinDuration(t, time.Now(), state.WrittenAt, time.Minute)
+	assert.NoError(t, StateFileProbe(path, time.Minute)())
+
+	probeErr = errors.New("connection refused")
+	checker.evaluateReadiness(context.Background(), true)
+	err = StateFileProbe(path, time.Minute)()
+	assert.EqualError(t, err, "service is not ready: database: connection refused")
+	assert.True(t, errors.Is(err, ErrUnhealthy))
+
+	// Only the state file is left, temporary files are removed
+	files, err := ioutil.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Len(t, files, 1)
+}
+
+func TestStateFileProbe_stale(t *testing.T) {
+	dir, err := ioutil.TempDir("", "state")
+	assert.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+	path := filepath.Join(dir, "state.json")
+
+	assert.NoError(t, writeStateFile(path, &StateFile{Ready: true, WrittenAt: time.Now().Add(-time.Hour)}))
+
+	err = StateFileProbe(path, time.Minute)()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "state file is stale, written 1h0m")
+	assert.True(t, errors.Is(err, ErrUnreachable))
+
+	assert.NoError(t, StateFileProbe(path, 0)())
+
+	probe, err := ProbeFromURI("statefile://" + filepath.ToSlash(filepath.Join(dir, "missing.json")) + "?max_age=30s")
+	assert.NoError(t, err)
+	assert.True(t, errors.Is(probe(), ErrUnreachable))
+}
+
+func TestStateFileListener_logger(t *testing.T) {
+	logs := &bytes.Buffer{}
+	checker := &Checker{Logger: log.New(logs, "", 0)}
+	checker.AddListener(StateFileListener(filepath.Join(os.DevNull, "state.json")))
+
+	checker.evaluateReadiness(context.Background(), true)
+	assert.Contains(t, logs.String(), "failed to write state file")
+}
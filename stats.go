@@ -0,0 +1,192 @@
+package health
+
+import (
+	"net/http"
+	"time"
+)
+
+const defaultStatsPath = "/.well-known/stats"
+
+// Availability counts the evaluations within a rolling window and how many of them passed.
+type Availability struct {
+	Total  uint64
+	Passed uint64
+}
+
+// Returns the share of passing evaluations in percent, or false if there were none.
+func (a Availability) Percent() (float64, bool) {
+	if a.Total == 0 {
+		return 0, false
+	}
+
+	return 100 * float64(a.Passed) / float64(a.Total), true
+}
+
+// AvailabilityStats contains the availability within the last hour, day and week.
+type AvailabilityStats struct {
+	Hour Availability
+	Day  Availability
+	Week Availability
+}
+
+// Stats contains the availability of the service, i.e. its readiness, and of each probe by name. See Checker.Stats.
+type Stats struct {
+	Service   AvailabilityStats
+	Liveness  map[string]AvailabilityStats
+	Readiness map[string]AvailabilityStats
+}
+
+// StatsResponse is the response of the stats endpoint. Availabilities are given in percent and omitted for windows
+// without evaluations.
+type StatsResponse struct {
+	Service   StatsEntry            `json:"service"`
+	Liveness  map[string]StatsEntry `json:"liveness"`
+	Readiness map[string]StatsEntry `json:"readiness"`
+}
+
+// StatsEntry is the availability of the service or a probe in StatsResponse.
+type StatsEntry struct {
+	Hour *float64 `json:"1h,omitempty"`
+	Day  *float64 `json:"24h,omitempty"`
+	Week *float64 `json:"7d,omitempty"`
+}
+
+// Returns the availability of the service and its probes within the last hour, day and week, e.g. as a lightweight
+// uptime report of each dependency. A probe counts as available if it passed or only warned, the service if it was
+// ready. Only evaluations running the probes count, so evaluate in background for meaningful numbers. The windows
+// of a day and a week are rounded to full hours, statistics are kept in memory and reset on restart.
+//
+// Example:
+//		for name, stats := range checker.Stats().Readiness {
+//			if percent, ok := stats.Day.Percent(); ok {
+//				log.Printf("%v: %.2f%%", name, percent)
+//			}
+//		}
+func (h *Checker) Stats() Stats {
+	now := h.clock().Now()
+
+	h.mu.Lock()
+	service := h.stats.window(now)
+	h.mu.Unlock()
+
+	return Stats{
+		Service:   service,
+		Liveness:  probeStats(h.livenessProbes, now),
+		Readiness: probeStats(h.readinessProbes, now),
+	}
+}
+
+func probeStats(probes map[string]*registeredProbe, now time.Time) map[string]AvailabilityStats {
+	stats := make(map[string]AvailabilityStats, len(probes))
+	for name, probe := range probes {
+		probe.statsMu.Lock()
+		stats[name] = probe.stats.window(now)
+		probe.statsMu.Unlock()
+	}
+
+	return stats
+}
+
+// Counts the result of a probe that was run.
+func (p *registeredProbe) countAvailability(r ProbeResult) {
+	p.statsMu.Lock()
+	defer p.statsMu.Unlock()
+
+	p.stats.add(r.CheckedAt, probeStatusOf(r.Err, true) != StatusFail)
+}
+
+// Counts evaluations in buckets of a minute for the last hour and of an hour for the last week.
+type availabilityCounter struct {
+	minutes [60]availabilityBucket
+	hours   [7 * 24]availabilityBucket
+}
+
+type availabilityBucket struct {
+	start time.Time
+	Availability
+}
+
+func (c *availabilityCounter) add(t time.Time, passed bool) {
+	for _, b := range []*availabilityBucket{
+		bucketOf(c.minutes[:], t, time.Minute),
+		bucketOf(c.hours[:], t, time.Hour),
+	} {
+		b.Total++
+		if passed {
+			b.Passed++
+		}
+	}
+}
+
+// Returns the bucket of t, reset if it was used by an earlier period.
+func bucketOf(buckets []availabilityBucket, t time.Time, size time.Duration) *availabilityBucket {
+	start := t.Truncate(size)
+	b := &buckets[int(start.Unix()/int64(size/time.Second))%len(buckets)]
+	if !b.start.Equal(start) {
+		*b = availabilityBucket{start: start}
+	}
+
+	return b
+}
+
+func (c *availabilityCounter) window(now time.Time) AvailabilityStats {
+	return AvailabilityStats{
+		Hour: sumBuckets(c.minutes[:], now.Truncate(time.Minute).Add(-time.Hour)),
+		Day:  sumBuckets(c.hours[:], now.Truncate(time.Hour).Add(-24*time.Hour)),
+		Week: sumBuckets(c.hours[:], now.Truncate(time.Hour).Add(-7*24*time.Hour)),
+	}
+}
+
+// Sums the buckets starting after since.
+func sumBuckets(buckets []availabilityBucket, since time.Time) Availability {
+	var a Availability
+	for _, b := range buckets {
+		if b.start.After(since) {
+			a.Total += b.Total
+			a.Passed += b.Passed
+		}
+	}
+
+	return a
+}
+
+func newStatsResponse(stats Stats) *StatsResponse {
+	return &StatsResponse{
+		Service:   newStatsEntry(stats.Service),
+		Liveness:  newStatsEntries(stats.Liveness),
+		Readiness: newStatsEntries(stats.Readiness),
+	}
+}
+
+func newStatsEntries(stats map[string]AvailabilityStats) map[string]StatsEntry {
+	entries := make(map[string]StatsEntry, len(stats))
+	for name, s := range stats {
+		entries[name] = newStatsEntry(s)
+	}
+
+	return entries
+}
+
+func newStatsEntry(s AvailabilityStats) StatsEntry {
+	percent := func(a Availability) *float64 {
+		if p, ok := a.Percent(); ok {
+			return &p
+		}
+
+		return nil
+	}
+
+	return StatsEntry{Hour: percent(s.Hour), Day: percent(s.Day), Week: percent(s.Week)}
+}
+
+func (h *Checker) statsPath() string {
+	if h.StatsPath == "" {
+		return defaultStatsPath
+	}
+
+	return h.StatsPath
+}
+
+func (h *Checker) statsHandler(w http.ResponseWriter, r *http.Request) {
+	h.writeResponse(w, r, true, newStatsResponse(h.Stats()))
+}
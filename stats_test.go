@@ -0,0 +1,75 @@
+package health
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChecker_Stats(t *testing.T) {
+	var err error
+	checker := &Checker{}
+	checker.AddReadinessProbe("database", func() error { return err })
+	checker.AddReadinessProbe("cache", func() error { return errors.New("down") }, NonCritical())
+
+	checker.evaluateReadiness(context.Background(), true)
+	err = errors.New("down")
+	checker.evaluateReadiness(context.Background(), true)
+	checker.evaluateReadiness(context.Background(), true)
+	err = nil
+	checker.evaluateReadiness(context.Background(), true)
+
+	stats := checker.Stats()
+	assert.Equal(t, Availability{Total: 4, Passed: 2}, stats.Service.Hour)
+	assert.Equal(t, Availability{Total: 4, Passed: 2}, stats.Readiness["database"].Week)
+	assert.Equal(t, Availability{Total: 4}, stats.Readiness["cache"].Day)
+
+	rec := httptest.NewRecorder()
+	checker.serverMux().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/.well-known/stats", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var resp StatsResponse
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, 50.0, *resp.Service.Day)
+	assert.Equal(t, 0.0, *resp.Readiness["cache"].Hour)
+}
+
+func TestAvailabilityCounter(t *testing.T) {
+	start := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	var c availabilityCounter
+	c.add(start, true)
+	c.add(start.Add(30*time.Minute), false)
+	c.add(start.Add(2*time.Hour), true)
+
+	stats := c.window(start.Add(2 * time.Hour))
+	assert.Equal(t, Availability{Total: 1, Passed: 1}, stats.Hour)
+	assert.Equal(t, Availability{Total: 3, Passed: 2}, stats.Day)
+
+	stats = c.window(start.Add(8 * 24 * time.Hour))
+	assert.Equal(t, Availability{}, stats.Week)
+	_, ok := stats.Week.Percent()
+	assert.False(t, ok)
+
+	// Buckets of an earlier period are reset
+	c.add(start.Add(7*24*time.Hour), false)
+	stats = c.window(start.Add(7 * 24 * time.Hour))
+	assert.Equal(t, Availability{Total: 2, Passed: 1}, stats.Week)
+}
+
+func TestTextEncoder_stats(t *testing.T) {
+	hour := 99.5
+	var b bytes.Buffer
+	assert.NoError(t, TextEncoder{}.Encode(&b, &StatsResponse{
+		Service:   StatsEntry{Hour: &hour},
+		Readiness: map[string]StatsEntry{"database": {}},
+	}))
+	assert.Equal(t, "service - 99.50% - -\nreadiness database - - -\n", b.String())
+}
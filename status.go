@@ -0,0 +1,119 @@
+package health
+
+import "errors"
+
+// Status of a service or a single probe.
+type Status string
+
+const (
+	// All probes passed
+	StatusPass Status = "pass"
+	// The service works but is degraded, e.g. a non-critical probe failed. Still reported as ready.
+	StatusWarn Status = "warn"
+	// A critical probe failed
+	StatusFail Status = "fail"
+	// A critical probe failed during the grace period after the start of the service, see Checker.GracePeriod
+	StatusStarting Status = "starting"
+	// The service is ready, but an instance waiting to take over, e.g. a follower of a leader election. See Standby
+	StatusStandby Status = "standby"
+)
+
+type warning struct {
+	err error
+}
+
+func (w *warning) Error() string {
+	return w.err.Error()
+}
+
+func (w *warning) Unwrap() error {
+	return w.err
+}
+
+// Marks the error of a probe as warning. The probe is reported with StatusWarn, which does not change the readiness
+// of the service, but shows it as degraded.
+//
+// Example:
+//		checker.AddReadinessProbe("replication", func() error {
+//			if lag > time.Minute {
+//				return health.Warning(fmt.Errorf("replica lag is %v", lag))
+//			}
+//			return nil
+//		})
+func Warning(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &warning{err: err}
+}
+
+type standby struct {
+	err error
+}
+
+func (s *standby) Error() string {
+	return s.err.Error()
+}
+
+func (s *standby) Unwrap() error {
+	return s.err
+}
+
+// Marks the error of a probe as standby. The probe is reported with StatusStandby, which does not change the
+// readiness of the service, but shows it is not the active instance, e.g. while following a leader.
+//
+// Example:
+//		checker.AddReadinessProbe("leader", func() error {
+//			if !elector.IsLeader() {
+//				return health.Standby(fmt.Errorf("following %v", elector.Leader()))
+//			}
+//			return nil
+//		})
+func Standby(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &standby{err: err}
+}
+
+// Returns the status of a probe that returned err.
+func probeStatusOf(err error, critical bool) Status {
+	if err == nil {
+		return StatusPass
+	}
+
+	var sb *standby
+	if errors.As(err, &sb) {
+		return StatusStandby
+	}
+
+	var w *warning
+	if !critical || errors.As(err, &w) {
+		return StatusWarn
+	}
+
+	return StatusFail
+}
+
+// Returns the worse of both states.
+func (s Status) worse(o Status) Status {
+	if s == StatusFail || o == StatusFail {
+		return StatusFail
+	}
+
+	if s == StatusStarting || o == StatusStarting {
+		return StatusStarting
+	}
+
+	if s == StatusWarn || o == StatusWarn {
+		return StatusWarn
+	}
+
+	if s == StatusStandby || o == StatusStandby {
+		return StatusStandby
+	}
+
+	return StatusPass
+}
@@ -0,0 +1,68 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWarning(t *testing.T) {
+	err := fmt.Errorf("replica lag is 2m")
+
+	assert.Nil(t, Warning(nil))
+	assert.EqualError(t, Warning(err), "replica lag is 2m")
+	assert.True(t, errors.Is(Warning(err), err))
+}
+
+func TestProbeStatusOf(t *testing.T) {
+	err := fmt.Errorf("unhealthy")
+
+	assert.Equal(t, StatusPass, probeStatusOf(nil, true))
+	assert.Equal(t, StatusFail, probeStatusOf(err, true))
+	assert.Equal(t, StatusWarn, probeStatusOf(err, false))
+	assert.Equal(t, StatusWarn, probeStatusOf(Warning(err), true))
+	assert.Equal(t, StatusStandby, probeStatusOf(Standby(err), true))
+}
+
+func TestChecker_ready_standby(t *testing.T) {
+	checker := &Checker{}
+	checker.AddReadinessProbe("database", func() error { return nil })
+	checker.AddReadinessProbe("leader", func() error {
+		return Standby(fmt.Errorf("following pod-a"))
+	})
+
+	r := checker.readiness(context.Background(), nil)
+	assert.True(t, r.Ready)
+	assert.Equal(t, StatusStandby, r.Status)
+	assert.Equal(t, []Reason{{Service: "leader", Error: "following pod-a", Kind: KindStandby}}, r.Reasons)
+
+	checker.AddReadinessProbe("replication", func() error { return Warning(fmt.Errorf("replica lag is 2m")) })
+	assert.Equal(t, StatusWarn, checker.readiness(context.Background(), nil).Status)
+}
+
+func TestChecker_ready_warn(t *testing.T) {
+	checker := &Checker{}
+	checker.AddReadinessProbe("database", func() error { return nil })
+	checker.AddReadinessProbe("replication", func() error {
+		return Warning(fmt.Errorf("replica lag is 2m"))
+	})
+
+	server := httptest.NewServer(checker.serverMux())
+	defer server.Close()
+
+	resp, err := http.DefaultClient.Do(detailedRequest(fmt.Sprintf("%v/.well-known/ready", server.URL)))
+	assert.NoError(t, err)
+	assert.EqualValues(t, http.StatusOK, resp.StatusCode)
+
+	var ready ReadyResponse
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&ready))
+	assert.True(t, ready.Ready)
+	assert.Equal(t, StatusWarn, ready.Status)
+	assert.Equal(t, []Reason{{Service: "replication", Error: "replica lag is 2m", Kind: KindError}}, ready.Reasons)
+}
@@ -0,0 +1,231 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// A ResultStore keeps the latest result of the readiness probes, so processes not evaluating the probes themselves
+// can serve it, see Checker.ResultStore. Only the result is shared: the history and the failure counters stay per
+// process, as they count the runs of the probes in the process serving them and a shared counter replaced by each
+// evaluating process would not be monotonic.
+type ResultStore interface {
+	// Stores the result of an evaluation of the readiness probes
+	Save(ctx context.Context, r Result) error
+	// Returns the latest result, nil if there is none
+	Load(ctx context.Context) (*Result, error)
+}
+
+// MemoryResultStore keeps the latest result in memory, e.g. to share it between checkers of the same process. Its
+// zero value is ready to use.
+type MemoryResultStore struct {
+	mu   sync.Mutex
+	last *Result
+}
+
+func (s *MemoryResultStore) Save(_ context.Context, r Result) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.last = &r
+	return nil
+}
+
+func (s *MemoryResultStore) Load(context.Context) (*Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.last, nil
+}
+
+type redisResultStore struct {
+	pool *redis.Pool
+	key  string
+	ttl  time.Duration
+}
+
+// Returns a store keeping the latest result as JSON at the given redis key, so multi-process deployments like
+// forked workers share one snapshot of the health evaluated by a single process. The key expires after ttl, so the
+// other processes evaluate the probes themselves once the evaluating process stopped. Panics if ttl is not positive,
+// as the first result would be served forever otherwise.
+// Errors of the probes are restored with their message and kind only.
+//
+// Example:
+//		checker.ResultStore = health.RedisResultStore(pool, "my-service:health", time.Minute)
+//		if worker == 0 {
+//			defer checker.EvaluateInBackground(10 * time.Second)()
+//		}
+func RedisResultStore(pool *redis.Pool, key string, ttl time.Duration) ResultStore {
+	if ttl <= 0 {
+		panic("a result store should expire its results")
+	}
+
+	return &redisResultStore{pool: pool, key: key, ttl: ttl}
+}
+
+func (s *redisResultStore) Save(ctx context.Context, r Result) error {
+	b, err := json.Marshal(newStoredResult(r))
+	if err != nil {
+		return err
+	}
+
+	conn, err := s.pool.GetContext(ctx)
+	if err != nil {
+		return fmt.Errorf("could not connect to redis: %w", err)
+	}
+	defer conn.Close()
+
+	_, err = conn.Do("SET", s.key, b, "PX", s.ttl.Milliseconds())
+	return err
+}
+
+func (s *redisResultStore) Load(ctx context.Context) (*Result, error) {
+	conn, err := s.pool.GetContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not connect to redis: %w", err)
+	}
+	defer conn.Close()
+
+	b, err := redis.Bytes(conn.Do("GET", s.key))
+	if errors.Is(err, redis.ErrNil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var stored storedResult
+	if err := json.Unmarshal(b, &stored); err != nil {
+		return nil, fmt.Errorf("invalid stored result: %w", err)
+	}
+
+	r := stored.result()
+	return &r, nil
+}
+
+// Serializable form of a Result
+type storedResult struct {
+	Ready     bool          `json:"ready"`
+	Status    Status        `json:"status"`
+	Reasons   []Reason      `json:"reasons,omitempty"`
+	Probes    []storedProbe `json:"probes"`
+	CheckedAt time.Time     `json:"checkedAt"`
+	Duration  time.Duration `json:"duration"`
+}
+
+type storedProbe struct {
+	Name         string            `json:"name"`
+	Critical     bool              `json:"critical"`
+	Error        string            `json:"error,omitempty"`
+	Kind         ReasonKind        `json:"kind,omitempty"`
+	Status       Status            `json:"status"`
+	CheckedAt    time.Time         `json:"checkedAt"`
+	Duration     time.Duration     `json:"duration"`
+	Flapping     bool              `json:"flapping,omitempty"`
+	Disabled     bool              `json:"disabled,omitempty"`
+	Maintenance  bool              `json:"maintenance,omitempty"`
+	Labels       map[string]string `json:"labels,omitempty"`
+	Failures     uint64            `json:"failures,omitempty"`
+	LastError    string            `json:"lastError,omitempty"`
+	LastKind     ReasonKind        `json:"lastKind,omitempty"`
+	LastFailedAt time.Time         `json:"lastFailedAt"`
+}
+
+func newStoredResult(r Result) *storedResult {
+	stored := &storedResult{
+		Ready:     r.Ready,
+		Status:    r.Status,
+		Reasons:   r.Reasons,
+		Probes:    make([]storedProbe, 0, len(r.Probes)),
+		CheckedAt: r.CheckedAt,
+		Duration:  r.Duration,
+	}
+
+	for _, p := range r.Probes {
+		probe := storedProbe{
+			Name:         p.Name,
+			Critical:     p.Critical,
+			Status:       p.Status,
+			CheckedAt:    p.CheckedAt,
+			Duration:     p.Duration,
+			Flapping:     p.Flapping,
+			Disabled:     p.Disabled,
+			Maintenance:  p.Maintenance,
+			Labels:       p.Labels,
+			Failures:     p.Failures,
+			LastFailedAt: p.LastFailedAt,
+		}
+		if p.Err != nil {
+			probe.Error = p.Err.Error()
+			probe.Kind = reasonKindOf(p.Err)
+		}
+		if p.LastErr != nil {
+			probe.LastError = p.LastErr.Error()
+			probe.LastKind = reasonKindOf(p.LastErr)
+		}
+
+		stored.Probes = append(stored.Probes, probe)
+	}
+
+	return stored
+}
+
+func (s *storedResult) result() Result {
+	r := Result{
+		Ready:     s.Ready,
+		Status:    s.Status,
+		Reasons:   s.Reasons,
+		Probes:    make([]ProbeResult, 0, len(s.Probes)),
+		CheckedAt: s.CheckedAt,
+		Duration:  s.Duration,
+	}
+
+	for _, p := range s.Probes {
+		r.Probes = append(r.Probes, ProbeResult{
+			Name:         p.Name,
+			Critical:     p.Critical,
+			Err:          restoreError(p.Error, p.Kind),
+			Status:       p.Status,
+			CheckedAt:    p.CheckedAt,
+			Duration:     p.Duration,
+			Flapping:     p.Flapping,
+			Disabled:     p.Disabled,
+			Maintenance:  p.Maintenance,
+			Labels:       p.Labels,
+			Failures:     p.Failures,
+			LastErr:      restoreError(p.LastError, p.LastKind),
+			LastFailedAt: p.LastFailedAt,
+		})
+	}
+
+	return r
+}
+
+// Returns an error with the message classified as kind, nil if there is no message.
+func restoreError(msg string, kind ReasonKind) error {
+	if msg == "" {
+		return nil
+	}
+
+	err := errors.New(msg)
+	switch kind {
+	case KindTimeout:
+		return classify(ErrTimeout, err)
+	case KindUnreachable:
+		return classify(ErrUnreachable, err)
+	case KindUnauthorized:
+		return classify(ErrUnauthorized, err)
+	case KindUnhealthy:
+		return classify(ErrUnhealthy, err)
+	case KindStandby:
+		return Standby(err)
+	}
+
+	return err
+}
@@ -0,0 +1,127 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/stretchr/testify/assert"
+)
+
+// Connection keeping the values of SET and GET in a map
+type mapRedisConn struct {
+	fakeRedisConn
+	values map[string][]byte
+	args   []interface{}
+}
+
+func (c *mapRedisConn) Do(cmd string, args ...interface{}) (interface{}, error) {
+	switch cmd {
+	case "SET":
+		c.args = args
+		c.values[args[0].(string)] = args[1].([]byte)
+		return "OK", nil
+	case "GET":
+		if v, ok := c.values[args[0].(string)]; ok {
+			return v, nil
+		}
+		return nil, nil
+	case "":
+		return nil, nil
+	}
+
+	return nil, fmt.Errorf("unexpected command %v", cmd)
+}
+
+func TestChecker_ResultStore(t *testing.T) {
+	store := &MemoryResultStore{}
+
+	evaluator := &Checker{ResultStore: store}
+	evaluator.AddReadinessProbe("database", func() error { return errors.New("connection refused") })
+	defer evaluator.EvaluateInBackground(time.Hour)()
+
+	ran := false
+	worker := &Checker{ResultStore: store}
+	worker.AddReadinessProbe("database", func() error { ran = true; return nil })
+
+	var r Result
+	assert.Eventually(t, func() bool {
+		r = worker.readiness(context.Background(), nil)
+		return !r.Ready
+	}, time.Second, time.Millisecond)
+	assert.False(t, ran)
+	assert.EqualError(t, r.Probes[0].Err, "connection refused")
+
+	// Without a stored result the worker evaluates itself
+	ran = false
+	worker.ResultStore = &MemoryResultStore{}
+	assert.True(t, worker.readiness(context.Background(), nil).Ready)
+	assert.True(t, ran)
+}
+
+func TestChecker_ResultStore_otherProbes(t *testing.T) {
+	store := &MemoryResultStore{}
+
+	evaluator := &Checker{ResultStore: store}
+	evaluator.AddReadinessProbe("database", func() error { return nil })
+	evaluator.evaluateReadiness(context.Background(), false)
+
+	worker := &Checker{ResultStore: store}
+	worker.AddReadinessProbe("cache", func() error { return errors.New("connection refused") })
+	handler := worker.serverMux()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/.well-known/ready/cache", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code, "probe missing in the stored result runs itself")
+	assert.Contains(t, rec.Body.String(), "connection refused")
+
+	rec = httptest.NewRecorder()
+	worker.MetricsHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `health_probe_up{probe="database",critical="true"} 1`)
+	assert.NotContains(t, rec.Body.String(), `health_probe_failures_total{probe="database"}`)
+}
+
+func TestRedisResultStore(t *testing.T) {
+	conn := &mapRedisConn{values: map[string][]byte{}}
+	store := RedisResultStore(&redis.Pool{Dial: func() (redis.Conn, error) { return conn, nil }}, "health", time.Minute)
+
+	stored, err := store.Load(context.Background())
+	assert.NoError(t, err)
+	assert.Nil(t, stored)
+
+	checkedAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	r := Result{
+		Status:    StatusFail,
+		Reasons:   []Reason{{Service: "database", Error: "timed out after 1s", Kind: KindTimeout}},
+		CheckedAt: checkedAt,
+		Duration:  time.Second,
+		Probes: []ProbeResult{
+			{Name: "database", Critical: true, Err: &timeoutError{time.Second}, Status: StatusFail, CheckedAt: checkedAt},
+			{Name: "leader", Err: Standby(errors.New("follower")), Status: StatusStandby, Labels: map[string]string{"team": "ops"}},
+		},
+	}
+	assert.NoError(t, store.Save(context.Background(), r))
+	assert.Equal(t, []interface{}{"health", conn.values["health"], "PX", int64(60000)}, conn.args)
+
+	stored, err = store.Load(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, r.Reasons, stored.Reasons)
+	assert.Equal(t, checkedAt, stored.Probes[0].CheckedAt.UTC())
+	assert.EqualError(t, stored.Probes[0].Err, "timed out after 1s")
+	assert.True(t, errors.Is(stored.Probes[0].Err, ErrTimeout))
+	assert.Equal(t, KindStandby, reasonKindOf(stored.Probes[1].Err))
+	assert.Equal(t, map[string]string{"team": "ops"}, stored.Probes[1].Labels)
+	assert.Nil(t, stored.Probes[1].LastErr)
+}
+
+func TestRedisResultStore_noTTL(t *testing.T) {
+	assert.Panics(t, func() {
+		RedisResultStore(&redis.Pool{}, "health", 0)
+	})
+}
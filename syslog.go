@@ -0,0 +1,45 @@
+//go:build !windows && !plan9
+// +build !windows,!plan9
+
+package health
+
+import "log/syslog"
+
+// Returns a listener writing a record to syslog whenever the status of the service changes, e.g. from pass to fail,
+// so the transitions reach the log pipelines of VM-based deployments without extra agents. Connects to the syslog
+// server at raddr by network, or to the local syslog daemon if both are empty, and tags the records with tag.
+// Records are logged with the facility daemon and the priority err if the service fails, warning if it warns and
+// notice otherwise, and formatted as logfmt:
+//		msg="status changed" status=fail previous=pass ready=false reasons="db: connection refused"
+//
+// Example:
+//		listener, err := health.SyslogListener("", "", "my-service")
+//		if err != nil {
+//			log.Fatal(err)
+//		}
+//
+//		checker.AddListener(listener)
+func SyslogListener(network, raddr, tag string) (Listener, error) {
+	w, err := syslog.Dial(network, raddr, syslog.LOG_DAEMON|syslog.LOG_NOTICE, tag)
+	if err != nil {
+		return nil, err
+	}
+
+	return onTransition(func(previous Status, r Result) {
+		msg := transitionMessage(previous, r)
+
+		var err error
+		switch statusPriority(r.Status) {
+		case priorityErr:
+			err = w.Err(msg)
+		case priorityWarning:
+			err = w.Warning(msg)
+		default:
+			err = w.Notice(msg)
+		}
+
+		if err != nil {
+			r.log().Printf("failed to write to syslog: %v", err)
+		}
+	}), nil
+}
@@ -0,0 +1,31 @@
+//go:build !windows && !plan9
+// +build !windows,!plan9
+
+package health
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSyslogListener(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	listener, err := SyslogListener("udp", conn.LocalAddr().String(), "my-service")
+	assert.NoError(t, err)
+
+	listener(Result{Status: StatusWarn, Ready: true, Reasons: []Reason{{Service: "cache", Error: "slow"}}})
+
+	buf := make([]byte, 1024)
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	assert.NoError(t, err)
+
+	// Facility daemon (3) and priority warning (4)
+	assert.Regexp(t, `^<28>.* my-service\[\d+\]: msg="status changed" status=warn previous=pass ready=true reasons="cache: slow"\n$`, string(buf[:n]))
+}
@@ -0,0 +1,76 @@
+package health
+
+import (
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Returns a listener notifying systemd about the state of the service. READY=1 is sent once the service is ready
+// the first time and WATCHDOG=1 after each evaluation the service is ready, so systemd restarts the service if it
+// stops being healthy. Use with `Type=notify` and `WatchdogSec=` in the unit and evaluate in background at
+// SystemdWatchdogInterval. Does nothing if the service is not started by systemd.
+//
+// Example:
+//		checker.AddListener(health.SystemdListener())
+//		if interval, ok := health.SystemdWatchdogInterval(); ok {
+//			defer checker.EvaluateInBackground(interval)()
+//		}
+func SystemdListener() Listener {
+	ready := sync.Once{}
+
+	return func(r Result) {
+		if !r.Ready {
+			return
+		}
+
+		ready.Do(func() {
+			if err := sdNotify("READY=1"); err != nil {
+				r.log().Printf("failed to notify systemd: %v", err)
+			}
+		})
+
+		if err := sdNotify("WATCHDOG=1"); err != nil {
+			r.log().Printf("failed to notify systemd: %v", err)
+		}
+	}
+}
+
+// Returns half of the watchdog timeout configured by systemd, which is the recommended interval to evaluate the
+// probes in. Returns false if the watchdog is not enabled for this process.
+func SystemdWatchdogInterval() (time.Duration, bool) {
+	usec, err := strconv.ParseInt(os.Getenv("WATCHDOG_USEC"), 10, 64)
+	if err != nil || usec <= 0 {
+		return 0, false
+	}
+
+	if pid := os.Getenv("WATCHDOG_PID"); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+		return 0, false
+	}
+
+	return time.Duration(usec) * time.Microsecond / 2, true
+}
+
+// Sends the state to the socket given by NOTIFY_SOCKET. Does nothing if it is not set.
+func sdNotify(state string) error {
+	socket := os.Getenv("NOTIFY_SOCKET")
+	if socket == "" {
+		return nil
+	}
+
+	// Abstract unix sockets are prefixed by @ instead of a null byte
+	if socket[0] == '@' {
+		socket = "\x00" + socket[1:]
+	}
+
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_, err = conn.Write([]byte(state))
+	return err
+}
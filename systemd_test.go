@@ -0,0 +1,62 @@
+package health
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSystemdListener(t *testing.T) {
+	socket := filepath.Join(os.TempDir(), fmt.Sprintf("healthchecker-notify-%d", os.Getpid()))
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		t.Skipf("unix datagram sockets are not supported: %v", err)
+	}
+	defer os.Remove(socket)
+	defer conn.Close()
+
+	_ = os.Setenv("NOTIFY_SOCKET", socket)
+	defer os.Unsetenv("NOTIFY_SOCKET")
+
+	listener := SystemdListener()
+	listener(Result{Ready: false})
+	listener(Result{Ready: true})
+	listener(Result{Ready: true})
+
+	var received []string
+	buf := make([]byte, 64)
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+	for i := 0; i < 3; i++ {
+		n, err := conn.Read(buf)
+		if err != nil {
+			break
+		}
+
+		received = append(received, string(buf[:n]))
+	}
+
+	assert.Equal(t, []string{"READY=1", "WATCHDOG=1", "WATCHDOG=1"}, received)
+}
+
+func TestSystemdWatchdogInterval(t *testing.T) {
+	_ = os.Setenv("WATCHDOG_USEC", "30000000")
+	_ = os.Setenv("WATCHDOG_PID", strconv.Itoa(os.Getpid()))
+	defer os.Unsetenv("WATCHDOG_USEC")
+	defer os.Unsetenv("WATCHDOG_PID")
+
+	interval, ok := SystemdWatchdogInterval()
+
+	assert.True(t, ok)
+	assert.Equal(t, 15*time.Second, interval)
+
+	_ = os.Setenv("WATCHDOG_PID", "1")
+	_, ok = SystemdWatchdogInterval()
+
+	assert.False(t, ok)
+}
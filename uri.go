@@ -0,0 +1,398 @@
+package health
+
+import (
+	"context"
+	"crypto/tls"
+	"database/sql"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+const defaultURITimeout = 5 * time.Second
+
+// A ProbeFactory creates a probe for the given URI.
+type ProbeFactory func(u *url.URL) (Probe, error)
+
+// A factory of probes opening connections, which also returns a closer releasing them.
+type closingProbeFactory func(u *url.URL) (Probe, io.Closer, error)
+
+var (
+	schemesMu sync.RWMutex
+	schemes   = map[string]ProbeFactory{}
+	// Factories of the schemes in schemes opening connections
+	closingSchemes = map[string]closingProbeFactory{}
+)
+
+func init() {
+	RegisterScheme("http", httpProbeFactory)
+	RegisterScheme("https", httpProbeFactory)
+	RegisterScheme("tcp", tcpProbeFactory)
+	RegisterScheme("tls", tlsProbeFactory)
+	registerClosingScheme("redis", redisProbeFactory)
+	registerClosingScheme("rediss", redisProbeFactory)
+	registerClosingScheme("postgres", postgresProbeFactory)
+	registerClosingScheme("postgresql", postgresProbeFactory)
+	registerClosingScheme("mongodb", mongoProbeFactory)
+	registerClosingScheme("mongodb+srv", mongoProbeFactory)
+	registerClosingScheme("grpc", grpcProbeFactory)
+	registerClosingScheme("grpcs", grpcProbeFactory)
+	RegisterScheme("ping", pingProbeFactory)
+	RegisterScheme("ntp", ntpProbeFactory)
+	RegisterScheme("smtp", smtpProbeFactory)
+	RegisterScheme("file", fileProbeFactory)
+	RegisterScheme("disk", diskProbeFactory)
+	RegisterScheme("goroutines", goroutineProbeFactory)
+	RegisterScheme("memory", memoryProbeFactory)
+	RegisterScheme("statefile", stateFileProbeFactory)
+	RegisterScheme("ssh", sshProbeFactory)
+}
+
+// Registers a factory for probes of the given URI scheme, so it can be used with ProbeFromURI.
+// Panics if the scheme is already registered.
+//
+// Example:
+//		health.RegisterScheme("amqp", func(u *url.URL) (health.Probe, error) {
+//			return MyAMQPProbe(u.String()), nil
+//		})
+func RegisterScheme(scheme string, factory ProbeFactory) {
+	schemesMu.Lock()
+	defer schemesMu.Unlock()
+
+	scheme = strings.ToLower(scheme)
+	if _, alreadyRegistered := schemes[scheme]; alreadyRegistered {
+		panic(fmt.Sprintf("a probe factory for scheme %v is already registered", scheme))
+	}
+
+	schemes[scheme] = factory
+}
+
+// Registers a factory of probes opening connections, see probeFromURI.
+func registerClosingScheme(scheme string, factory closingProbeFactory) {
+	RegisterScheme(scheme, func(u *url.URL) (Probe, error) {
+		probe, _, err := factory(u)
+		return probe, err
+	})
+
+	schemesMu.Lock()
+	defer schemesMu.Unlock()
+
+	closingSchemes[scheme] = factory
+}
+
+// Creates a probe from a URI using the factory registered for its scheme.
+// This enables config driven health checks without importing driver specific constructors.
+// Supported out of the box:
+//		http://, https://                     HTTPProbe requesting the URI
+//		tcp://host:port?timeout=2s            TCPProbe
+//		tls://host:port?expires_within=336h   TLSProbe
+//		redis://, rediss://                   RedisPoolProbe
+//		postgres://, postgresql://            SQLProbe, requires a registered "postgres" or "pgx" driver
+//		mongodb://, mongodb+srv://            MongoProbe
+//		grpc://host:port, grpcs://host:port   GrpcProbe
+//		ping://host?timeout=2s                PingProbe
+//		ntp://host?max_drift=500ms            NTPProbe
+//		smtp://host:port?timeout=5s           SMTPProbe
+//		file:///path?access=rw                FileProbe
+//		disk:///path?min_free_bytes=1048576&min_free_percent=5   DiskSpaceProbe
+//		goroutines://?max=10000               GoroutineProbe
+//		memory://?max_rss=1073741824&max_heap=0   MemoryProbe
+//		statefile:///path?max_age=30s        StateFileProbe
+//		ssh://host:port?timeout=5s            SSHProbe without authentication
+//
+// Example:
+//		probe, err := health.ProbeFromURI("redis://redis:6379/0")
+func ProbeFromURI(uri string) (Probe, error) {
+	probe, _, err := probeFromURI(uri)
+	return probe, err
+}
+
+// Creates a probe like ProbeFromURI and returns a closer releasing the connections opened for it, nil if it opened
+// none.
+func probeFromURI(uri string) (Probe, io.Closer, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid probe uri: %w", err)
+	}
+
+	scheme := strings.ToLower(u.Scheme)
+	schemesMu.RLock()
+	factory, ok := schemes[scheme]
+	closingFactory, closing := closingSchemes[scheme]
+	schemesMu.RUnlock()
+
+	if !ok {
+		return nil, nil, fmt.Errorf("no probe registered for scheme %q", u.Scheme)
+	}
+
+	if closing {
+		return closingFactory(u)
+	}
+
+	probe, err := factory(u)
+	return probe, nil, err
+}
+
+// Adapts a function like the Disconnect of a mongo client to an io.Closer.
+type closerFunc func() error
+
+func (f closerFunc) Close() error {
+	return f()
+}
+
+// Returns the duration given by the query parameter or the default if not set.
+func queryDuration(u *url.URL, name string, def time.Duration) (time.Duration, error) {
+	v := u.Query().Get(name)
+	if v == "" {
+		return def, nil
+	}
+
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %v: %w", name, err)
+	}
+
+	return d, nil
+}
+
+// Returns the non-negative number given by the query parameter or the default if not set.
+func queryFloat(u *url.URL, name string, def float64) (float64, error) {
+	v := u.Query().Get(name)
+	if v == "" {
+		return def, nil
+	}
+
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %v: %w", name, err)
+	}
+
+	if f < 0 {
+		return 0, fmt.Errorf("invalid %v: must not be negative", name)
+	}
+
+	return f, nil
+}
+
+// Returns the non-negative integer given by the query parameter or the default if not set.
+func queryUint(u *url.URL, name string, def uint64) (uint64, error) {
+	v := u.Query().Get(name)
+	if v == "" {
+		return def, nil
+	}
+
+	n, err := strconv.ParseUint(v, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %v: %w", name, err)
+	}
+
+	return n, nil
+}
+
+func httpProbeFactory(u *url.URL) (Probe, error) {
+	return HTTPProbe(u.String()), nil
+}
+
+func tcpProbeFactory(u *url.URL) (Probe, error) {
+	timeout, err := queryDuration(u, "timeout", defaultURITimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	return TCPProbe(u.Host, timeout), nil
+}
+
+func tlsProbeFactory(u *url.URL) (Probe, error) {
+	timeout, err := queryDuration(u, "timeout", defaultTLSDialTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	expiresWithin, err := queryDuration(u, "expires_within", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return TLSProbe(u.Host, expiresWithin, TLSDialTimeout(timeout)), nil
+}
+
+func redisProbeFactory(u *url.URL) (Probe, io.Closer, error) {
+	uri := u.String()
+	timeout, err := queryDuration(u, "timeout", defaultURITimeout)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pool := &redis.Pool{
+		MaxIdle:     1,
+		IdleTimeout: time.Minute,
+		Dial: func() (redis.Conn, error) {
+			return redis.DialURL(uri, redis.DialConnectTimeout(timeout))
+		},
+	}
+
+	return RedisPoolProbe(pool), pool, nil
+}
+
+func postgresProbeFactory(u *url.URL) (Probe, io.Closer, error) {
+	for _, driver := range []string{"postgres", "pgx"} {
+		for _, registered := range sql.Drivers() {
+			if driver == registered {
+				db, err := sql.Open(driver, u.String())
+				if err != nil {
+					return nil, nil, err
+				}
+
+				return SQLProbe(db), db, nil
+			}
+		}
+	}
+
+	return nil, nil, fmt.Errorf("no postgres sql driver registered")
+}
+
+func mongoProbeFactory(u *url.URL) (Probe, io.Closer, error) {
+	timeout, err := queryDuration(u, "timeout", defaultURITimeout)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Remove the probe specific parameter, as it is no valid connection string option
+	q := u.Query()
+	q.Del("timeout")
+	cu := *u
+	cu.RawQuery = q.Encode()
+
+	client, err := mongo.NewClient(options.Client().ApplyURI(cu.String()))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := client.Connect(context.Background()); err != nil {
+		return nil, nil, err
+	}
+
+	disconnect := func() error {
+		return client.Disconnect(context.Background())
+	}
+
+	return MongoProbe(client, MongoTimeout(timeout)), closerFunc(disconnect), nil
+}
+
+func grpcProbeFactory(u *url.URL) (Probe, io.Closer, error) {
+	wait, err := queryDuration(u, "timeout", time.Second)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	creds := grpc.WithInsecure()
+	if strings.ToLower(u.Scheme) == "grpcs" {
+		creds = grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{}))
+	}
+
+	conn, err := grpc.Dial(u.Host, creds)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return GrpcProbe(conn, GrpcReconnect(wait)), conn, nil
+}
+
+func pingProbeFactory(u *url.URL) (Probe, error) {
+	timeout, err := queryDuration(u, "timeout", defaultPingTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	return PingProbe(u.Hostname(), PingTimeout(timeout)), nil
+}
+
+func ntpProbeFactory(u *url.URL) (Probe, error) {
+	timeout, err := queryDuration(u, "timeout", defaultNTPTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	maxDrift, err := queryDuration(u, "max_drift", time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	return NTPProbe(u.Host, maxDrift, NTPTimeout(timeout)), nil
+}
+
+func smtpProbeFactory(u *url.URL) (Probe, error) {
+	timeout, err := queryDuration(u, "timeout", defaultSMTPTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	return SMTPProbe(u.Host, SMTPTimeout(timeout)), nil
+}
+
+func fileProbeFactory(u *url.URL) (Probe, error) {
+	access := FileExists
+	for _, c := range u.Query().Get("access") {
+		switch c {
+		case 'r':
+			access |= FileReadable
+		case 'w':
+			access |= FileWritable
+		default:
+			return nil, fmt.Errorf("invalid access %q, use a combination of r and w", c)
+		}
+	}
+
+	return FileProbe(u.Path, access), nil
+}
+
+func diskProbeFactory(u *url.URL) (Probe, error) {
+	minFreeBytes, err := queryUint(u, "min_free_bytes", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	minFreePercent, err := queryFloat(u, "min_free_percent", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return DiskSpaceProbe(u.Path, minFreeBytes, minFreePercent), nil
+}
+
+func goroutineProbeFactory(u *url.URL) (Probe, error) {
+	max, err := queryUint(u, "max", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	if max == 0 {
+		return nil, fmt.Errorf("max is required")
+	}
+
+	return GoroutineProbe(int(max)), nil
+}
+
+func memoryProbeFactory(u *url.URL) (Probe, error) {
+	maxRSS, err := queryUint(u, "max_rss", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	maxHeap, err := queryUint(u, "max_heap", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return MemoryProbe(maxRSS, maxHeap), nil
+}
@@ -0,0 +1,94 @@
+package health
+
+import (
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Media types selecting the version of the JSON responses via the Accept header. Version 1 is served by default and
+// lists the reasons of the readiness response as `service: error` strings, see ReadyResponseV1. Version 2 lists them
+// as Reason objects including their kind and labels and lists passing probes as well, like `?verbose=1`, see
+// ReadyResponse.
+const (
+	MediaTypeHealthV1 = "application/vnd.health.v1+json"
+	MediaTypeHealthV2 = "application/vnd.health.v2+json"
+)
+
+const (
+	mediaTypeHealthPrefix = "application/vnd.health.v"
+	mediaTypeHealthSuffix = "+json"
+	latestResponseVersion = 2
+)
+
+// ReadyResponseV1 is the legacy response of the readiness endpoint, served as JSON unless version 2 is requested.
+// It equals ReadyResponse except for the reasons, which are `service: error` strings.
+type ReadyResponseV1 struct {
+	Ready     bool              `json:"ready"`
+	Status    Status            `json:"status,omitempty"`
+	Reasons   []string          `json:"reasons,omitempty"`
+	CheckedAt *time.Time        `json:"checkedAt,omitempty"`
+	Duration  string            `json:"duration,omitempty"`
+	Probes    []ProbeStatus     `json:"probes,omitempty"`
+	Peers     *PeerStatus       `json:"peers,omitempty"`
+	Metadata  map[string]string `json:"metadata,omitempty"`
+}
+
+// Returns the response in the legacy format.
+func (r *ReadyResponse) v1() *ReadyResponseV1 {
+	resp := &ReadyResponseV1{
+		Ready:     r.Ready,
+		Status:    r.Status,
+		CheckedAt: r.CheckedAt,
+		Duration:  r.Duration,
+		Probes:    r.Probes,
+		Peers:     r.Peers,
+		Metadata:  r.Metadata,
+	}
+	for _, reason := range r.Reasons {
+		resp.Reasons = append(resp.Reasons, reason.String())
+	}
+
+	return resp
+}
+
+// Returns the response version requested by the Accept header, 0 if no version was requested. Returns false if
+// only unsupported versions were requested.
+func requestedVersion(r *http.Request) (int, bool) {
+	accept := r.Header.Get("Accept")
+	if !strings.Contains(accept, mediaTypeHealthPrefix) {
+		return 0, true
+	}
+
+	unsupported := false
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		if !strings.HasPrefix(mediaType, mediaTypeHealthPrefix) || !strings.HasSuffix(mediaType, mediaTypeHealthSuffix) {
+			// Other acceptable types are served with the default version
+			if mediaType == "*/*" || mediaType == "application/*" || mediaType == "application/json" {
+				return 0, true
+			}
+			continue
+		}
+
+		v, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(mediaType, mediaTypeHealthPrefix), mediaTypeHealthSuffix))
+		if err == nil && v >= 1 && v <= latestResponseVersion {
+			return v, true
+		}
+
+		unsupported = true
+	}
+
+	return 0, !unsupported
+}
+
+// Returns the content type of responses in the given version.
+func versionedContentType(version int) string {
+	return mediaTypeHealthPrefix + strconv.Itoa(version) + mediaTypeHealthSuffix
+}
@@ -0,0 +1,100 @@
+package health
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestedVersion(t *testing.T) {
+	for accept, expected := range map[string]struct {
+		version int
+		ok      bool
+	}{
+		"":                                {0, true},
+		"application/json":                {0, true},
+		MediaTypeHealthV1:                 {1, true},
+		MediaTypeHealthV2 + ";q=0.9, */*": {2, true},
+		"application/vnd.health.v3+json":  {0, false},
+		"application/vnd.health.v3+json, application/json": {0, true},
+	} {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Accept", accept)
+
+		version, ok := requestedVersion(r)
+		assert.Equal(t, expected.version, version, accept)
+		assert.Equal(t, expected.ok, ok, accept)
+	}
+}
+
+func TestChecker_ready_versions(t *testing.T) {
+	checker := &Checker{}
+	checker.AddReadinessProbe("database", func() error { return nil })
+	checker.AddReadinessProbe("cache", func() error { return fmt.Errorf("connection refused") }, NonCritical())
+
+	server := httptest.NewServer(checker.serverMux())
+	defer server.Close()
+
+	get := func(accept string) (*http.Response, string) {
+		req, _ := http.NewRequest(http.MethodGet, server.URL+"/.well-known/ready", nil)
+		req.Header.Set("Accept", accept)
+		resp, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+
+		body, _ := ioutil.ReadAll(resp.Body)
+		return resp, string(body)
+	}
+
+	assertLegacy := func(body string) {
+		var legacy ReadyResponseV1
+		assert.NoError(t, json.Unmarshal([]byte(body), &legacy))
+		assert.Equal(t, StatusWarn, legacy.Status)
+		assert.Equal(t, []string{"cache: connection refused"}, legacy.Reasons)
+		assert.Len(t, legacy.Probes, 1, "passing probes are omitted")
+	}
+
+	resp, body := get("")
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	assertLegacy(body)
+
+	resp, body = get(MediaTypeHealthV1)
+	assert.Equal(t, MediaTypeHealthV1, resp.Header.Get("Content-Type"))
+	assertLegacy(body)
+
+	resp, body = get(MediaTypeHealthV2)
+	assert.Equal(t, MediaTypeHealthV2, resp.Header.Get("Content-Type"))
+	assert.Equal(t, "Accept", resp.Header.Get("Vary"))
+
+	var ready ReadyResponse
+	assert.NoError(t, json.Unmarshal([]byte(body), &ready))
+	assert.Equal(t, StatusWarn, ready.Status)
+	assert.Len(t, ready.Probes, 2, "all probes are listed")
+
+	resp, _ = get("application/vnd.health.v9+json")
+	assert.Equal(t, http.StatusNotAcceptable, resp.StatusCode)
+}
+
+func TestChecker_ready_legacyVerbose(t *testing.T) {
+	checker := &Checker{Metadata: map[string]string{"zone": "eu-1"}}
+	checker.AddReadinessProbe("database", func() error { return nil })
+	checker.AddReadinessProbe("bad", func() error { return fmt.Errorf("x") }, NonCritical())
+
+	server := httptest.NewServer(checker.serverMux())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/.well-known/ready?verbose=1")
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	var body ReadyResponseV1
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, []string{"bad: x"}, body.Reasons)
+	assert.Len(t, body.Probes, 2, "passing probes are listed")
+	assert.Equal(t, map[string]string{"zone": "eu-1"}, body.Metadata)
+	assert.NotNil(t, body.CheckedAt)
+}
@@ -0,0 +1,227 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Interval at which WaitUntilReady evaluates the probes
+const waitInterval = time.Second
+
+const (
+	defaultWaitBackoff    = 100 * time.Millisecond
+	defaultWaitMaxBackoff = 10 * time.Second
+)
+
+// Blocks until the service is ready or ctx is done, e.g. to start consumers or servers only once the dependencies
+// are reachable. Returns an error listing the reasons if ctx is done first.
+//
+// Example:
+//		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+//		defer cancel()
+//
+//		if err := checker.WaitUntilReady(ctx); err != nil {
+//			log.Fatal(err)
+//		}
+func (h *Checker) WaitUntilReady(ctx context.Context) error {
+	return h.waitUntilReady(ctx, nil)
+}
+
+// Blocks until the given readiness probes pass or ctx is done, e.g. to wait for the database only.
+// Fails immediately for names not matching a registered probe.
+//
+// Example:
+//		if err := checker.WaitUntilReadyFor(ctx, "database", "cache"); err != nil {
+//			log.Fatal(err)
+//		}
+func (h *Checker) WaitUntilReadyFor(ctx context.Context, probes ...string) error {
+	names, err := probeNames(probes, h.readinessProbes)
+	if err != nil {
+		return err
+	}
+
+	return h.waitUntilReady(ctx, func(name string) bool {
+		return names[name]
+	})
+}
+
+func (h *Checker) waitUntilReady(ctx context.Context, keep probeFilter) error {
+	ticker := h.clock().NewTicker(waitInterval)
+	defer ticker.Stop()
+
+	for {
+		r := h.readiness(ctx, keep)
+		if r.Ready {
+			return nil
+		}
+
+		select {
+		case <-ticker.C():
+		case <-ctx.Done():
+			return fmt.Errorf("service is not ready (%v): %w", strings.Join(reasonStrings(r.Reasons), ", "), ctx.Err())
+		}
+	}
+}
+
+type waitConfig struct {
+	backoff    time.Duration
+	maxBackoff time.Duration
+	timeout    time.Duration
+	timeouts   map[string]time.Duration
+	logger     Logger
+	clock      Clock
+}
+
+// A WaitOption configures WaitFor.
+type WaitOption func(c *waitConfig)
+
+// Waits initial after the first failure of a dependency and doubles the wait after each further failure up to max.
+// Defaults to 100ms and 10s.
+func WaitBackoff(initial, max time.Duration) WaitOption {
+	return func(c *waitConfig) {
+		c.backoff = initial
+		c.maxBackoff = max
+	}
+}
+
+// Fails each attempt to reach a dependency after d. By default attempts are not timed out.
+func WaitTimeout(d time.Duration) WaitOption {
+	return func(c *waitConfig) {
+		c.timeout = d
+	}
+}
+
+// Fails each attempt to reach the given dependency after d, overriding WaitTimeout.
+func WaitDependencyTimeout(name string, d time.Duration) WaitOption {
+	return func(c *waitConfig) {
+		c.timeouts[name] = d
+	}
+}
+
+// Sets the clock timing the attempts and the waits between them, e.g. a fake clock in tests. Defaults to the real
+// time.
+func WaitClock(clock Clock) WaitOption {
+	return func(c *waitConfig) {
+		c.clock = clock
+	}
+}
+
+// Logs each failed attempt, e.g. to show in the boot log which dependency is awaited.
+func WaitLogger(logger Logger) WaitOption {
+	return func(c *waitConfig) {
+		c.logger = logger
+	}
+}
+
+// Blocks until all dependencies pass or ctx is done, e.g. to start the main listeners of a service only once its
+// dependencies are reachable. Reuses the probes of the runtime checker without registering them. The dependencies
+// are awaited concurrently, each retried with exponential backoff. Returns an error listing the failing dependencies
+// if ctx is done first.
+//
+// Example:
+//		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+//		defer cancel()
+//
+//		err := health.WaitFor(ctx, map[string]health.Probe{
+//			"database": health.SQLProbe(db),
+//			"broker":   health.TCPProbe("broker:5672", time.Second),
+//		}, health.WaitTimeout(5*time.Second), health.WaitLogger(log.Default()))
+//		if err != nil {
+//			log.Fatal(err)
+//		}
+func WaitFor(ctx context.Context, dependencies map[string]Probe, opts ...WaitOption) error {
+	cfg := &waitConfig{
+		backoff:    defaultWaitBackoff,
+		maxBackoff: defaultWaitMaxBackoff,
+		timeouts:   map[string]time.Duration{},
+		clock:      realClock{},
+	}
+
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	names := make([]string, 0, len(dependencies))
+	for name := range dependencies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	errs := make([]error, len(names))
+	var wg sync.WaitGroup
+	for i, name := range names {
+		i, name := i, name
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = cfg.await(ctx, name, dependencies[name])
+		}()
+	}
+	wg.Wait()
+
+	var reasons []string
+	for i, err := range errs {
+		if err != nil {
+			reasons = append(reasons, fmt.Sprintf("%v: %v", names[i], err))
+		}
+	}
+
+	if len(reasons) > 0 {
+		return fmt.Errorf("dependencies are not ready (%v): %w", strings.Join(reasons, ", "), ctx.Err())
+	}
+
+	return nil
+}
+
+// Runs the probe until it passes or ctx is done. Returns the latest error of the probe if ctx is done first. A
+// hanging attempt is abandoned once ctx is done.
+func (c *waitConfig) await(ctx context.Context, name string, probe Probe) error {
+	timeout, ok := c.timeouts[name]
+	if !ok {
+		timeout = c.timeout
+	}
+
+	runner := &boundedRunner{}
+	backoff := c.backoff
+	var last error
+	for {
+		var expired <-chan time.Time
+		if timeout > 0 {
+			expired = c.clock.After(timeout)
+		}
+
+		err := runner.runUntil(ctx, expired, timeout, probe)
+		if err == nil {
+			return nil
+		}
+
+		// An attempt abandoned as ctx is done tells less than the failure of the previous attempt
+		if ctx.Err() != nil {
+			if last != nil {
+				return last
+			}
+
+			return err
+		}
+		last = err
+
+		if c.logger != nil {
+			c.logger.Printf("waiting for %v: %v", name, err)
+		}
+
+		select {
+		case <-c.clock.After(backoff):
+		case <-ctx.Done():
+			return err
+		}
+
+		if backoff *= 2; backoff > c.maxBackoff {
+			backoff = c.maxBackoff
+		}
+	}
+}
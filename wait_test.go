@@ -0,0 +1,108 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChecker_WaitUntilReady(t *testing.T) {
+	var calls int32
+	checker := &Checker{}
+	checker.AddReadinessProbe("database", func() error {
+		if atomic.AddInt32(&calls, 1) < 2 {
+			return fmt.Errorf("connection refused")
+		}
+		return nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	assert.NoError(t, checker.WaitUntilReady(ctx))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestChecker_WaitUntilReady_timeout(t *testing.T) {
+	checker := &Checker{}
+	checker.AddReadinessProbe("database", func() error {
+		return fmt.Errorf("connection refused")
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	err := checker.WaitUntilReady(ctx)
+
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.Contains(t, err.Error(), "database: ")
+}
+
+func TestChecker_WaitUntilReadyFor(t *testing.T) {
+	checker := &Checker{}
+	checker.AddReadinessProbe("database", func() error { return nil })
+	checker.AddReadinessProbe("search", func() error {
+		return fmt.Errorf("connection refused")
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	assert.NoError(t, checker.WaitUntilReadyFor(ctx, "database"))
+	assert.EqualError(t, checker.WaitUntilReadyFor(ctx, "queue"), `no probe named "queue"`)
+}
+
+func TestWaitFor(t *testing.T) {
+	var calls int32
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := WaitFor(ctx, map[string]Probe{
+		"database": func() error {
+			if atomic.AddInt32(&calls, 1) < 3 {
+				return fmt.Errorf("connection refused")
+			}
+			return nil
+		},
+		"broker": func() error { return nil },
+	}, WaitBackoff(time.Millisecond, 5*time.Millisecond))
+
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
+
+func TestWaitFor_timeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	err := WaitFor(ctx, map[string]Probe{
+		"broker":   func() error { return fmt.Errorf("connection refused") },
+		"database": func() error { <-release; return nil },
+		"cache":    func() error { return nil },
+	}, WaitTimeout(time.Second), WaitDependencyTimeout("database", 10*time.Millisecond), WaitBackoff(time.Millisecond, time.Millisecond))
+
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.EqualError(t, err, "dependencies are not ready (broker: connection refused, database: timed out after 10ms): context deadline exceeded")
+}
+
+func TestWaitFor_hangingWithoutTimeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	err := WaitFor(ctx, map[string]Probe{
+		"database": func() error { <-release; return nil },
+	})
+
+	assert.EqualError(t, err, "dependencies are not ready (database: context deadline exceeded): context deadline exceeded")
+}
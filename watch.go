@@ -0,0 +1,125 @@
+package health
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const defaultWatchTimeout = 30 * time.Second
+
+// WatchResponse is the response of the WatchHandler, the status of the service including the results of all probes.
+type WatchResponse struct {
+	// Identifies the state of the probes, pass it as `version` to wait for the next change
+	Version  string            `json:"version"`
+	Ready    bool              `json:"ready"`
+	Status   Status            `json:"status"`
+	Reasons  []Reason          `json:"reasons,omitempty"`
+	Probes   []ProbeStatus     `json:"probes"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// Returns a channel receiving the current result of the readiness probes and then each result in which a probe
+// changed its state, e.g. for a controller reacting to the health of the service in near real-time. Results are
+// dropped if the receiver is too slow, only the latest one is kept. The channel is closed once ctx is done.
+// Use it together with EvaluateInBackground, otherwise only evaluations triggered by requests are received.
+//
+// Example:
+//		for r := range checker.Watch(ctx) {
+//			log.Printf("service is %v: %v", r.Status, r.Reasons)
+//		}
+func (h *Checker) Watch(ctx context.Context) <-chan Result {
+	ch := make(chan Result)
+	updates, unsubscribe := h.subscribe()
+
+	go func() {
+		defer close(ch)
+		defer unsubscribe()
+
+		last := h.readiness(ctx, nil)
+		pending := true
+
+		for {
+			// Only send while a result is pending
+			var out chan<- Result
+			if pending {
+				out = ch
+			}
+
+			select {
+			case out <- last:
+				pending = false
+			case r := <-updates:
+				if pending || !r.sameState(last) {
+					last = r
+					pending = true
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return ch
+}
+
+// Returns a long-polling handler responding with the status of the service including the results of all probes as
+// WatchResponse. If the request passes the version of the latest response it knows as `?version=`, the response is
+// held until a probe changes its state or timeout passes, so clients observe changes in near real-time by polling
+// again right after each response. Timeout defaults to 30s. Use it together with EvaluateInBackground.
+//
+// Example:
+//		mux.Handle("/.well-known/watch", checker.WatchHandler(time.Minute))
+//		defer checker.EvaluateInBackground(5 * time.Second)()
+func (h *Checker) WatchHandler(timeout time.Duration) http.Handler {
+	if timeout <= 0 {
+		timeout = defaultWatchTimeout
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		updates, unsubscribe := h.subscribe()
+		defer unsubscribe()
+
+		current := h.readiness(r.Context(), nil)
+		if version := r.URL.Query().Get("version"); version != "" && version == current.version() {
+			expired := h.clock().After(timeout)
+
+		wait:
+			for {
+				select {
+				case u := <-updates:
+					if u.version() != version {
+						current = u
+						break wait
+					}
+				case <-expired:
+					break wait
+				case <-r.Context().Done():
+					return
+				}
+			}
+		}
+
+		h.writeResponse(w, r, true, &WatchResponse{
+			Version:  current.version(),
+			Ready:    current.Ready,
+			Status:   current.Status,
+			Reasons:  current.Reasons,
+			Probes:   newProbeStatuses(current.Probes),
+			Metadata: h.Metadata,
+		})
+	})
+}
+
+// Returns a hash of the state compared by sameState.
+func (r Result) version() string {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%v\n", r.Status)
+	for _, p := range r.Probes {
+		fmt.Fprintf(hash, "%v\n%v\n", p.Name, p.Err)
+	}
+
+	return fmt.Sprintf("%x", hash.Sum(nil)[:8])
+}
@@ -0,0 +1,104 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChecker_Watch(t *testing.T) {
+	var healthy int32 = 1
+	checker := &Checker{}
+	checker.AddReadinessProbe("broker", func() error {
+		if atomic.LoadInt32(&healthy) == 0 {
+			return fmt.Errorf("connection refused")
+		}
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(timeoutContext(t))
+	results := checker.Watch(ctx)
+	assert.True(t, (<-results).Ready)
+
+	// Unchanged results are not sent
+	checker.evaluateReadiness(context.Background(), true)
+	atomic.StoreInt32(&healthy, 0)
+	checker.evaluateReadiness(context.Background(), true)
+
+	r := <-results
+	assert.False(t, r.Ready)
+	assert.EqualError(t, r.Probes[0].Err, "connection refused")
+
+	cancel()
+	for range results {
+	}
+}
+
+func TestChecker_WatchHandler(t *testing.T) {
+	var healthy int32 = 1
+	checker := &Checker{}
+	checker.AddReadinessProbe("broker", func() error {
+		if atomic.LoadInt32(&healthy) == 0 {
+			return fmt.Errorf("connection refused")
+		}
+		return nil
+	})
+
+	server := httptest.NewServer(checker.WatchHandler(time.Hour))
+	defer server.Close()
+
+	watch := func(version string) WatchResponse {
+		resp, err := http.Get(server.URL + "?version=" + version)
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var body WatchResponse
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		return body
+	}
+
+	first := watch("")
+	assert.True(t, first.Ready)
+	assert.NotEmpty(t, first.Version)
+
+	changed := make(chan WatchResponse)
+	go func() {
+		changed <- watch(first.Version)
+	}()
+
+	// The request is held until a probe changes its state
+	atomic.StoreInt32(&healthy, 0)
+	var next WatchResponse
+	for next.Version == "" {
+		checker.evaluateReadiness(context.Background(), true)
+		select {
+		case next = <-changed:
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+
+	assert.False(t, next.Ready)
+	assert.NotEqual(t, first.Version, next.Version)
+	assert.Equal(t, "connection refused", next.Probes[0].Error)
+}
+
+func TestChecker_WatchHandler_timeout(t *testing.T) {
+	checker := &Checker{}
+	checker.AddReadinessProbe("broker", func() error { return nil })
+
+	version := checker.readiness(context.Background(), nil).version()
+	rec := httptest.NewRecorder()
+	checker.WatchHandler(10*time.Millisecond).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/?version="+version, nil))
+
+	var body WatchResponse
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, version, body.Version)
+}
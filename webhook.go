@@ -0,0 +1,150 @@
+package health
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sync"
+	"text/template"
+	"time"
+)
+
+const defaultWebhookTimeout = 10 * time.Second
+
+// Template of a Microsoft Teams message card for WebhookListener, colored by the status of the service.
+const TeamsTemplate = `{
+	"@type": "MessageCard",
+	"@context": "https://schema.org/extensions",
+	"themeColor": "{{if eq .Result.Status "fail"}}D70000{{else if eq .Result.Status "pass"}}2EB886{{else}}FFA500{{end}}",
+	"summary": {{json (printf "%v is %v" .Host .Result.Status)}},
+	"sections": [{
+		"activityTitle": {{json (printf "%v is %v" .Host .Result.Status)}},
+		"activitySubtitle": {{json (printf "was %v" .Previous)}},
+		"facts": [{{range $i, $r := .Result.Reasons}}{{if $i}},{{end}}
+			{"name": {{json $r.Service}}, "value": {{json $r.Error}}}{{end}}
+		]
+	}]
+}`
+
+// Template of a generic JSON webhook for WebhookListener.
+const JSONWebhookTemplate = `{"host": {{json .Host}}, "ready": {{.Result.Ready}}, "status": {{json .Result.Status}}, "previous": {{json .Previous}}, "reasons": {{json .Result.Reasons}}}`
+
+type webhookConfig struct {
+	client *http.Client
+	header http.Header
+}
+
+// A WebhookOption configures a WebhookListener.
+type WebhookOption func(c *webhookConfig)
+
+// Sets the http client used to post the messages. Defaults to a client with a timeout of 10s.
+func WebhookClient(client *http.Client) WebhookOption {
+	return func(c *webhookConfig) {
+		c.client = client
+	}
+}
+
+// Adds a request header, e.g. an authorization token.
+func WebhookHeader(key, value string) WebhookOption {
+	return func(c *webhookConfig) {
+		c.header.Add(key, value)
+	}
+}
+
+// WebhookData is passed to the template of a WebhookListener.
+type WebhookData struct {
+	// Host name of the instance
+	Host string
+	// Status of the service before the change
+	Previous Status
+	// Result of the evaluation changing the status
+	Result Result
+}
+
+// Returns a listener posting a message rendered by the given template to a chat webhook whenever the status of the
+// service changes, e.g. from pass to fail. Use TeamsTemplate for Microsoft Teams, JSONWebhookTemplate for a generic
+// JSON webhook, or a template of your own to customize the layout. Templates are executed with WebhookData and
+// can use the `json` function to encode values. Messages are sent asynchronously, failures are logged.
+//
+// Example:
+//		listener, err := health.WebhookListener(os.Getenv("TEAMS_WEBHOOK_URL"), health.TeamsTemplate)
+//		if err != nil {
+//			log.Fatal(err)
+//		}
+//
+//		checker.AddListener(listener)
+func WebhookListener(url, tmpl string, opts ...WebhookOption) (Listener, error) {
+	cfg := &webhookConfig{
+		client: &http.Client{Timeout: defaultWebhookTimeout},
+		header: http.Header{},
+	}
+
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	t, err := template.New("webhook").Funcs(template.FuncMap{"json": templateJSON}).Parse(tmpl)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template: %w", err)
+	}
+
+	hostname, _ := os.Hostname()
+	mu := sync.Mutex{}
+	previous := StatusPass
+
+	return func(r Result) {
+		mu.Lock()
+		changed := r.Status != previous
+		data := WebhookData{Host: hostname, Previous: previous, Result: r}
+		previous = r.Status
+		mu.Unlock()
+
+		if !changed {
+			return
+		}
+
+		var body bytes.Buffer
+		if err := t.Execute(&body, data); err != nil {
+			r.log().Printf("failed to render webhook message: %v", err)
+			return
+		}
+
+		go cfg.post(r.log(), url, body.Bytes())
+	}, nil
+}
+
+// Encodes v as JSON, e.g. to embed strings in a JSON template.
+func templateJSON(v interface{}) (string, error) {
+	b, err := json.Marshal(v)
+	return string(b), err
+}
+
+// Posts the message and logs failures.
+func (c *webhookConfig) post(logger Logger, url string, body []byte) {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		logger.Printf("failed to create webhook request: %v", err)
+		return
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	for key, values := range c.header {
+		req.Header[key] = values
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		logger.Printf("failed to post webhook message: %v", err)
+		return
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		logger.Printf("webhook responded with %v", resp.Status)
+	}
+}
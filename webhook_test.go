@@ -0,0 +1,81 @@
+package health
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newWebhookTestServer(t *testing.T) (string, <-chan []byte) {
+	bodies := make(chan []byte, 10)
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		bodies <- b
+	}))
+	t.Cleanup(s.Close)
+
+	return s.URL, bodies
+}
+
+func receiveWebhook(t *testing.T, bodies <-chan []byte) map[string]interface{} {
+	select {
+	case b := <-bodies:
+		var msg map[string]interface{}
+		assert.NoError(t, json.Unmarshal(b, &msg), string(b))
+		return msg
+	case <-time.After(2 * time.Second):
+		t.Fatal("no message received")
+		return nil
+	}
+}
+
+func TestWebhookListener(t *testing.T) {
+	url, bodies := newWebhookTestServer(t)
+
+	listener, err := WebhookListener(url, JSONWebhookTemplate)
+	assert.NoError(t, err)
+
+	failing := Result{Status: StatusFail, Reasons: []Reason{{Service: "database", Error: `refused "quoted"`, Kind: KindError}}}
+	listener(Result{Ready: true, Status: StatusPass})
+	listener(failing)
+	listener(failing)
+
+	msg := receiveWebhook(t, bodies)
+	assert.Equal(t, "fail", msg["status"])
+	assert.Equal(t, "pass", msg["previous"])
+	assert.Equal(t, `refused "quoted"`, msg["reasons"].([]interface{})[0].(map[string]interface{})["error"])
+
+	listener(Result{Ready: true, Status: StatusPass})
+	assert.Equal(t, "fail", receiveWebhook(t, bodies)["previous"])
+
+	select {
+	case b := <-bodies:
+		t.Fatalf("unexpected message: %s", b)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestWebhookListener_teams(t *testing.T) {
+	url, bodies := newWebhookTestServer(t)
+
+	listener, err := WebhookListener(url, TeamsTemplate)
+	assert.NoError(t, err)
+
+	listener(Result{Status: StatusFail, Reasons: []Reason{{Service: "database", Error: "refused"}, {Service: "cache", Error: "timeout"}}})
+
+	msg := receiveWebhook(t, bodies)
+	assert.Equal(t, "MessageCard", msg["@type"])
+	assert.Equal(t, "D70000", msg["themeColor"])
+	facts := msg["sections"].([]interface{})[0].(map[string]interface{})["facts"].([]interface{})
+	assert.Len(t, facts, 2)
+}
+
+func TestWebhookListener_err_template(t *testing.T) {
+	_, err := WebhookListener("http://localhost", "{{")
+	assert.Error(t, err)
+}
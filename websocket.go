@@ -0,0 +1,78 @@
+package health
+
+import (
+	"net/http"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+const defaultWebSocketKeepalive = 30 * time.Second
+
+// Returns a handler streaming the readiness status as JSON over a WebSocket, e.g. for live dashboards.
+// The status, including the results of all probes, is sent on connect, whenever it changes and at least every
+// keepalive interval, which defaults to 30s, timed by the Clock of the checker. Without background evaluation the
+// probes are run on each keepalive.
+//
+// Example:
+//		mux.Handle("/.well-known/ws", checker.WebSocketHandler(10 * time.Second))
+//		defer checker.EvaluateInBackground(5 * time.Second)()
+func (h *Checker) WebSocketHandler(keepalive time.Duration) http.Handler {
+	if keepalive <= 0 {
+		keepalive = defaultWebSocketKeepalive
+	}
+
+	return websocket.Handler(func(ws *websocket.Conn) {
+		defer ws.Close()
+
+		updates, unsubscribe := h.subscribe()
+		defer unsubscribe()
+
+		// Detect the client closing the connection, incoming messages are ignored
+		closed := make(chan struct{})
+		go func() {
+			defer close(closed)
+
+			var msg string
+			for websocket.Message.Receive(ws, &msg) == nil {
+			}
+		}()
+
+		ticker := h.clock().NewTicker(keepalive)
+		defer ticker.Stop()
+
+		last := h.readiness(ws.Request().Context(), nil)
+		if h.sendStatus(ws, last) != nil {
+			return
+		}
+
+		for {
+			select {
+			case r := <-updates:
+				if r.sameState(last) {
+					continue
+				}
+
+				last = r
+			case <-ticker.C():
+				last = h.readiness(ws.Request().Context(), nil)
+			case <-closed:
+				return
+			}
+
+			if h.sendStatus(ws, last) != nil {
+				return
+			}
+		}
+	})
+}
+
+func (h *Checker) sendStatus(ws *websocket.Conn, r Result) error {
+	return websocket.JSON.Send(ws, &statusResponse{
+		Ready:    r.Ready,
+		Status:   r.Status,
+		Reasons:  r.Reasons,
+		Probes:   newProbeStatuses(r.Probes),
+		Metadata: h.Metadata,
+	})
+}
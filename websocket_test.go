@@ -0,0 +1,49 @@
+package health
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/websocket"
+)
+
+func TestChecker_WebSocketHandler(t *testing.T) {
+	var healthy = true
+	checker := &Checker{}
+	checker.AddReadinessProbe("redis", func() error {
+		if !healthy {
+			return fmt.Errorf("connection refused")
+		}
+
+		return nil
+	})
+
+	server := httptest.NewServer(checker.WebSocketHandler(time.Hour))
+	defer server.Close()
+
+	ws, err := websocket.Dial(strings.Replace(server.URL, "http://", "ws://", 1), "", server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ws.Close()
+
+	var status statusResponse
+	assert.NoError(t, websocket.JSON.Receive(ws, &status))
+	assert.True(t, status.Ready)
+	assert.Len(t, status.Probes, 1)
+	assert.True(t, status.Probes[0].Healthy)
+
+	// Unchanged results are not sent
+	_, _ = checker.IsReady()
+
+	healthy = false
+	_, _ = checker.IsReady()
+
+	assert.NoError(t, websocket.JSON.Receive(ws, &status))
+	assert.False(t, status.Ready)
+	assert.Equal(t, []Reason{{Service: "redis", Error: "connection refused", Kind: KindError}}, status.Reasons)
+}